@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/pkg/storage"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"gitlab.com/tozd/go/errors"
+)
+
+const employeeUsage = `Usage: employee <command>
+
+Commands:
+  purge-trash   Permanently delete employees in trash longer than TRASH_RETENTION_DAYS
+  export        Export employees to CSV, XLSX or PDF, run "employee export -h" for the filters`
+
+// Employee runs employee maintenance commands
+func Employee(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		fmt.Println(employeeUsage)
+		return nil
+	}
+
+	switch args[0] {
+	case "purge-trash":
+		if configs.Get().Trash.RetentionDays <= 0 {
+			fmt.Println("Trash retention is disabled, nothing is purged")
+			return nil
+		}
+		fileStorage, err := storage.New(configs.Get().Storage)
+		if err != nil {
+			return err
+		}
+		purged, err := PurgeTrash(context.Background(), db, fileStorage)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d employees are permanently deleted\n", purged)
+	case "export":
+		return exportEmployees(db, args[1:])
+	default:
+		fmt.Println(employeeUsage)
+		return errors.Errorf("unknown employee command %q", args[0])
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes employees that are in trash longer than the retention period
+func PurgeTrash(ctx context.Context, db *sql.DB, fileStorage storage.Storage) (int, error) {
+	employeeService := services.NewEmployeeService(
+		repositories.NewEmployeeRepository(db),
+		repositories.NewEmployeeAllowanceRepository(db),
+		repositories.NewAllowanceTypeRepository(db),
+		repositories.NewDepartmentRepository(db),
+		repositories.NewPositionRepository(db),
+		repositories.NewEmployeeStatusHistoryRepository(db),
+		repositories.NewEmployeeDocumentRepository(db),
+		repositories.NewAuditLogRepository(db),
+		fileStorage,
+		db,
+	)
+	retention := time.Duration(configs.Get().Trash.RetentionDays) * 24 * time.Hour
+	return employeeService.PurgeTrashed(ctx, time.Now().Add(-retention))
+}
+
+// exportEmployees writes the export to a file (or stdout) for scheduled reports,
+// e.g. employee export -format xlsx -status ACTIVE -output active.xlsx
+func exportEmployees(db *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("employee export", flag.ContinueOnError)
+	format := flags.String("format", "csv", "Export format: csv, xlsx or pdf")
+	output := flags.String("output", "", "Output file, default is employees-<timestamp>.<format>, use - for stdout")
+	filter := &dto.EmployeeFilter{}
+	flags.StringVar(&filter.Status, "status", "", "Filter by status")
+	flags.StringVar(&filter.Gender, "gender", "", "Filter by gender")
+	flags.StringVar(&filter.HiredFrom, "hired-from", "", "Filter hired date from (YYYY-MM-DD)")
+	flags.StringVar(&filter.HiredTo, "hired-to", "", "Filter hired date to (YYYY-MM-DD)")
+	flags.StringVar(&filter.Allowance, "allowance", "", "Filter by allowance type code")
+	flags.IntVar(&filter.Department, "department", 0, "Filter by department id, sub departments are included")
+	flags.IntVar(&filter.Position, "position", 0, "Filter by position id")
+	flags.StringVar(&filter.Sort, "sort", "id", "Sort column")
+	flags.StringVar(&filter.Order, "order", "asc", "Sort order: asc or desc")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if _, ok := services.EmployeeExportFormats[*format]; !ok {
+		return errors.Errorf("unsupported export format %q, use csv, xlsx or pdf", *format)
+	}
+
+	employeeExportService := services.NewEmployeeExportService(repositories.NewEmployeeRepository(db))
+	if *output == "-" {
+		return employeeExportService.Export(context.Background(), *format, filter, os.Stdout)
+	}
+	if *output == "" {
+		*output = employeeExportService.Filename(*format, time.Now())
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return errors.Errorf("failed to create export file: %w", err)
+	}
+	if err := employeeExportService.Export(context.Background(), *format, filter, file); err != nil {
+		file.Close()
+		os.Remove(*output)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return errors.Errorf("failed to write export file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Employees are exported to %s\n", *output)
+	return nil
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"gitlab.com/tozd/go/errors"
+)
+
+const migrateUsage = `Usage: migrate <command>
+
+Commands:
+  up              Apply all pending migrations
+  down [steps]    Rollback the latest migrations (default 1 step)
+  status          Show applied and pending migrations
+  create <name>   Create a new migration file pair`
+
+// Migrate runs `migrate up|down|status|create`
+func Migrate(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		return nil
+	}
+
+	ctx := context.Background()
+	migrator := database.NewMigrator(db)
+
+	switch args[0] {
+	case "up":
+		migrated, err := migrator.Up(ctx)
+		for _, migration := range migrated {
+			fmt.Printf("Migrated: %06d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(migrated) == 0 {
+			fmt.Println("Nothing to migrate")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			parsed, err := strconv.Atoi(args[1])
+			if err != nil || parsed < 1 {
+				return errors.Errorf("invalid steps %q", args[1])
+			}
+			steps = parsed
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("Rolled back: %06d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("Nothing to rollback")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "Pending"
+			if status.Applied {
+				state = "Applied " + status.AppliedAt.Time.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d_%-50s %s\n", status.Migration.Version, status.Migration.Name, state)
+		}
+	case "create":
+		if len(args) < 2 {
+			return errors.New("migration name is required, e.g. migrate create add_phone_to_employees")
+		}
+		files, err := migrator.Create(args[1])
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			fmt.Println("Created:", file)
+		}
+	default:
+		fmt.Println(migrateUsage)
+		return errors.Errorf("unknown migrate command %q", args[0])
+	}
+	return nil
+}
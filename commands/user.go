@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"gitlab.com/tozd/go/errors"
+)
+
+const userUsage = `Usage: user <command>
+
+Commands:
+  role <username> <role>   Assign role (user type) to user, e.g. user role admin ADMINISTRATOR
+  activate <username>      Activate user without email verification`
+
+// User runs user maintenance commands, e.g. promoting the first administrator
+func User(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		fmt.Println(userUsage)
+		return nil
+	}
+
+	ctx := context.Background()
+	userRepository := repositories.NewUserRepository(db)
+	roleRepository := repositories.NewRoleRepository(db)
+
+	switch args[0] {
+	case "role":
+		if len(args) < 3 {
+			return errors.New("username and role are required, e.g. user role admin ADMINISTRATOR")
+		}
+		user, err := userRepository.GetByUsername(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		role, err := roleRepository.GetByName(ctx, args[2])
+		if err != nil {
+			return err
+		}
+		if _, err := userRepository.UpdateUserType(ctx, user.Id, role.Name); err != nil {
+			return err
+		}
+		fmt.Printf("User %s is now %s\n", user.Username, role.Name)
+	case "activate":
+		if len(args) < 2 {
+			return errors.New("username is required, e.g. user activate admin")
+		}
+		user, err := userRepository.GetByUsername(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		if _, err := userRepository.UpdateStatus(ctx, user.Id, "ACTIVATED"); err != nil {
+			return err
+		}
+		fmt.Printf("User %s is activated\n", user.Username)
+	default:
+		fmt.Println(userUsage)
+		return errors.Errorf("unknown user command %q", args[0])
+	}
+	return nil
+}
+
+// PurgeFailedLogins deletes failed login attempts that no longer count toward the throttle
+func PurgeFailedLogins(ctx context.Context, db *sql.DB) (int64, error) {
+	loginThrottleService := services.NewLoginThrottleService(repositories.NewFailedLoginRepository(db))
+	return loginThrottleService.PurgeExpired(ctx)
+}
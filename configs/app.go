@@ -1,26 +1,48 @@
 package configs
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/spf13/viper"
 )
 
 type AppConfig struct {
     Name string
+    Url string
+    // Key signs links (e.g. email verification), keep it secret and stable
+    Key string
     Environment string
     Port uint
     Debug bool
 }
 
+// defaultAppKey is public, it is only accepted in development
+const defaultAppKey = "app-key"
+
 func LoadAppConfig() AppConfig {
     viper.SetDefault("APP_NAME", "Application")
+    viper.SetDefault("APP_URL", "http://localhost:8080")
+    viper.SetDefault("APP_KEY", defaultAppKey)
     viper.SetDefault("APP_ENV", "production")
     viper.SetDefault("APP_PORT", 8080)
     viper.SetDefault("APP_DEBUG", false)
     
     return AppConfig{
         Name: viper.GetString("APP_NAME"),
+        Url: strings.TrimRight(viper.GetString("APP_URL"), "/"),
+        Key: viper.GetString("APP_KEY"),
         Environment: viper.GetString("APP_ENV"),
         Port: viper.GetUint("APP_PORT"),
         Debug: viper.GetBool("APP_DEBUG"),
     }
-}
\ No newline at end of file
+}
+
+// Validate rejects the default key outside development, two factor secrets are encrypted
+// and links are signed with it
+func (config AppConfig) Validate() error {
+    if config.Environment != "development" && (config.Key == "" || config.Key == defaultAppKey) {
+        return errors.New("APP_KEY must be set to a random secret outside development")
+    }
+    return nil
+}
@@ -5,20 +5,33 @@ import "github.com/spf13/viper"
 type AuthConfig struct {
 	JwtSecret string
 	JwtExpired int
-	PersonalToken string
 	ResetExpired int
+	VerifyExpired int
+	// Failed login attempts allowed per username and per IP address within LoginDecay seconds
+	LoginMaxAttempts int
+	LoginMaxAttemptsPerIp int
+	LoginDecay int
+	LoginLockout int
 }
 
 func LoadAuthConfig() AuthConfig {
 	viper.SetDefault("JWT_SECRET", "jwt-secret")
 	viper.SetDefault("JWT_EXPIRED", 7200)
-	viper.SetDefault("PERSONAL_TOKEN", "personal-token")
 	viper.SetDefault("RESET_EXPIRED", 7200)
+	viper.SetDefault("VERIFY_EXPIRED", 86400)
+	viper.SetDefault("LOGIN_MAX_ATTEMPTS", 5)
+	viper.SetDefault("LOGIN_MAX_ATTEMPTS_PER_IP", 20)
+	viper.SetDefault("LOGIN_DECAY", 900)
+	viper.SetDefault("LOGIN_LOCKOUT", 900)
 
 	return AuthConfig{
 		JwtSecret: viper.GetString("JWT_SECRET"),
 		JwtExpired: viper.GetInt("JWT_EXPIRED"),
-		PersonalToken: viper.GetString("PERSONAL_TOKEN"),
 		ResetExpired: viper.GetInt("RESET_EXPIRED"),
+		VerifyExpired: viper.GetInt("VERIFY_EXPIRED"),
+		LoginMaxAttempts: viper.GetInt("LOGIN_MAX_ATTEMPTS"),
+		LoginMaxAttemptsPerIp: viper.GetInt("LOGIN_MAX_ATTEMPTS_PER_IP"),
+		LoginDecay: viper.GetInt("LOGIN_DECAY"),
+		LoginLockout: viper.GetInt("LOGIN_LOCKOUT"),
 	}
 }
\ No newline at end of file
@@ -12,6 +12,10 @@ type Config struct {
 	Auth     AuthConfig
 	Database DatabaseConfig
 	Session  SessionConfig
+	Mail     MailConfig
+	Trash    TrashConfig
+	Payroll  PayrollConfig
+	Storage  StorageConfig
 }
 
 // Global config instance
@@ -46,6 +50,13 @@ func Load() (*Config, error) {
 		Auth:     LoadAuthConfig(),
 		Database: LoadDatabaseConfig(),
 		Session:  LoadSessionConfig(),
+		Mail:     LoadMailConfig(),
+		Trash:    LoadTrashConfig(),
+		Payroll:  LoadPayrollConfig(),
+		Storage:  LoadStorageConfig(),
+	}
+	if err := Configs.App.Validate(); err != nil {
+		return nil, err
 	}
 
 	return Configs, nil
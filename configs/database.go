@@ -12,6 +12,7 @@ type DatabaseConfig struct {
 	User     string
 	Password string
 	Database string
+	MigrationCheck bool
 }
 
 func LoadDatabaseConfig() DatabaseConfig {
@@ -20,6 +21,7 @@ func LoadDatabaseConfig() DatabaseConfig {
 	viper.SetDefault("DB_USERNAME", "root")
 	viper.SetDefault("DB_PASSWORD", "")
 	viper.SetDefault("DB_DATABASE", "sandbox")
+	viper.SetDefault("DB_MIGRATION_CHECK", true)
 
 	return DatabaseConfig{
 		Host:     viper.GetString("DB_HOST"),
@@ -27,6 +29,7 @@ func LoadDatabaseConfig() DatabaseConfig {
 		User:     viper.GetString("DB_USERNAME"),
 		Password: viper.GetString("DB_PASSWORD"),
 		Database: viper.GetString("DB_DATABASE"),
+		MigrationCheck: viper.GetBool("DB_MIGRATION_CHECK"),
 	}
 }
 
@@ -0,0 +1,34 @@
+package configs
+
+import "github.com/spf13/viper"
+
+type MailConfig struct {
+	Driver      string
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	FromAddress string
+	FromName    string
+	OutboxPath  string
+}
+
+func LoadMailConfig() MailConfig {
+	viper.SetDefault("MAIL_DRIVER", "log")
+	viper.SetDefault("MAIL_HOST", "127.0.0.1")
+	viper.SetDefault("MAIL_PORT", 25)
+	viper.SetDefault("MAIL_FROM_ADDRESS", "no-reply@example.com")
+	viper.SetDefault("MAIL_FROM_NAME", "Application")
+	viper.SetDefault("MAIL_OUTBOX_PATH", "storage/mails")
+
+	return MailConfig{
+		Driver:      viper.GetString("MAIL_DRIVER"),
+		Host:        viper.GetString("MAIL_HOST"),
+		Port:        viper.GetInt("MAIL_PORT"),
+		Username:    viper.GetString("MAIL_USERNAME"),
+		Password:    viper.GetString("MAIL_PASSWORD"),
+		FromAddress: viper.GetString("MAIL_FROM_ADDRESS"),
+		FromName:    viper.GetString("MAIL_FROM_NAME"),
+		OutboxPath:  viper.GetString("MAIL_OUTBOX_PATH"),
+	}
+}
@@ -0,0 +1,20 @@
+package configs
+
+import "github.com/spf13/viper"
+
+type PayrollConfig struct {
+	// TaxRate in percent of base salary and taxable allowances, 0 disables the deduction
+	TaxRate float64
+	// SocialSecurityRate in percent of base salary, 0 disables the deduction
+	SocialSecurityRate float64
+}
+
+func LoadPayrollConfig() PayrollConfig {
+	viper.SetDefault("PAYROLL_TAX_RATE", 5)
+	viper.SetDefault("PAYROLL_SOCIAL_SECURITY_RATE", 2)
+
+	return PayrollConfig{
+		TaxRate: viper.GetFloat64("PAYROLL_TAX_RATE"),
+		SocialSecurityRate: viper.GetFloat64("PAYROLL_SOCIAL_SECURITY_RATE"),
+	}
+}
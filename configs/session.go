@@ -3,8 +3,11 @@ package configs
 import "github.com/spf13/viper"
 
 type SessionConfig struct {
+	// Driver is "jwt" (stateless cookie), "database" or "memory" (server-side sessions that can be listed and revoked)
+	Driver string
 	StoreName  string
 	CookieName string
+	CsrfCookieName string
 	Lifetime int
 	Secret string
 	Path string
@@ -14,8 +17,10 @@ type SessionConfig struct {
 }
 
 func LoadSessionConfig() SessionConfig {
+	viper.SetDefault("SESSION_DRIVER", "jwt")
 	viper.SetDefault("SESSION_STORE_NAME", "session_store")
 	viper.SetDefault("SESSION_COOKIE", "session")
+	viper.SetDefault("CSRF_COOKIE", "csrf_token")
 	viper.SetDefault("COOKIE_SECRET", "secret")
 	viper.SetDefault("COOKIE_LIFETIME", 7200)
 	viper.SetDefault("COOKIE_PATH", "/")
@@ -24,8 +29,10 @@ func LoadSessionConfig() SessionConfig {
 	viper.SetDefault("COOKIE_SAME_SITE", "lax")
 
 	return SessionConfig{
+		Driver: viper.GetString("SESSION_DRIVER"),
 		StoreName: viper.GetString("SESSION_STORE_NAME"),
 		CookieName: viper.GetString("SESSION_COOKIE"),
+		CsrfCookieName: viper.GetString("CSRF_COOKIE"),
 		Secret: viper.GetString("COOKIE_SECRET"),
 		Lifetime: viper.GetInt("COOKIE_LIFETIME"),
 		Path: viper.GetString("COOKIE_PATH"),
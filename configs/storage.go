@@ -0,0 +1,38 @@
+package configs
+
+import "github.com/spf13/viper"
+
+type StorageConfig struct {
+	// Driver is local (filesystem) or s3 (any S3-compatible service e.g. MinIO)
+	Driver    string
+	LocalPath string
+	// SignedUrlTtl is how long (in seconds) signed file urls stay valid
+	SignedUrlTtl int
+	S3Endpoint   string
+	S3Region     string
+	S3Bucket     string
+	S3AccessKey  string
+	S3SecretKey  string
+	// S3PathStyle addresses the bucket as endpoint/bucket instead of bucket.endpoint, needed by most stand-ins
+	S3PathStyle bool
+}
+
+func LoadStorageConfig() StorageConfig {
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_LOCAL_PATH", "uploads")
+	viper.SetDefault("STORAGE_SIGNED_URL_TTL", 3600)
+	viper.SetDefault("STORAGE_S3_REGION", "us-east-1")
+	viper.SetDefault("STORAGE_S3_PATH_STYLE", true)
+
+	return StorageConfig{
+		Driver:       viper.GetString("STORAGE_DRIVER"),
+		LocalPath:    viper.GetString("STORAGE_LOCAL_PATH"),
+		SignedUrlTtl: viper.GetInt("STORAGE_SIGNED_URL_TTL"),
+		S3Endpoint:   viper.GetString("STORAGE_S3_ENDPOINT"),
+		S3Region:     viper.GetString("STORAGE_S3_REGION"),
+		S3Bucket:     viper.GetString("STORAGE_S3_BUCKET"),
+		S3AccessKey:  viper.GetString("STORAGE_S3_ACCESS_KEY"),
+		S3SecretKey:  viper.GetString("STORAGE_S3_SECRET_KEY"),
+		S3PathStyle:  viper.GetBool("STORAGE_S3_PATH_STYLE"),
+	}
+}
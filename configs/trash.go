@@ -0,0 +1,20 @@
+package configs
+
+import "github.com/spf13/viper"
+
+type TrashConfig struct {
+	// RetentionDays keeps deleted employees in trash before they are purged, 0 keeps them forever
+	RetentionDays int
+	// PurgeInterval in seconds between automatic purges of expired trash and failed logins
+	PurgeInterval int
+}
+
+func LoadTrashConfig() TrashConfig {
+	viper.SetDefault("TRASH_RETENTION_DAYS", 30)
+	viper.SetDefault("TRASH_PURGE_INTERVAL", 3600)
+
+	return TrashConfig{
+		RetentionDays: viper.GetInt("TRASH_RETENTION_DAYS"),
+		PurgeInterval: viper.GetInt("TRASH_PURGE_INTERVAL"),
+	}
+}
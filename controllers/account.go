@@ -2,7 +2,10 @@ package controllers
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/anggadarkprince/crud-employee-go/configs"
 	"github.com/anggadarkprince/crud-employee-go/dto"
 	"github.com/anggadarkprince/crud-employee-go/middlewares"
 	"github.com/anggadarkprince/crud-employee-go/pkg/session"
@@ -13,15 +16,66 @@ import (
 
 type AccountController struct {
 	userService *services.UserService
+	authService *services.AuthService
+	personalAccessTokenService *services.PersonalAccessTokenService
+	twoFactorService *services.TwoFactorService
 }
 
-func NewAccountController(userService *services.UserService) *AccountController {
-	return &AccountController{userService: userService}
+func NewAccountController(
+	userService *services.UserService,
+	authService *services.AuthService,
+	personalAccessTokenService *services.PersonalAccessTokenService,
+	twoFactorService *services.TwoFactorService,
+) *AccountController {
+	return &AccountController{
+		userService: userService,
+		authService: authService,
+		personalAccessTokenService: personalAccessTokenService,
+		twoFactorService: twoFactorService,
+	}
 }
 
 func (controller *AccountController) Index(w http.ResponseWriter, r *http.Request) error {
 	user := middlewares.GetUser(r)
-	data := utilities.Compact("user", user)
+	tokens, err := controller.personalAccessTokenService.GetByUserId(r.Context(), user.Id)
+	if err != nil {
+		return err
+	}
+	// Token scopes are limited to permissions of the current user
+	scopes := middlewares.GetPermissions(r)
+
+	twoFactorSetup, err := controller.twoFactorService.Setup(user)
+	if err != nil {
+		return err
+	}
+	twoFactorRequired, err := controller.twoFactorService.IsRequired(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	recoveryCodeCount, err := controller.twoFactorService.CountRecoveryCodes(r.Context(), user.Id)
+	if err != nil {
+		return err
+	}
+	sessions, err := controller.authService.GetSessions(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	currentSessionId := ""
+	if cookie, err := r.Cookie(configs.Get().Session.CookieName); err == nil {
+		currentSessionId = session.SessionId(cookie.Value)
+	}
+
+	data := utilities.Compact(
+		"user", user,
+		"tokens", tokens,
+		"scopes", scopes,
+		"twoFactorSetup", twoFactorSetup,
+		"twoFactorRequired", twoFactorRequired,
+		"recoveryCodeCount", recoveryCodeCount,
+		"sessionsEnabled", controller.authService.SessionsEnabled(),
+		"sessions", sessions,
+		"currentSessionId", currentSessionId,
+	)
 	return utilities.Render(w, r, "account/index.html", data)
 }
 
@@ -55,9 +109,94 @@ func (controller *AccountController) Update(w http.ResponseWriter, r *http.Reque
 	if err != nil {
 		return err
 	}
+
+	// Other sessions are revoked after password change, keep the current one logged in
+	if data.Password != "" {
+		if cookie, err := r.Cookie(configs.Get().Session.CookieName); err == nil {
+			authToken, exp, err := controller.authService.ReissueAuthToken(r.Context(), cookie.Value)
+			if err != nil {
+				return err
+			}
+			setSessionCookie(w, authToken, int(time.Until(exp).Seconds()))
+		}
+	}
 	
 	session.Flash(w, "success", "Account successfully updated")
 
 	http.Redirect(w, r, "/account", http.StatusSeeOther)
 	return nil
-}
\ No newline at end of file
+}
+
+func (controller *AccountController) StoreToken(w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	user := middlewares.GetUser(r)
+
+	data := &dto.CreatePersonalAccessTokenRequest{
+		UserId: user.Id,
+		Name: r.FormValue("name"),
+		Scopes: r.Form["scopes[]"],
+		ExpiredAt: r.FormValue("expired_at"),
+	}
+	err := validation.Validator.Struct(data)
+	if err != nil {
+		return err
+	}
+
+	plainToken, _, err := controller.personalAccessTokenService.Create(r.Context(), data, middlewares.GetPermissions(r))
+	if err != nil {
+		return err
+	}
+
+	// Plain token is only shown once, right after it is created
+	session.SetFlash(w, session.FlashData{
+		"alert": map[string]string{
+			"type": "success",
+			"message": "Personal access token successfully created, copy it now because it will not be shown again",
+		},
+		"token": plainToken,
+	})
+
+	http.Redirect(w, r, "/account", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *AccountController) DeleteToken(w http.ResponseWriter, r *http.Request) error {
+	tokenId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	user := middlewares.GetUser(r)
+
+	err = controller.personalAccessTokenService.Revoke(r.Context(), user.Id, tokenId)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "warning", "Personal access token successfully revoked")
+
+	http.Redirect(w, r, "/account", http.StatusSeeOther)
+	return nil
+}
+
+// DeleteSession logs out a device of the user, ending the current session logs the user out
+func (controller *AccountController) DeleteSession(w http.ResponseWriter, r *http.Request) error {
+	user := middlewares.GetUser(r)
+	sessionId := r.PathValue("id")
+	if err := controller.authService.RevokeSession(r.Context(), user.Id, sessionId); err != nil {
+		return err
+	}
+
+	if cookie, err := r.Cookie(configs.Get().Session.CookieName); err == nil && session.SessionId(cookie.Value) == sessionId {
+		clearSessionCookie(w)
+		session.Flash(w, "warning", "You are logged out")
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil
+	}
+
+	session.Flash(w, "warning", "Session successfully ended, the device is logged out")
+
+	http.Redirect(w, r, "/account#devices", http.StatusSeeOther)
+	return nil
+}
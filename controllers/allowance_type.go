@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type AllowanceTypeController struct {
+	allowanceTypeService *services.AllowanceTypeService
+}
+
+func NewAllowanceTypeController(allowanceTypeService *services.AllowanceTypeService) *AllowanceTypeController {
+	return &AllowanceTypeController{allowanceTypeService: allowanceTypeService}
+}
+
+// parseAllowanceTypeForm reads the shared fields of create and edit form, code is normalized to uppercase
+func parseAllowanceTypeForm(r *http.Request) (code string, name string, amount models.Money, err error) {
+	code = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(r.FormValue("code")), " ", "_"))
+	name = strings.TrimSpace(r.FormValue("name"))
+	if value := strings.TrimSpace(r.FormValue("default_amount")); value != "" {
+		amount, err = models.ParseMoney(strings.ReplaceAll(value, ",", ""))
+		if err != nil {
+			return "", "", 0, &exceptions.ValidationError{
+				Message: "Please check the data you provided.",
+				Errors: map[string]string{"default_amount": "Default amount must be a number with up to 2 decimals"},
+			}
+		}
+	}
+	return code, name, amount, nil
+}
+
+func (controller *AllowanceTypeController) Index(w http.ResponseWriter, r *http.Request) error {
+	allowanceTypes, err := controller.allowanceTypeService.GetAll(r.Context(), false)
+	if err != nil {
+		return err
+	}
+	return utilities.Render(w, r, "allowance_types/index.html", utilities.Compact("allowanceTypes", allowanceTypes))
+}
+
+func (controller *AllowanceTypeController) Create(w http.ResponseWriter, r *http.Request) error {
+	return utilities.Render(w, r, "allowance_types/create.html", nil)
+}
+
+func (controller *AllowanceTypeController) Store(w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	code, name, amount, err := parseAllowanceTypeForm(r)
+	if err != nil {
+		return err
+	}
+	data := &dto.CreateAllowanceTypeRequest{
+		Code: code,
+		Name: name,
+		DefaultAmount: amount,
+		IsTaxable: r.FormValue("is_taxable") != "",
+		IsActive: r.FormValue("is_active") != "",
+	}
+	if err := validation.Validator.Struct(data); err != nil {
+		return err
+	}
+
+	allowanceType, err := controller.allowanceTypeService.Store(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("Allowance %s successfully created", allowanceType.Name))
+
+	http.Redirect(w, r, "/allowance-types", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *AllowanceTypeController) Edit(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	allowanceType, err := controller.allowanceTypeService.GetById(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	return utilities.Render(w, r, "allowance_types/edit.html", utilities.Compact("allowanceType", allowanceType))
+}
+
+func (controller *AllowanceTypeController) Update(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	code, name, amount, err := parseAllowanceTypeForm(r)
+	if err != nil {
+		return err
+	}
+	data := &dto.UpdateAllowanceTypeRequest{
+		Id: id,
+		Code: code,
+		Name: name,
+		DefaultAmount: amount,
+		IsTaxable: r.FormValue("is_taxable") != "",
+		IsActive: r.FormValue("is_active") != "",
+	}
+	if err := validation.Validator.Struct(data); err != nil {
+		return err
+	}
+
+	allowanceType, err := controller.allowanceTypeService.Update(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("Allowance %s successfully updated", allowanceType.Name))
+
+	http.Redirect(w, r, "/allowance-types", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *AllowanceTypeController) Delete(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	allowanceType, err := controller.allowanceTypeService.Destroy(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "warning", fmt.Sprintf("Allowance %s successfully deleted", allowanceType.Name))
+
+	http.Redirect(w, r, "/allowance-types", http.StatusSeeOther)
+	return nil
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type AuditLogController struct {
+	auditLogService *services.AuditLogService
+	userService *services.UserService
+}
+
+func NewAuditLogController(auditLogService *services.AuditLogService, userService *services.UserService) *AuditLogController {
+	return &AuditLogController{
+		auditLogService: auditLogService,
+		userService: userService,
+	}
+}
+
+// parseAuditLogFilter reads list params from query string, invalid values fall back to defaults
+func parseAuditLogFilter(r *http.Request) *dto.AuditLogFilter {
+	query := r.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = 25
+	}
+	perPage = min(perPage, 100)
+
+	userId, _ := strconv.Atoi(query.Get("user_id"))
+	entityId, _ := strconv.Atoi(query.Get("entity_id"))
+
+	entityType := query.Get("entity_type")
+	if !slices.Contains(models.AuditEntities, entityType) {
+		entityType = ""
+	}
+
+	dateFrom := query.Get("date_from")
+	if _, err := utilities.StringToDate(dateFrom); err != nil {
+		dateFrom = ""
+	}
+	dateTo := query.Get("date_to")
+	if _, err := utilities.StringToDate(dateTo); err != nil {
+		dateTo = ""
+	}
+
+	return &dto.AuditLogFilter{
+		Page: page,
+		PerPage: perPage,
+		UserId: userId,
+		EntityType: entityType,
+		EntityId: entityId,
+		DateFrom: dateFrom,
+		DateTo: dateTo,
+	}
+}
+
+func (controller *AuditLogController) Index(w http.ResponseWriter, r *http.Request) error {
+	filter := parseAuditLogFilter(r)
+	auditLogs, total, err := controller.auditLogService.Paginate(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+	users, err := controller.userService.GetAll(r.Context())
+	if err != nil {
+		return err
+	}
+
+	data := utilities.Compact(
+		"auditLogs", auditLogs,
+		"users", users,
+		"entities", models.AuditEntities,
+		"pagination", utilities.NewPagination(total, filter.Page, filter.PerPage, r.URL.Path, r.URL.Query()),
+	)
+	return utilities.Render(w, r, "audit_logs/index.html", data)
+}
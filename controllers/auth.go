@@ -2,12 +2,15 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"time"
 
 	"github.com/anggadarkprince/crud-employee-go/configs"
 	"github.com/anggadarkprince/crud-employee-go/dto"
 	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/middlewares"
 	"github.com/anggadarkprince/crud-employee-go/services"
 	"github.com/anggadarkprince/crud-employee-go/utilities"
 	"github.com/anggadarkprince/crud-employee-go/pkg/session"
@@ -16,10 +19,17 @@ import (
 
 type AuthController struct {
 	authService *services.AuthService
+	loginThrottleService *services.LoginThrottleService
 }
 
-func NewAuthController(authService *services.AuthService) *AuthController {
-	return &AuthController{authService: authService}
+func NewAuthController(
+	authService *services.AuthService,
+	loginThrottleService *services.LoginThrottleService,
+) *AuthController {
+	return &AuthController{
+		authService: authService,
+		loginThrottleService: loginThrottleService,
+	}
 }
 
 func (controller *AuthController) Login(w http.ResponseWriter, r *http.Request) error {
@@ -47,23 +57,47 @@ func (controller *AuthController) Authenticate(w http.ResponseWriter, r *http.Re
 		}
 	}
 
+	ipAddress := utilities.ClientIP(r)
+	wait, err := controller.loginThrottleService.RetryAfter(r.Context(), username, ipAddress)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return &exceptions.AppError{
+			Code: http.StatusTooManyRequests,
+			Message: fmt.Sprintf("Too many login attempts, please try again in %d seconds", int(math.Ceil(wait.Seconds()))),
+		}
+	}
+
 	user, err := controller.authService.Authenticate(r.Context(), username, password)
 	if err != nil {
 		switch {
-        case errors.Is(err, exceptions.ErrUserNotFound):
-			return &exceptions.AppError{
-				Code: 404,
-				Message: "User not found",
+        case errors.Is(err, exceptions.ErrUserNotFound), errors.Is(err, exceptions.ErrWrongPassword):
+			// Same message for both so existence of the username is not disclosed
+			if err := controller.loginThrottleService.RecordFailure(r.Context(), username, ipAddress, r.UserAgent()); err != nil {
+				return err
 			}
-        case errors.Is(err, exceptions.ErrWrongPassword):
 			return &exceptions.AppError{
 				Code: 401,
-				Message: "Username or password wrong",
+				Message: "These credentials do not match our records",
 			}
         case errors.Is(err, exceptions.ErrUserInactive):
+			if user != nil && user.Status == "PENDING" {
+				// Offer to resend the verification mail, the token proves the password was right
+				session.SetFlash(w, session.FlashData{
+					"alert": map[string]string{
+						"type": "warning",
+						"message": "Your email address is not verified yet, please check your inbox",
+					},
+					"old": session.ParseFormInput(r),
+					"resend_token": controller.authService.ResendToken(user),
+				})
+				http.Redirect(w, r, "/login", http.StatusSeeOther)
+				return nil
+			}
 			return &exceptions.AppError{
 				Code: 403,
-				Message: "User is PENDING or SUSPENDED",
+				Message: "Your account is suspended",
 			}
         default:
 			return err
@@ -71,31 +105,21 @@ func (controller *AuthController) Authenticate(w http.ResponseWriter, r *http.Re
 	}
 	
 	if user != nil {
-		var hours = 2;
-		if remember {
-			hours = 24 * 30
-		}
-		var exp = time.Now().Add(time.Duration(hours) * time.Hour).Unix()
-		authToken, err := controller.authService.GenerateAuthToken(user.Id, exp)
-		if err != nil {
-			return err
+		// Second factor is verified before the session is issued, failures are kept until it passes
+		if user.HasTwoFactor() {
+			setTwoFactorCookie(w, user.Id, remember)
+			http.Redirect(w, r, "/two-factor-challenge", http.StatusSeeOther)
+			return nil
 		}
 
-		var maxAge = configs.Get().Session.Lifetime;
-		if remember {
-			maxAge = 3600 * 24 * 30
+		// Login is complete, forget the previous failures
+		if err := controller.loginThrottleService.Clear(r.Context(), username, ipAddress); err != nil {
+			return err
 		}
 
-		cookie := http.Cookie{
-			Name: configs.Get().Session.CookieName,
-			Value: authToken,
-			Path: configs.Get().Session.Path,
-			HttpOnly: true, // cannot be accessed by JS (secure)
-			Secure: configs.Get().Session.Secure, // set to true in HTTPS
-			SameSite: http.SameSiteLaxMode,
-			MaxAge: maxAge,
+		if err := startSession(w, r, controller.authService, user.Id, remember); err != nil {
+			return err
 		}
-		http.SetCookie(w, &cookie)
 
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return nil
@@ -124,30 +148,195 @@ func (controller *AuthController) RegisterUser(w http.ResponseWriter, r *http.Re
 		return err
 	}
 
-	controller.authService.Register(r.Context(), data)
+	_, err = controller.authService.Register(r.Context(), data)
+	if err != nil {
+		return err
+	}
 
-	session.Flash(w, "success", "User is registered")
+	session.Flash(w, "success", "User is registered, please check your email to verify the account")
 
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
 	return nil
 }
 
 func (controller *AuthController) Logout(w http.ResponseWriter, r *http.Request) error {
-    cookieName := configs.Get().Session.CookieName
-
-    cookie := http.Cookie{
-        Name: cookieName,
-        Value: "",
-        Path: configs.Get().Session.Path,
-        MaxAge: -1,
-        HttpOnly: true,
-        Secure: configs.Get().Session.Secure, // set true in production (HTTPS)
-        SameSite: http.SameSiteLaxMode,
+    // Revoke the token server-side, clearing the cookie alone keeps a copied token valid
+    if cookie, err := r.Cookie(configs.Get().Session.CookieName); err == nil {
+        if err := controller.authService.RevokeAuthToken(r.Context(), cookie.Value); err != nil {
+            return err
+        }
     }
-    http.SetCookie(w, &cookie)
+    clearSessionCookie(w)
 
 	session.Flash(w, "warning", "You are logged out")
 
     http.Redirect(w, r, "/login", http.StatusSeeOther)
 	return nil
-}
\ No newline at end of file
+}
+
+// LogoutEverywhere revokes every session of the user including the current one
+func (controller *AuthController) LogoutEverywhere(w http.ResponseWriter, r *http.Request) error {
+	user := middlewares.GetUser(r)
+	if err := controller.authService.RevokeAllSessions(r.Context(), user.Id); err != nil {
+		return err
+	}
+	if cookie, err := r.Cookie(configs.Get().Session.CookieName); err == nil {
+		if err := controller.authService.RevokeAuthToken(r.Context(), cookie.Value); err != nil {
+			return err
+		}
+	}
+	clearSessionCookie(w)
+
+	session.Flash(w, "warning", "You are logged out from all devices")
+
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *AuthController) VerifyEmail(w http.ResponseWriter, r *http.Request) error {
+	_, err := controller.authService.VerifyEmail(r.Context(), r.PathValue("token"))
+	if err != nil {
+		if errors.Is(err, exceptions.ErrInvalidSignedToken) {
+			session.Flash(w, "danger", "This verification link is invalid or has expired, log in to request a new one")
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return nil
+		}
+		return err
+	}
+
+	session.Flash(w, "success", "Your email address is verified, you may log in now")
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *AuthController) ResendVerification(w http.ResponseWriter, r *http.Request) error {
+	err := controller.authService.ResendVerificationEmail(r.Context(), r.FormValue("resend_token"))
+	if err != nil {
+		if errors.Is(err, exceptions.ErrInvalidSignedToken) {
+			return &exceptions.AppError{
+				Code: 400,
+				Message: "Verification request has expired, please log in again",
+				Err: err,
+			}
+		}
+		return err
+	}
+
+	session.Flash(w, "success", "A new verification link has been sent to your email address")
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+	return nil
+}
+
+// startSession issues the session cookie, remember me keeps the session for 30 days
+func startSession(w http.ResponseWriter, r *http.Request, authService *services.AuthService, userId int, remember bool) error {
+	var hours = 2;
+	if remember {
+		hours = 24 * 30
+	}
+	var exp = time.Now().Add(time.Duration(hours) * time.Hour)
+	authToken, err := authService.StartSession(r.Context(), userId, exp, utilities.ClientIP(r), r.UserAgent())
+	if err != nil {
+		return err
+	}
+
+	var maxAge = configs.Get().Session.Lifetime;
+	if remember {
+		maxAge = 3600 * 24 * 30
+	}
+	setSessionCookie(w, authToken, maxAge)
+	return nil
+}
+
+func setSessionCookie(w http.ResponseWriter, authToken string, maxAge int) {
+	cookie := http.Cookie{
+		Name: configs.Get().Session.CookieName,
+		Value: authToken,
+		Path: configs.Get().Session.Path,
+		HttpOnly: true, // cannot be accessed by JS (secure)
+		Secure: configs.Get().Session.Secure, // set to true in HTTPS
+		SameSite: http.SameSiteLaxMode,
+		MaxAge: maxAge,
+	}
+	http.SetCookie(w, &cookie)
+}
+
+func clearSessionCookie(w http.ResponseWriter) {
+	cookie := http.Cookie{
+		Name: configs.Get().Session.CookieName,
+		Value: "",
+		Path: configs.Get().Session.Path,
+		MaxAge: -1,
+		HttpOnly: true,
+		Secure: configs.Get().Session.Secure, // set true in production (HTTPS)
+		SameSite: http.SameSiteLaxMode,
+	}
+	http.SetCookie(w, &cookie)
+}
+
+func (controller *AuthController) ForgotPassword(w http.ResponseWriter, r *http.Request) error {
+	return utilities.Render(w, r, "auth/forgot_password.html", nil)
+}
+
+func (controller *AuthController) SendResetLink(w http.ResponseWriter, r *http.Request) error {
+	data := &dto.ForgotPasswordRequest{
+		Email: r.FormValue("email"),
+	}
+	err := validation.Validator.Struct(data)
+	if err != nil {
+		return err
+	}
+
+	err = controller.authService.SendPasswordResetLink(r.Context(), data.Email)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", "If the email is registered, a password reset link has been sent to it")
+
+	http.Redirect(w, r, "/forgot-password", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *AuthController) ResetPassword(w http.ResponseWriter, r *http.Request) error {
+	token := r.PathValue("token")
+	err := controller.authService.ValidateResetToken(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, exceptions.ErrInvalidResetToken) {
+			session.Flash(w, "danger", "This password reset link is invalid or has expired")
+			http.Redirect(w, r, "/forgot-password", http.StatusSeeOther)
+			return nil
+		}
+		return err
+	}
+
+	return utilities.Render(w, r, "auth/reset_password.html", utilities.Compact("token", token))
+}
+
+func (controller *AuthController) UpdatePassword(w http.ResponseWriter, r *http.Request) error {
+	data := &dto.ResetPasswordRequest{
+		Token: r.FormValue("token"),
+		Password: r.FormValue("password"),
+		PasswordConfirmation: r.FormValue("password_confirmation"),
+	}
+	err := validation.Validator.Struct(data)
+	if err != nil {
+		return err
+	}
+
+	err = controller.authService.ResetPassword(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, exceptions.ErrInvalidResetToken) {
+			return &exceptions.AppError{
+				Code: 400,
+				Message: "This password reset link is invalid or has expired",
+				Err: err,
+			}
+		}
+		return err
+	}
+
+	session.Flash(w, "success", "Your password has been reset, please log in with the new password")
+
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+	return nil
+}
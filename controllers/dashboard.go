@@ -2,17 +2,31 @@ package controllers
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/middlewares"
+	"github.com/anggadarkprince/crud-employee-go/models"
 	"github.com/anggadarkprince/crud-employee-go/services"
 	"github.com/anggadarkprince/crud-employee-go/utilities"
 )
 
+// expiringDocumentsLimit is the number of expiring documents listed on the dashboard
+const expiringDocumentsLimit = 10
+
 type DashboardController struct {
 	dashboardService *services.DashboardService
+	employeeDocumentService *services.EmployeeDocumentService
 }
 
-func NewDashboardController(dashboardService *services.DashboardService) *DashboardController {
-	return &DashboardController{dashboardService: dashboardService}
+func NewDashboardController(
+	dashboardService *services.DashboardService,
+	employeeDocumentService *services.EmployeeDocumentService,
+) *DashboardController {
+	return &DashboardController{
+		dashboardService: dashboardService,
+		employeeDocumentService: employeeDocumentService,
+	}
 }
 
 func (controller *DashboardController) Index(w http.ResponseWriter, r *http.Request) error {
@@ -21,9 +35,40 @@ func (controller *DashboardController) Index(w http.ResponseWriter, r *http.Requ
         return err
     }
 
+	var expiringDocuments *[]models.EmployeeDocument
+	if middlewares.Can(r, "employees.view") {
+		expiringDocuments, err = controller.employeeDocumentService.GetExpiring(r.Context(), expiringDocumentsLimit)
+		if err != nil {
+			return err
+		}
+	}
+
 	data := utilities.Compact(
 		"statistic", stats,
+		"ranges", services.DashboardRangeMonths,
+		"expiringDocuments", expiringDocuments,
+		"expiringDays", models.EmployeeDocumentExpiringDays,
 	)
 
 	return utilities.Render(w, r, "dashboard/index.html", data)
-}
\ No newline at end of file
+}
+
+// Analytics returns trends and breakdowns of the last "months" query (3, 6, 12 or 24), rendered as
+// charts on the dashboard and served to API clients
+func (controller *DashboardController) Analytics(w http.ResponseWriter, r *http.Request) error {
+	months, _ := strconv.Atoi(r.URL.Query().Get("months"))
+
+	stats, err := controller.dashboardService.GetStatistics(r.Context())
+	if err != nil {
+		return err
+	}
+	analytics, err := controller.dashboardService.GetAnalytics(r.Context(), months)
+	if err != nil {
+		return err
+	}
+
+	utilities.JSON(w, http.StatusOK, map[string]any{
+		"data": dto.NewDashboardAnalyticsResource(stats, analytics),
+	})
+	return nil
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type DepartmentController struct {
+	departmentService *services.DepartmentService
+}
+
+func NewDepartmentController(departmentService *services.DepartmentService) *DepartmentController {
+	return &DepartmentController{departmentService: departmentService}
+}
+
+func (controller *DepartmentController) Index(w http.ResponseWriter, r *http.Request) error {
+	departments, err := controller.departmentService.GetTree(r.Context())
+	if err != nil {
+		return err
+	}
+	return utilities.Render(w, r, "departments/index.html", utilities.Compact("departments", departments))
+}
+
+func (controller *DepartmentController) Create(w http.ResponseWriter, r *http.Request) error {
+	departments, err := controller.departmentService.GetTree(r.Context())
+	if err != nil {
+		return err
+	}
+	return utilities.Render(w, r, "departments/create.html", utilities.Compact("departments", departments))
+}
+
+func (controller *DepartmentController) Store(w http.ResponseWriter, r *http.Request) error {
+	data := &dto.CreateDepartmentRequest{
+		ParentId: parseFormId(r, "parent_id"),
+		Name: strings.TrimSpace(r.FormValue("name")),
+		Description: strings.TrimSpace(r.FormValue("description")),
+	}
+	if err := validation.Validator.Struct(data); err != nil {
+		return err
+	}
+
+	department, err := controller.departmentService.Store(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("Department %s successfully created", department.Name))
+
+	http.Redirect(w, r, "/departments", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *DepartmentController) Edit(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	department, err := controller.departmentService.GetById(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	departments, err := controller.departmentService.GetTree(r.Context())
+	if err != nil {
+		return err
+	}
+	return utilities.Render(w, r, "departments/edit.html", utilities.Compact("department", department, "departments", departments))
+}
+
+func (controller *DepartmentController) Update(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	data := &dto.UpdateDepartmentRequest{
+		Id: id,
+		ParentId: parseFormId(r, "parent_id"),
+		Name: strings.TrimSpace(r.FormValue("name")),
+		Description: strings.TrimSpace(r.FormValue("description")),
+	}
+	if err := validation.Validator.Struct(data); err != nil {
+		return err
+	}
+
+	department, err := controller.departmentService.Update(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("Department %s successfully updated", department.Name))
+
+	http.Redirect(w, r, "/departments", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *DepartmentController) Delete(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	department, err := controller.departmentService.Destroy(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "warning", fmt.Sprintf("Department %s successfully deleted", department.Name))
+
+	http.Redirect(w, r, "/departments", http.StatusSeeOther)
+	return nil
+}
@@ -3,10 +3,17 @@ package controllers
 import (
 	"database/sql"
 	"fmt"
+	"maps"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/anggadarkprince/crud-employee-go/configs"
 	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/middlewares"
+	"github.com/anggadarkprince/crud-employee-go/models"
 	"github.com/anggadarkprince/crud-employee-go/services"
 	"github.com/anggadarkprince/crud-employee-go/utilities"
 	"github.com/anggadarkprince/crud-employee-go/pkg/session"
@@ -34,33 +41,220 @@ type EmployeeAllowance struct {
 type EmployeeController struct {
 	employeeService          *services.EmployeeService
 	employeeAllowanceService *services.EmployeeAllowanceService
+	allowanceTypeService     *services.AllowanceTypeService
+	auditLogService          *services.AuditLogService
+	payrollService           *services.PayrollService
+	departmentService        *services.DepartmentService
+	positionService          *services.PositionService
+	employeeDocumentService  *services.EmployeeDocumentService
 }
 
 func NewEmployeeController(
 	employeeService *services.EmployeeService,
 	employeeAllowanceService *services.EmployeeAllowanceService,
+	allowanceTypeService *services.AllowanceTypeService,
+	auditLogService *services.AuditLogService,
+	payrollService *services.PayrollService,
+	departmentService *services.DepartmentService,
+	positionService *services.PositionService,
+	employeeDocumentService *services.EmployeeDocumentService,
 ) *EmployeeController {
 	return &EmployeeController{
 		employeeService:          employeeService,
 		employeeAllowanceService: employeeAllowanceService,
+		allowanceTypeService:     allowanceTypeService,
+		auditLogService:          auditLogService,
+		payrollService:           payrollService,
+		departmentService:        departmentService,
+		positionService:          positionService,
+		employeeDocumentService:  employeeDocumentService,
 	}
 }
 
+// parseEmployeeFilter reads list params from query string, invalid values fall back to defaults
+func parseEmployeeFilter(r *http.Request) *dto.EmployeeFilter {
+	query := r.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = 15
+	}
+	perPage = min(perPage, 100)
+
+	order := query.Get("order")
+	if order != "asc" {
+		order = "desc"
+	}
+
+	hiredFrom := query.Get("hired_from")
+	if _, err := utilities.StringToDate(hiredFrom); err != nil {
+		hiredFrom = ""
+	}
+	hiredTo := query.Get("hired_to")
+	if _, err := utilities.StringToDate(hiredTo); err != nil {
+		hiredTo = ""
+	}
+	department, _ := strconv.Atoi(query.Get("department"))
+	position, _ := strconv.Atoi(query.Get("position"))
+	manager, _ := strconv.Atoi(query.Get("manager"))
+
+	return &dto.EmployeeFilter{
+		Page:      page,
+		PerPage:   perPage,
+		Sort:      query.Get("sort"),
+		Order:     order,
+		Status:    query.Get("status"),
+		Gender:    query.Get("gender"),
+		HiredFrom: hiredFrom,
+		HiredTo:   hiredTo,
+		Allowance: query.Get("allowance"),
+		Department: department,
+		Position:   position,
+		Manager:    manager,
+	}
+}
+
+// parseFormId reads id of a select input, empty or invalid value means nothing is selected
+func parseFormId(r *http.Request, key string) int {
+	id, err := strconv.Atoi(strings.TrimSpace(r.FormValue(key)))
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// organizationOptions returns data of department, position and manager inputs
+func (c *EmployeeController) organizationOptions(r *http.Request) (map[string]any, error) {
+	departments, err := c.departmentService.GetTree(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	positions, err := c.positionService.GetAll(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	managers, err := c.employeeService.GetOptions(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return utilities.Compact("departments", departments, "positions", positions, "managers", managers), nil
+}
+
+// parseAllowanceAmounts reads amount overrides posted as allowance_amounts[CODE], empty input uses the default amount
+func parseAllowanceAmounts(r *http.Request) (map[string]models.Money, error) {
+	amounts := map[string]models.Money{}
+	for key, values := range r.Form {
+		code, ok := strings.CutPrefix(key, "allowance_amounts[")
+		if !ok || !strings.HasSuffix(code, "]") || len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+			continue
+		}
+		code = strings.TrimSuffix(code, "]")
+		amount, err := models.ParseMoney(strings.ReplaceAll(values[0], ",", ""))
+		if err != nil {
+			return nil, &exceptions.ValidationError{
+				Message: "Please check the data you provided.",
+				Errors: map[string]string{"allowance_amounts": fmt.Sprintf("Amount of %s must be a number with up to 2 decimals", code)},
+			}
+		}
+		amounts[code] = amount
+	}
+	return amounts, nil
+}
+
+// parseBaseSalary reads base salary that may be written with thousand separator, empty input is nil
+func parseBaseSalary(r *http.Request) (*models.Money, error) {
+	value := strings.ReplaceAll(strings.TrimSpace(r.FormValue("base_salary")), ",", "")
+	if value == "" {
+		return nil, nil
+	}
+	baseSalary, err := models.ParseMoney(value)
+	if err != nil {
+		return nil, &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{"base_salary": "Base salary must be a number with up to 2 decimals"},
+		}
+	}
+	return &baseSalary, nil
+}
+
 func (controller *EmployeeController) Index(w http.ResponseWriter, r *http.Request) error {
-	employees, err := controller.employeeService.GetAll(r.Context())
+	filter := parseEmployeeFilter(r)
+	employees, total, err := controller.employeeService.Paginate(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	allowanceTypes, err := controller.allowanceTypeService.GetAll(r.Context(), false)
+	if err != nil {
+		return err
+	}
+	options, err := controller.organizationOptions(r)
 	if err != nil {
 		return err
 	}
 
 	data := utilities.Compact(
 		"employees", employees,
+		"statuses", models.EmployeeStatuses,
+		"allowanceTypes", allowanceTypes,
+		"pagination", utilities.NewPagination(total, filter.Page, filter.PerPage, r.URL.Path, r.URL.Query()),
 	)
+	maps.Copy(data, options)
 
 	return utilities.Render(w, r, "employees/index.html", data)
 }
 
+// OrgChart renders reporting tree of employees, it can be scoped to a department and its sub departments
+func (c *EmployeeController) OrgChart(w http.ResponseWriter, r *http.Request) error {
+	department, _ := strconv.Atoi(r.URL.Query().Get("department"))
+	roots, err := c.employeeService.GetOrgChart(r.Context(), &dto.EmployeeFilter{Department: department})
+	if err != nil {
+		return err
+	}
+	departments, err := c.departmentService.GetTree(r.Context())
+	if err != nil {
+		return err
+	}
+	return utilities.Render(w, r, "employees/org_chart.html", utilities.Compact("roots", roots, "departments", departments))
+}
+
+// Trash lists soft deleted employees, latest deleted first
+func (controller *EmployeeController) Trash(w http.ResponseWriter, r *http.Request) error {
+	filter := parseEmployeeFilter(r)
+	filter.Trashed = true
+	if filter.Sort == "" {
+		filter.Sort = "deleted_at"
+	}
+	employees, total, err := controller.employeeService.Paginate(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	data := utilities.Compact(
+		"employees", employees,
+		"pagination", utilities.NewPagination(total, filter.Page, filter.PerPage, r.URL.Path, r.URL.Query()),
+		"retentionDays", configs.Get().Trash.RetentionDays,
+	)
+
+	return utilities.Render(w, r, "employees/trash.html", data)
+}
+
 func (c *EmployeeController) Create(w http.ResponseWriter, r *http.Request) error {
-	return utilities.Render(w, r, "employees/create.html", nil)
+	allowanceTypes, err := c.allowanceTypeService.GetAll(r.Context(), true)
+	if err != nil {
+		return err
+	}
+	options, err := c.organizationOptions(r)
+	if err != nil {
+		return err
+	}
+	data := utilities.Compact("allowanceTypes", allowanceTypes, "statuses", models.InitialEmployeeStatuses())
+	maps.Copy(data, options)
+	return utilities.Render(w, r, "employees/create.html", data)
 }
 
 func (c *EmployeeController) Store(w http.ResponseWriter, r *http.Request) error {
@@ -69,6 +263,14 @@ func (c *EmployeeController) Store(w http.ResponseWriter, r *http.Request) error
 	}
 
 	allowances := r.Form["allowances"]
+	allowanceAmounts, err := parseAllowanceAmounts(r)
+	if err != nil {
+		return err
+	}
+	baseSalary, err := parseBaseSalary(r)
+	if err != nil {
+		return err
+	}
 	data := &dto.CreateEmployeeRequest{
 		Name:       r.FormValue("name"),
 		Email:      r.FormValue("email"),
@@ -77,9 +279,16 @@ func (c *EmployeeController) Store(w http.ResponseWriter, r *http.Request) error
 		HiredDate:  r.FormValue("hired_date"),
 		Address:    r.FormValue("address"),
 		Status:     r.FormValue("status"),
+		DepartmentId: parseFormId(r, "department_id"),
+		PositionId: parseFormId(r, "position_id"),
+		ManagerId: parseFormId(r, "manager_id"),
 		Allowances: allowances,
+		AllowanceAmounts: allowanceAmounts,
+	}
+	if baseSalary != nil {
+		data.BaseSalary = *baseSalary
 	}
-	err := validation.Validator.Struct(data)
+	err = validation.Validator.Struct(data)
 	if err != nil {
 		return err
 	}
@@ -110,9 +319,49 @@ func (c *EmployeeController) View(w http.ResponseWriter, r *http.Request) error
 		return err
 	}
 
+	// History tab shows the latest changes, the rest is on audit page
+	var auditLogs *[]models.AuditLog
+	if middlewares.Can(r, "audit.view") {
+		auditLogs, _, err = c.auditLogService.Paginate(r.Context(), &dto.AuditLogFilter{
+			Page: 1,
+			PerPage: 50,
+			EntityType: models.AuditEntityEmployee,
+			EntityId: employee.Id,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var payslips *[]models.Payslip
+	if middlewares.Can(r, "payroll.view") {
+		payslips, err = c.payrollService.GetPayslipsByEmployeeId(r.Context(), employee.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	statusHistories, err := c.employeeService.GetStatusHistories(r.Context(), employee.Id)
+	if err != nil {
+		return err
+	}
+
+	documents, err := c.employeeDocumentService.GetByEmployeeId(r.Context(), employee.Id)
+	if err != nil {
+		return err
+	}
+
 	data := utilities.Compact(
 		"employee", employee,
 		"employeeAllowances", employeeAllowances,
+		"auditLogs", auditLogs,
+		"payslips", payslips,
+		"statusHistories", statusHistories,
+		"documents", documents,
+		"documentTypes", models.EmployeeDocumentTypes,
+		"maxDocumentSize", validation.MaxDocumentSize >> 20,
+		"maxDocumentFiles", validation.MaxDocumentFiles,
+		"today", time.Now().Format("2006-01-02"),
 	)
 	return utilities.Render(w, r, "employees/view.html", data)
 }
@@ -132,10 +381,32 @@ func (c *EmployeeController) Edit(w http.ResponseWriter, r *http.Request) error
 		return err
 	}
 
+	allowanceTypes, err := c.allowanceTypeService.GetAll(r.Context(), false)
+	if err != nil {
+		return err
+	}
+	allowanceCodes := []string{}
+	allowanceAmounts := map[string]string{}
+	for _, employeeAllowance := range *employeeAllowances {
+		allowanceCodes = append(allowanceCodes, employeeAllowance.Code)
+		if employeeAllowance.Amount.Valid {
+			allowanceAmounts[employeeAllowance.Code] = employeeAllowance.Amount.Money.String()
+		}
+	}
+
+	options, err := c.organizationOptions(r)
+	if err != nil {
+		return err
+	}
+
 	data := utilities.Compact(
 		"employee", employee,
 		"employeeAllowances", employeeAllowances,
+		"allowanceTypes", allowanceTypes,
+		"allowanceCodes", allowanceCodes,
+		"allowanceAmounts", allowanceAmounts,
 	)
+	maps.Copy(data, options)
 	return utilities.Render(w, r, "employees/edit.html", data)
 }
 
@@ -151,6 +422,14 @@ func (c *EmployeeController) Update(w http.ResponseWriter, r *http.Request) erro
 	}
 
 	allowances := r.Form["allowances"]
+	allowanceAmounts, err := parseAllowanceAmounts(r)
+	if err != nil {
+		return err
+	}
+	baseSalary, err := parseBaseSalary(r)
+	if err != nil {
+		return err
+	}
 	data := &dto.UpdateEmployeeRequest{
 		Id:         int(employeeId),
 		Name:       r.FormValue("name"),
@@ -160,7 +439,12 @@ func (c *EmployeeController) Update(w http.ResponseWriter, r *http.Request) erro
 		HiredDate:  r.FormValue("hired_date"),
 		Address:    r.FormValue("address"),
 		Status:     r.FormValue("status"),
+		BaseSalary: baseSalary,
+		DepartmentId: parseFormId(r, "department_id"),
+		PositionId: parseFormId(r, "position_id"),
+		ManagerId: parseFormId(r, "manager_id"),
 		Allowances: allowances,
+		AllowanceAmounts: allowanceAmounts,
 	}
 	err = validation.Validator.Struct(data)
 	if err != nil {
@@ -178,6 +462,32 @@ func (c *EmployeeController) Update(w http.ResponseWriter, r *http.Request) erro
 	return nil
 }
 
+// ChangeStatus moves the employee to the next status of the lifecycle
+func (c *EmployeeController) ChangeStatus(w http.ResponseWriter, r *http.Request) error {
+	employeeId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	data := &dto.ChangeEmployeeStatusRequest{
+		Id: employeeId,
+		Status: r.FormValue("status"),
+		Reason: strings.TrimSpace(r.FormValue("reason")),
+		EffectiveDate: r.FormValue("effective_date"),
+	}
+	if err := validation.Validator.Struct(data); err != nil {
+		return err
+	}
+
+	employee, err := c.employeeService.ChangeStatus(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("Employee %s is now %s", employee.Name, employee.StatusConfig().Label))
+	http.Redirect(w, r, fmt.Sprintf("/employees/%d", employee.Id), http.StatusSeeOther)
+	return nil
+}
+
 func (c *EmployeeController) Delete(w http.ResponseWriter, r *http.Request) error {
 	id := r.PathValue("id")
 
@@ -193,7 +503,42 @@ func (c *EmployeeController) Delete(w http.ResponseWriter, r *http.Request) erro
 	if err != nil {
 		return err
 	}
-	session.Flash(w, "warning", fmt.Sprintf("Employee %s successfully deleted", employee.Name))
+	session.Flash(w, "warning", fmt.Sprintf("Employee %s is moved to trash", employee.Name))
 	http.Redirect(w, r, "/employees", http.StatusSeeOther)
 	return nil
 }
+
+func (c *EmployeeController) Restore(w http.ResponseWriter, r *http.Request) error {
+	employeeId, err := strconv.ParseInt(r.PathValue("id"), 10, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := c.employeeService.GetTrashedById(r.Context(), int(employeeId)); err != nil {
+		return err
+	}
+	employee, err := c.employeeService.Restore(r.Context(), int(employeeId))
+	if err != nil {
+		return err
+	}
+	session.Flash(w, "success", fmt.Sprintf("Employee %s successfully restored", employee.Name))
+	http.Redirect(w, r, "/employees/trash", http.StatusSeeOther)
+	return nil
+}
+
+func (c *EmployeeController) Purge(w http.ResponseWriter, r *http.Request) error {
+	employeeId, err := strconv.ParseInt(r.PathValue("id"), 10, 0)
+	if err != nil {
+		return err
+	}
+	employee, err := c.employeeService.GetTrashedById(r.Context(), int(employeeId))
+	if err != nil {
+		return err
+	}
+	err = c.employeeService.Purge(r.Context(), int(employeeId))
+	if err != nil {
+		return err
+	}
+	session.Flash(w, "warning", fmt.Sprintf("Employee %s is permanently deleted", employee.Name))
+	http.Redirect(w, r, "/employees/trash", http.StatusSeeOther)
+	return nil
+}
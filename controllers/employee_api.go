@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type EmployeeApiController struct {
+	employeeService          *services.EmployeeService
+	employeeAllowanceService *services.EmployeeAllowanceService
+}
+
+func NewEmployeeApiController(
+	employeeService *services.EmployeeService,
+	employeeAllowanceService *services.EmployeeAllowanceService,
+) *EmployeeApiController {
+	return &EmployeeApiController{
+		employeeService:          employeeService,
+		employeeAllowanceService: employeeAllowanceService,
+	}
+}
+
+func parseEmployeeId(r *http.Request) (int, error) {
+	employeeId, err := strconv.ParseInt(r.PathValue("id"), 10, 0)
+	if err != nil {
+		return 0, &exceptions.AppError{
+			Code: http.StatusNotFound,
+			Message: "Employee not found",
+			Err: err,
+		}
+	}
+	return int(employeeId), nil
+}
+
+func (c *EmployeeApiController) Index(w http.ResponseWriter, r *http.Request) error {
+	filter := parseEmployeeFilter(r)
+	employees, total, err := c.employeeService.Paginate(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+	pagination := utilities.NewPagination(total, filter.Page, filter.PerPage, r.URL.Path, r.URL.Query())
+
+	links := map[string]any{
+		"first": pagination.PageUrl(1),
+		"last": pagination.PageUrl(pagination.Pages),
+		"prev": nil,
+		"next": nil,
+	}
+	if pagination.HasPrev() {
+		links["prev"] = pagination.PrevUrl()
+	}
+	if pagination.HasNext() {
+		links["next"] = pagination.NextUrl()
+	}
+
+	utilities.JSON(w, http.StatusOK, map[string]any{
+		"data": dto.NewEmployeeResources(*employees),
+		"meta": map[string]any{
+			"total": pagination.Total,
+			"page": pagination.Page,
+			"per_page": pagination.PerPage,
+			"pages": pagination.Pages,
+		},
+		"links": links,
+	})
+	return nil
+}
+
+func (c *EmployeeApiController) View(w http.ResponseWriter, r *http.Request) error {
+	employeeId, err := parseEmployeeId(r)
+	if err != nil {
+		return err
+	}
+	employee, err := c.employeeService.GetById(r.Context(), employeeId)
+	if err != nil {
+		return err
+	}
+
+	utilities.JSON(w, http.StatusOK, map[string]any{
+		"data": dto.NewEmployeeResource(employee),
+	})
+	return nil
+}
+
+func (c *EmployeeApiController) Store(w http.ResponseWriter, r *http.Request) error {
+	data := &dto.CreateEmployeeRequest{}
+	if err := utilities.ParseJSON(r, data); err != nil {
+		return &exceptions.AppError{
+			Code: http.StatusBadRequest,
+			Message: "Invalid JSON payload",
+			Err: err,
+		}
+	}
+	err := validation.Validator.Struct(data)
+	if err != nil {
+		return err
+	}
+
+	employee, err := c.employeeService.Store(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	utilities.JSON(w, http.StatusCreated, map[string]any{
+		"message": "Employee successfully created",
+		"data": dto.NewEmployeeResource(employee),
+	})
+	return nil
+}
+
+func (c *EmployeeApiController) Update(w http.ResponseWriter, r *http.Request) error {
+	employeeId, err := parseEmployeeId(r)
+	if err != nil {
+		return err
+	}
+	if _, err := c.employeeService.GetById(r.Context(), employeeId); err != nil {
+		return err
+	}
+
+	data := &dto.UpdateEmployeeRequest{}
+	if err := utilities.ParseJSON(r, data); err != nil {
+		return &exceptions.AppError{
+			Code: http.StatusBadRequest,
+			Message: "Invalid JSON payload",
+			Err: err,
+		}
+	}
+	data.Id = employeeId
+	err = validation.Validator.Struct(data)
+	if err != nil {
+		return err
+	}
+
+	employee, err := c.employeeService.Update(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	utilities.JSON(w, http.StatusOK, map[string]any{
+		"message": "Employee successfully updated",
+		"data": dto.NewEmployeeResource(employee),
+	})
+	return nil
+}
+
+func (c *EmployeeApiController) ChangeStatus(w http.ResponseWriter, r *http.Request) error {
+	employeeId, err := parseEmployeeId(r)
+	if err != nil {
+		return err
+	}
+	if _, err := c.employeeService.GetById(r.Context(), employeeId); err != nil {
+		return err
+	}
+
+	data := &dto.ChangeEmployeeStatusRequest{}
+	if err := utilities.ParseJSON(r, data); err != nil {
+		return &exceptions.AppError{
+			Code: http.StatusBadRequest,
+			Message: "Invalid JSON payload",
+			Err: err,
+		}
+	}
+	data.Id = employeeId
+	err = validation.Validator.Struct(data)
+	if err != nil {
+		return err
+	}
+
+	employee, err := c.employeeService.ChangeStatus(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	utilities.JSON(w, http.StatusOK, map[string]any{
+		"message": "Employee status successfully changed",
+		"data": dto.NewEmployeeResource(employee),
+	})
+	return nil
+}
+
+func (c *EmployeeApiController) Delete(w http.ResponseWriter, r *http.Request) error {
+	employeeId, err := parseEmployeeId(r)
+	if err != nil {
+		return err
+	}
+	if _, err := c.employeeService.GetById(r.Context(), employeeId); err != nil {
+		return err
+	}
+	err = c.employeeService.Destroy(r.Context(), employeeId)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (c *EmployeeApiController) Allowances(w http.ResponseWriter, r *http.Request) error {
+	employeeId, err := parseEmployeeId(r)
+	if err != nil {
+		return err
+	}
+	if _, err := c.employeeService.GetById(r.Context(), employeeId); err != nil {
+		return err
+	}
+	employeeAllowances, err := c.employeeAllowanceService.GetByEmployeeId(r.Context(), employeeId)
+	if err != nil {
+		return err
+	}
+
+	utilities.JSON(w, http.StatusOK, map[string]any{
+		"data": dto.NewEmployeeAllowanceResources(*employeeAllowances),
+	})
+	return nil
+}
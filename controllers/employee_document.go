@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/anggadarkprince/crud-employee-go/services"
+)
+
+type EmployeeDocumentController struct {
+	employeeDocumentService *services.EmployeeDocumentService
+}
+
+func NewEmployeeDocumentController(employeeDocumentService *services.EmployeeDocumentService) *EmployeeDocumentController {
+	return &EmployeeDocumentController{employeeDocumentService: employeeDocumentService}
+}
+
+// Store uploads one or more documents of the same type to the employee
+func (controller *EmployeeDocumentController) Store(w http.ResponseWriter, r *http.Request) error {
+	employeeId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		return err
+	}
+
+	data := &dto.StoreEmployeeDocumentsRequest{
+		EmployeeId: employeeId,
+		DocumentType: r.FormValue("document_type"),
+		Description: strings.TrimSpace(r.FormValue("description")),
+		ExpiredAt: r.FormValue("expired_at"),
+		Files: r.MultipartForm.File["files"],
+	}
+	if err := validation.Validator.Struct(data); err != nil {
+		return err
+	}
+
+	employee, documents, err := controller.employeeDocumentService.Store(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("%d documents of %s successfully uploaded", len(documents), employee.Name))
+	http.Redirect(w, r, fmt.Sprintf("/employees/%d#documents", employee.Id), http.StatusSeeOther)
+	return nil
+}
+
+// Download streams the document with its original filename, only images and PDF are allowed
+// to be uploaded but they are still downloaded as attachment
+func (controller *EmployeeDocumentController) Download(w http.ResponseWriter, r *http.Request) error {
+	employeeId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	documentId, err := strconv.Atoi(r.PathValue("documentId"))
+	if err != nil {
+		return err
+	}
+	document, err := controller.employeeDocumentService.GetById(r.Context(), employeeId, documentId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.NotFound(w, r)
+			return nil
+		}
+		return err
+	}
+
+	file, err := controller.employeeDocumentService.Open(r.Context(), document)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.NotFound(w, r)
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", document.MimeType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": document.FileName}))
+	w.Header().Set("Content-Length", strconv.FormatInt(document.FileSize, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	_, err = io.Copy(w, file)
+	return err
+}
+
+func (controller *EmployeeDocumentController) Delete(w http.ResponseWriter, r *http.Request) error {
+	employeeId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	documentId, err := strconv.Atoi(r.PathValue("documentId"))
+	if err != nil {
+		return err
+	}
+	document, err := controller.employeeDocumentService.Destroy(r.Context(), employeeId, documentId)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "warning", fmt.Sprintf("Document %s is deleted", document.FileName))
+	http.Redirect(w, r, fmt.Sprintf("/employees/%d#documents", employeeId), http.StatusSeeOther)
+	return nil
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/pkg/logger"
+	"github.com/anggadarkprince/crud-employee-go/services"
+)
+
+type EmployeeExportController struct {
+	employeeExportService *services.EmployeeExportService
+}
+
+func NewEmployeeExportController(employeeExportService *services.EmployeeExportService) *EmployeeExportController {
+	return &EmployeeExportController{employeeExportService: employeeExportService}
+}
+
+// Export downloads employees matching the list filters as CSV, XLSX or PDF
+func (controller *EmployeeExportController) Export(w http.ResponseWriter, r *http.Request) error {
+	format := r.URL.Query().Get("format")
+	contentType, ok := services.EmployeeExportFormats[format]
+	if !ok {
+		http.Error(w, "Export format must be csv, xlsx or pdf", http.StatusBadRequest)
+		return nil
+	}
+	filter := parseEmployeeFilter(r)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, controller.employeeExportService.Filename(format, time.Now())))
+
+	// Once the response is streaming the error can only be logged, before that it's shown to the user
+	writer := &exportWriter{w: w}
+	if err := controller.employeeExportService.Export(r.Context(), format, filter, writer); err != nil {
+		if !writer.written {
+			w.Header().Del("Content-Disposition")
+			return err
+		}
+		logger.LogError("Export employees failed", err, r)
+	}
+	return nil
+}
+
+// exportWriter tells whether the export has started writing the response
+type exportWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (writer *exportWriter) Write(p []byte) (int, error) {
+	writer.written = true
+	return writer.w.Write(p)
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/pkg/logger"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type EmployeeImportController struct {
+	employeeImportService *services.EmployeeImportService
+}
+
+func NewEmployeeImportController(employeeImportService *services.EmployeeImportService) *EmployeeImportController {
+	return &EmployeeImportController{employeeImportService: employeeImportService}
+}
+
+func (controller *EmployeeImportController) Index(w http.ResponseWriter, r *http.Request) error {
+	data := utilities.Compact(
+		"columns", services.EmployeeImportColumns,
+		"maxRows", services.EmployeeImportMaxRows,
+	)
+	return utilities.Render(w, r, "employees/import.html", data)
+}
+
+func (controller *EmployeeImportController) Template(w http.ResponseWriter, r *http.Request) error {
+	format := r.URL.Query().Get("format")
+	if format != "xlsx" {
+		format = "csv"
+	}
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	} else {
+		w.Header().Set("Content-Type", "text/csv")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="employee-import-template.%s"`, format))
+	return controller.employeeImportService.WriteTemplate(w, format)
+}
+
+// Preview parses the uploaded file, in dry run mode nothing is stored and valid rows
+// are carried to the confirm form, otherwise valid rows are imported right away
+func (controller *EmployeeImportController) Preview(w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		return err
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return &exceptions.ValidationError{
+			Message: "Please choose a file to import",
+			Errors: map[string]string{"file": "File is required"},
+		}
+	}
+	defer file.Close()
+	if header.Size > services.EmployeeImportMaxSize {
+		return &exceptions.ValidationError{
+			Message: "Please choose a smaller file to import",
+			Errors: map[string]string{"file": fmt.Sprintf("File must not be larger than %d MB", services.EmployeeImportMaxSize >> 20)},
+		}
+	}
+
+	rows, err := controller.employeeImportService.Parse(r.Context(), header.Filename, file)
+	if err != nil {
+		return err
+	}
+
+	validRows := []dto.EmployeeImportRow{}
+	for _, row := range rows {
+		if row.IsValid() {
+			validRows = append(validRows, row)
+		}
+	}
+
+	dryRun := r.FormValue("dry_run") != ""
+	imported := 0
+	if !dryRun && len(validRows) > 0 {
+		imported, err = controller.employeeImportService.Import(r.Context(), validRows)
+		if err != nil {
+			return err
+		}
+	}
+
+	validRowsJson, err := json.Marshal(validRows)
+	if err != nil {
+		return err
+	}
+
+	data := utilities.Compact(
+		"filename", header.Filename,
+		"dryRun", dryRun,
+		"rows", rows,
+		"totalRows", len(rows),
+		"validRows", len(validRows),
+		"invalidRows", len(rows)-len(validRows),
+		"validRowsJson", string(validRowsJson),
+		"imported", imported,
+	)
+	return utilities.Render(w, r, "employees/import_preview.html", data)
+}
+
+// Confirm imports the rows of dry run preview
+func (controller *EmployeeImportController) Confirm(w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	// Errors are flashed here instead of returned, the rows input is too large to be kept as old input
+	rows := []dto.EmployeeImportRow{}
+	if err := json.Unmarshal([]byte(r.FormValue("rows")), &rows); err != nil {
+		session.Flash(w, "danger", "Import data is invalid, please upload the file again")
+		http.Redirect(w, r, "/employees/import", http.StatusSeeOther)
+		return nil
+	}
+
+	imported, err := controller.employeeImportService.Import(r.Context(), rows)
+	if err != nil {
+		message := "Import failed, no employee is imported"
+		var validationErr *exceptions.ValidationError
+		if errors.As(err, &validationErr) {
+			message = validationErr.Message
+		} else {
+			logger.LogError("Import employees failed", err, r)
+		}
+		session.Flash(w, "danger", message)
+		http.Redirect(w, r, "/employees/import", http.StatusSeeOther)
+		return nil
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("%d employees successfully imported", imported))
+
+	http.Redirect(w, r, "/employees", http.StatusSeeOther)
+	return nil
+}
@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type ErrorController struct{}
+
+func NewErrorController() *ErrorController {
+	return &ErrorController{}
+}
+
+func (controller *ErrorController) Forbidden(w http.ResponseWriter, r *http.Request) error {
+	return utilities.RenderStatus(w, r, http.StatusForbidden, "errors/403.html", nil)
+}
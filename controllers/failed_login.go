@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type FailedLoginController struct {
+	loginThrottleService *services.LoginThrottleService
+}
+
+func NewFailedLoginController(loginThrottleService *services.LoginThrottleService) *FailedLoginController {
+	return &FailedLoginController{loginThrottleService: loginThrottleService}
+}
+
+func (controller *FailedLoginController) Index(w http.ResponseWriter, r *http.Request) error {
+	failedLogins, err := controller.loginThrottleService.GetSummaries(r.Context())
+	if err != nil {
+		return err
+	}
+
+	data := utilities.Compact("failedLogins", failedLogins)
+	return utilities.Render(w, r, "failed_logins/index.html", data)
+}
+
+func (controller *FailedLoginController) Delete(w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	username := r.FormValue("username")
+	ipAddress := r.FormValue("ip_address")
+
+	err := controller.loginThrottleService.ClearEntries(r.Context(), username, ipAddress)
+	if err != nil {
+		return err
+	}
+
+	if username == "" && ipAddress == "" {
+		session.Flash(w, "warning", "All failed logins are cleared")
+	} else {
+		session.Flash(w, "warning", "Failed logins of "+username+" from "+ipAddress+" are cleared, the login is unlocked")
+	}
+	http.Redirect(w, r, "/failed-logins", http.StatusSeeOther)
+	return nil
+}
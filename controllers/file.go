@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/middlewares"
+	"github.com/anggadarkprince/crud-employee-go/pkg/imaging"
+	"github.com/anggadarkprince/crud-employee-go/pkg/storage"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type FileController struct {
+	storage storage.Storage
+}
+
+func NewFileController(storage storage.Storage) *FileController {
+	return &FileController{storage: storage}
+}
+
+// canAccessFile checks the authenticated user against owner or permission of the file directory,
+// files outside the known directories are never served without signed url
+func canAccessFile(r *http.Request, filePath string) bool {
+	user := middlewares.GetUser(r)
+	if user == nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(filePath, "avatars/"):
+		return (user.Avatar.Valid && user.Avatar.String == imaging.OriginalPath(filePath)) || middlewares.Can(r, "users.manage")
+	default:
+		return false
+	}
+}
+
+// Show streams the stored file to user authorized by signed url or by ownership / permission,
+// only images are displayed inline, other files are downloaded so they never run in the page
+func (c *FileController) Show(w http.ResponseWriter, r *http.Request) error {
+	filePath := r.PathValue("path")
+	if !middlewares.IsSigned(r) && !canAccessFile(r, filePath) {
+		return utilities.RenderStatus(w, r, http.StatusForbidden, "errors/403.html", nil)
+	}
+
+	file, err := c.storage.Get(r.Context(), filePath)
+	// Images uploaded before thumbnails were generated only have the original
+	if errors.Is(err, fs.ErrNotExist) && imaging.OriginalPath(filePath) != filePath {
+		file, err = c.storage.Get(r.Context(), imaging.OriginalPath(filePath))
+	}
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.NotFound(w, r)
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(filePath))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	if strings.HasPrefix(contentType, "image/") && contentType != "image/svg+xml" {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": path.Base(filePath)}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+
+	// Local files support range and conditional requests
+	if seeker, ok := file.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, path.Base(filePath), time.Time{}, seeker)
+		return nil
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type PayrollController struct {
+	payrollService *services.PayrollService
+}
+
+func NewPayrollController(payrollService *services.PayrollService) *PayrollController {
+	return &PayrollController{payrollService: payrollService}
+}
+
+var payrollStatuses = []string{models.PayrollStatusDraft, models.PayrollStatusReviewed, models.PayrollStatusLocked}
+
+func (controller *PayrollController) Index(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	year, _ := strconv.Atoi(query.Get("year"))
+	filter := &dto.PayrollRunFilter{
+		Page: page,
+		PerPage: 15,
+		Status: query.Get("status"),
+		Year: year,
+	}
+
+	runs, total, err := controller.payrollService.Paginate(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	data := utilities.Compact(
+		"runs", runs,
+		"statuses", payrollStatuses,
+		"currentPeriod", time.Now().Format("2006-01"),
+		"pagination", utilities.NewPagination(total, filter.Page, filter.PerPage, r.URL.Path, query),
+	)
+	return utilities.Render(w, r, "payroll/index.html", data)
+}
+
+func (controller *PayrollController) Store(w http.ResponseWriter, r *http.Request) error {
+	data := &dto.CreatePayrollRunRequest{Period: r.FormValue("period")}
+	if err := validation.Validator.Struct(data); err != nil {
+		return err
+	}
+
+	run, err := controller.payrollService.Create(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("Payroll of %s is created with %d payslips", run.PeriodLabel(), run.TotalEmployee))
+	http.Redirect(w, r, fmt.Sprintf("/payroll/%d", run.Id), http.StatusSeeOther)
+	return nil
+}
+
+func (controller *PayrollController) View(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	run, err := controller.payrollService.GetById(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	payslips, err := controller.payrollService.GetPayslips(r.Context(), run.Id)
+	if err != nil {
+		return err
+	}
+	correctedPayslips, err := controller.payrollService.GetCorrectedPayslips(r.Context(), run)
+	if err != nil {
+		return err
+	}
+
+	data := utilities.Compact(
+		"run", run,
+		"payslips", payslips,
+		"correctedPayslips", correctedPayslips,
+	)
+	return utilities.Render(w, r, "payroll/view.html", data)
+}
+
+func (controller *PayrollController) Recalculate(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	run, err := controller.payrollService.Recalculate(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	session.Flash(w, "success", fmt.Sprintf("Payroll of %s is recalculated with %d payslips", run.PeriodLabel(), run.TotalEmployee))
+	http.Redirect(w, r, fmt.Sprintf("/payroll/%d", run.Id), http.StatusSeeOther)
+	return nil
+}
+
+func (controller *PayrollController) Review(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	run, err := controller.payrollService.Review(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	session.Flash(w, "success", fmt.Sprintf("Payroll of %s is reviewed, lock it to finalize the payslips", run.PeriodLabel()))
+	http.Redirect(w, r, fmt.Sprintf("/payroll/%d", run.Id), http.StatusSeeOther)
+	return nil
+}
+
+func (controller *PayrollController) Reopen(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	run, err := controller.payrollService.Reopen(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	session.Flash(w, "warning", fmt.Sprintf("Payroll of %s is reopened as draft", run.PeriodLabel()))
+	http.Redirect(w, r, fmt.Sprintf("/payroll/%d", run.Id), http.StatusSeeOther)
+	return nil
+}
+
+func (controller *PayrollController) Lock(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	run, err := controller.payrollService.Lock(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	session.Flash(w, "success", fmt.Sprintf("Payroll of %s is locked", run.PeriodLabel()))
+	http.Redirect(w, r, fmt.Sprintf("/payroll/%d", run.Id), http.StatusSeeOther)
+	return nil
+}
+
+// Correct reruns locked payroll as a new draft run, the reason is required
+func (controller *PayrollController) Correct(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	data := &dto.CreatePayrollCorrectionRequest{
+		Id: id,
+		Notes: r.FormValue("notes"),
+	}
+	if err := validation.Validator.Struct(data); err != nil {
+		return err
+	}
+
+	run, err := controller.payrollService.Correct(r.Context(), data)
+	if err != nil {
+		return err
+	}
+	session.Flash(w, "success", fmt.Sprintf("Correction of %s payroll is created with %d payslips", run.PeriodLabel(), run.TotalEmployee))
+	http.Redirect(w, r, fmt.Sprintf("/payroll/%d", run.Id), http.StatusSeeOther)
+	return nil
+}
+
+func (controller *PayrollController) Delete(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	run, err := controller.payrollService.Destroy(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	session.Flash(w, "warning", fmt.Sprintf("Draft payroll of %s is deleted", run.PeriodLabel()))
+	http.Redirect(w, r, "/payroll", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *PayrollController) Payslip(w http.ResponseWriter, r *http.Request) error {
+	run, payslip, err := controller.findPayslip(r)
+	if err != nil {
+		return err
+	}
+	return utilities.Render(w, r, "payroll/payslip.html", utilities.Compact("run", run, "payslip", payslip))
+}
+
+// PayslipPdf downloads the payslip, the document is small so it's built in memory
+// and a failure can still be reported as an error page
+func (controller *PayrollController) PayslipPdf(w http.ResponseWriter, r *http.Request) error {
+	run, payslip, err := controller.findPayslip(r)
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	if err := controller.payrollService.WritePayslipPdf(&buffer, run, payslip); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, controller.payrollService.PayslipFilename(run, payslip)))
+	_, err = buffer.WriteTo(w)
+	return err
+}
+
+func (controller *PayrollController) findPayslip(r *http.Request) (*models.PayrollRun, *models.Payslip, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return nil, nil, err
+	}
+	payslipId, err := strconv.Atoi(r.PathValue("payslipId"))
+	if err != nil {
+		return nil, nil, err
+	}
+	run, err := controller.payrollService.GetById(r.Context(), id)
+	if err != nil {
+		return nil, nil, err
+	}
+	payslip, err := controller.payrollService.GetPayslip(r.Context(), run.Id, payslipId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, payslip, nil
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type PositionController struct {
+	positionService *services.PositionService
+}
+
+func NewPositionController(positionService *services.PositionService) *PositionController {
+	return &PositionController{positionService: positionService}
+}
+
+func (controller *PositionController) Index(w http.ResponseWriter, r *http.Request) error {
+	positions, err := controller.positionService.GetAll(r.Context())
+	if err != nil {
+		return err
+	}
+	return utilities.Render(w, r, "positions/index.html", utilities.Compact("positions", positions))
+}
+
+func (controller *PositionController) Create(w http.ResponseWriter, r *http.Request) error {
+	return utilities.Render(w, r, "positions/create.html", nil)
+}
+
+func (controller *PositionController) Store(w http.ResponseWriter, r *http.Request) error {
+	data := &dto.CreatePositionRequest{
+		Name: strings.TrimSpace(r.FormValue("name")),
+		Description: strings.TrimSpace(r.FormValue("description")),
+	}
+	if err := validation.Validator.Struct(data); err != nil {
+		return err
+	}
+
+	position, err := controller.positionService.Store(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("Position %s successfully created", position.Name))
+
+	http.Redirect(w, r, "/positions", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *PositionController) Edit(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	position, err := controller.positionService.GetById(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	return utilities.Render(w, r, "positions/edit.html", utilities.Compact("position", position))
+}
+
+func (controller *PositionController) Update(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	data := &dto.UpdatePositionRequest{
+		Id: id,
+		Name: strings.TrimSpace(r.FormValue("name")),
+		Description: strings.TrimSpace(r.FormValue("description")),
+	}
+	if err := validation.Validator.Struct(data); err != nil {
+		return err
+	}
+
+	position, err := controller.positionService.Update(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("Position %s successfully updated", position.Name))
+
+	http.Redirect(w, r, "/positions", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *PositionController) Delete(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	position, err := controller.positionService.Destroy(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "warning", fmt.Sprintf("Position %s successfully deleted", position.Name))
+
+	http.Redirect(w, r, "/positions", http.StatusSeeOther)
+	return nil
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type RoleController struct {
+	roleService *services.RoleService
+}
+
+func NewRoleController(roleService *services.RoleService) *RoleController {
+	return &RoleController{roleService: roleService}
+}
+
+func (controller *RoleController) Index(w http.ResponseWriter, r *http.Request) error {
+	roles, err := controller.roleService.GetAll(r.Context())
+	if err != nil {
+		return err
+	}
+	permissions, err := controller.roleService.GetPermissions(r.Context())
+	if err != nil {
+		return err
+	}
+
+	data := utilities.Compact(
+		"roles", roles,
+		"permissions", permissions,
+	)
+	return utilities.Render(w, r, "roles/index.html", data)
+}
+
+func (controller *RoleController) Update(w http.ResponseWriter, r *http.Request) error {
+	roleId, err := strconv.ParseInt(r.PathValue("id"), 10, 0)
+	if err != nil {
+		return err
+	}
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	data := &dto.UpdateRolePermissionRequest{
+		Id: int(roleId),
+		Permissions: r.Form["permissions"],
+		RequireTwoFactor: r.FormValue("require_two_factor") == "1",
+	}
+	err = validation.Validator.Struct(data)
+	if err != nil {
+		return err
+	}
+
+	role, err := controller.roleService.UpdatePermissions(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("Role %s successfully updated", role.Name))
+	http.Redirect(w, r, "/roles", http.StatusSeeOther)
+	return nil
+}
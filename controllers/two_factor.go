@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/middlewares"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/signature"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+// twoFactorCookieName holds the user who passed the password check but not the second factor yet
+const twoFactorCookieName = "two_factor_login"
+
+const twoFactorCookieLifetime = 5 * time.Minute
+
+type TwoFactorController struct {
+	authService *services.AuthService
+	userService *services.UserService
+	twoFactorService *services.TwoFactorService
+	loginThrottleService *services.LoginThrottleService
+}
+
+func NewTwoFactorController(
+	authService *services.AuthService,
+	userService *services.UserService,
+	twoFactorService *services.TwoFactorService,
+	loginThrottleService *services.LoginThrottleService,
+) *TwoFactorController {
+	return &TwoFactorController{
+		authService: authService,
+		userService: userService,
+		twoFactorService: twoFactorService,
+		loginThrottleService: loginThrottleService,
+	}
+}
+
+// setTwoFactorCookie stores signed "{user id}.{expiration}.{remember}" pending login
+func setTwoFactorCookie(w http.ResponseWriter, userId int, remember bool) {
+	rememberFlag := "0"
+	if remember {
+		rememberFlag = "1"
+	}
+	payload := fmt.Sprintf("%d.%d.%s", userId, time.Now().Add(twoFactorCookieLifetime).Unix(), rememberFlag)
+	http.SetCookie(w, &http.Cookie{
+		Name: twoFactorCookieName,
+		Value: payload + "." + signature.Sign("two-factor-login|" + payload),
+		Path: configs.Get().Session.Path,
+		HttpOnly: true,
+		Secure: configs.Get().Session.Secure,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge: int(twoFactorCookieLifetime.Seconds()),
+	})
+}
+
+func clearTwoFactorCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name: twoFactorCookieName,
+		Value: "",
+		Path: configs.Get().Session.Path,
+		HttpOnly: true,
+		Secure: configs.Get().Session.Secure,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge: -1,
+	})
+}
+
+func parseTwoFactorCookie(r *http.Request) (userId int, remember bool, ok bool) {
+	cookie, err := r.Cookie(twoFactorCookieName)
+	if err != nil {
+		return 0, false, false
+	}
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 4 {
+		return 0, false, false
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !signature.Verify("two-factor-login|" + payload, parts[3]) {
+		return 0, false, false
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return 0, false, false
+	}
+	userId, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false, false
+	}
+	return userId, parts[2] == "1", true
+}
+
+func (controller *TwoFactorController) Challenge(w http.ResponseWriter, r *http.Request) error {
+	if _, _, ok := parseTwoFactorCookie(r); !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil
+	}
+	return utilities.Render(w, r, "auth/two_factor_challenge.html", nil)
+}
+
+func (controller *TwoFactorController) VerifyChallenge(w http.ResponseWriter, r *http.Request) error {
+	userId, remember, ok := parseTwoFactorCookie(r)
+	if !ok {
+		session.Flash(w, "warning", "Your login session has expired, please log in again")
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil
+	}
+	user, err := controller.userService.GetById(r.Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	// Codes are throttled like passwords, 6 digits are easy to guess otherwise
+	ipAddress := utilities.ClientIP(r)
+	wait, err := controller.loginThrottleService.RetryAfterTwoFactor(r.Context(), user.Username, ipAddress)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return &exceptions.AppError{
+			Code: http.StatusTooManyRequests,
+			Message: fmt.Sprintf("Too many attempts, please try again in %d seconds", int(math.Ceil(wait.Seconds()))),
+		}
+	}
+
+	err = controller.twoFactorService.Verify(r.Context(), user, r.FormValue("code"), r.FormValue("recovery_code"))
+	if err != nil {
+		if errors.Is(err, exceptions.ErrInvalidTwoFactorCode) {
+			if err := controller.loginThrottleService.RecordTwoFactorFailure(r.Context(), user.Username, ipAddress, r.UserAgent()); err != nil {
+				return err
+			}
+			return &exceptions.AppError{
+				Code: 401,
+				Message: "The provided two-factor code is invalid",
+				Err: err,
+			}
+		}
+		return err
+	}
+	if err := controller.loginThrottleService.Clear(r.Context(), user.Username, ipAddress); err != nil {
+		return err
+	}
+
+	clearTwoFactorCookie(w)
+	if err := startSession(w, r, controller.authService, user.Id, remember); err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *TwoFactorController) Enable(w http.ResponseWriter, r *http.Request) error {
+	user := middlewares.GetUser(r)
+	if err := controller.twoFactorService.Enable(r.Context(), user); err != nil {
+		return err
+	}
+
+	session.Flash(w, "info", "Scan the QR code with your authenticator app and enter the code to finish enabling two-factor authentication")
+	http.Redirect(w, r, "/account#two-factor", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *TwoFactorController) Confirm(w http.ResponseWriter, r *http.Request) error {
+	user := middlewares.GetUser(r)
+	codes, err := controller.twoFactorService.Confirm(r.Context(), user, r.FormValue("code"))
+	if err != nil {
+		return err
+	}
+
+	return renderRecoveryCodes(w, r, "Two-factor authentication is enabled, use a recovery code to log in when you don't have access to your device", codes)
+}
+
+func (controller *TwoFactorController) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) error {
+	user := middlewares.GetUser(r)
+	codes, err := controller.twoFactorService.RegenerateRecoveryCodes(r.Context(), user, r.FormValue("two_factor_password"))
+	if err != nil {
+		return err
+	}
+
+	return renderRecoveryCodes(w, r, "New recovery codes are generated, the previous codes no longer work", codes)
+}
+
+func (controller *TwoFactorController) Disable(w http.ResponseWriter, r *http.Request) error {
+	user := middlewares.GetUser(r)
+	err := controller.twoFactorService.Disable(r.Context(), user, r.FormValue("two_factor_password"))
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "warning", "Two-factor authentication is disabled")
+	http.Redirect(w, r, "/account#two-factor", http.StatusSeeOther)
+	return nil
+}
+
+// renderRecoveryCodes shows the plain recovery codes once in the response, only their hashes are stored,
+// they are not kept in the flash cookie and the page is not cached
+func renderRecoveryCodes(w http.ResponseWriter, r *http.Request, message string, codes []string) error {
+	w.Header().Set("Cache-Control", "private, no-store")
+	data := utilities.Compact(
+		"message", message,
+		"recoveryCodes", codes,
+	)
+	return utilities.Render(w, r, "account/recovery_codes.html", data)
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/middlewares"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/anggadarkprince/crud-employee-go/services"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type UserController struct {
+	userService *services.UserService
+	roleService *services.RoleService
+}
+
+func NewUserController(userService *services.UserService, roleService *services.RoleService) *UserController {
+	return &UserController{userService: userService, roleService: roleService}
+}
+
+func (controller *UserController) Index(w http.ResponseWriter, r *http.Request) error {
+	users, err := controller.userService.GetAll(r.Context())
+	if err != nil {
+		return err
+	}
+	roles, err := controller.roleService.GetAll(r.Context())
+	if err != nil {
+		return err
+	}
+
+	data := utilities.Compact(
+		"users", users,
+		"roles", roles,
+	)
+	return utilities.Render(w, r, "users/index.html", data)
+}
+
+func (controller *UserController) UpdateRole(w http.ResponseWriter, r *http.Request) error {
+	userId, err := strconv.ParseInt(r.PathValue("id"), 10, 0)
+	if err != nil {
+		return err
+	}
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	data := &dto.UpdateUserRoleRequest{
+		Id: int(userId),
+		UserType: r.FormValue("user_type"),
+	}
+	err = validation.Validator.Struct(data)
+	if err != nil {
+		return err
+	}
+
+	user, err := controller.userService.UpdateRole(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("Role of user %s successfully changed to %s", user.Name, user.UserType))
+	http.Redirect(w, r, "/users", http.StatusSeeOther)
+	return nil
+}
+
+func (controller *UserController) UpdateStatus(w http.ResponseWriter, r *http.Request) error {
+	userId, err := strconv.ParseInt(r.PathValue("id"), 10, 0)
+	if err != nil {
+		return err
+	}
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	data := &dto.UpdateUserStatusRequest{
+		Id: int(userId),
+		ActorId: middlewares.GetUser(r).Id,
+		Status: r.FormValue("status"),
+	}
+	err = validation.Validator.Struct(data)
+	if err != nil {
+		return err
+	}
+
+	user, err := controller.userService.UpdateStatus(r.Context(), data)
+	if err != nil {
+		return err
+	}
+
+	session.Flash(w, "success", fmt.Sprintf("User %s is %s", user.Name, user.Status))
+	http.Redirect(w, r, "/users", http.StatusSeeOther)
+	return nil
+}
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS employee_allowances (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    employee_id INT UNSIGNED NOT NULL,
+    allowance VARCHAR(50) NOT NULL,
+    PRIMARY KEY (id),
+    KEY employee_allowances_employee_id_index (employee_id),
+    CONSTRAINT employee_allowances_employee_id_foreign
+        FOREIGN KEY (employee_id) REFERENCES employees (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
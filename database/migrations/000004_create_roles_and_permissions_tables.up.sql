@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS roles (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(20) NOT NULL,
+    description VARCHAR(255) NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY roles_name_unique (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(255) NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY permissions_name_unique (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INT UNSIGNED NOT NULL,
+    permission_id INT UNSIGNED NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    CONSTRAINT role_permissions_role_id_foreign
+        FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE,
+    CONSTRAINT role_permissions_permission_id_foreign
+        FOREIGN KEY (permission_id) REFERENCES permissions (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Role name matches users.user_type
+INSERT INTO roles (name, description) VALUES
+    ('ADMINISTRATOR', 'Full access including user management'),
+    ('INTERNAL', 'Internal staff managing employee data'),
+    ('EXTERNAL', 'Self registered user with read only access');
+
+INSERT INTO permissions (name, description) VALUES
+    ('employees.view', 'View employee list and detail'),
+    ('employees.create', 'Create new employee'),
+    ('employees.edit', 'Edit existing employee'),
+    ('employees.delete', 'Delete employee'),
+    ('users.manage', 'Manage users, roles and permissions');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles CROSS JOIN permissions
+WHERE roles.name = 'ADMINISTRATOR'
+    OR (roles.name = 'INTERNAL' AND permissions.name IN ('employees.view', 'employees.create', 'employees.edit'))
+    OR (roles.name = 'EXTERNAL' AND permissions.name IN ('employees.view'));
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token CHAR(64) NOT NULL,
+    scopes VARCHAR(1000) NOT NULL DEFAULT '',
+    expired_at DATETIME NULL,
+    last_used_at DATETIME NULL,
+    last_used_ip VARCHAR(45) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY personal_access_tokens_token_unique (token),
+    KEY personal_access_tokens_user_id_index (user_id),
+    CONSTRAINT personal_access_tokens_user_id_foreign
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti CHAR(32) NOT NULL,
+    user_id INT UNSIGNED NOT NULL,
+    expired_at DATETIME NOT NULL,
+    revoked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (jti),
+    KEY revoked_tokens_expired_at_index (expired_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS failed_logins (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    username VARCHAR(100) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    user_agent VARCHAR(255) NULL,
+    attempted_at DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    KEY failed_logins_username_attempted_at_index (username, attempted_at),
+    KEY failed_logins_ip_address_attempted_at_index (ip_address, attempted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
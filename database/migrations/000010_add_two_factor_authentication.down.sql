@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS two_factor_recovery_codes;
+
+ALTER TABLE roles DROP COLUMN require_two_factor;
+
+ALTER TABLE users DROP COLUMN two_factor_last_step;
+ALTER TABLE users DROP COLUMN two_factor_confirmed_at;
+ALTER TABLE users DROP COLUMN two_factor_secret;
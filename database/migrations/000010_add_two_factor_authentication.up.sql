@@ -0,0 +1,17 @@
+ALTER TABLE users ADD COLUMN two_factor_secret VARCHAR(255) NULL AFTER sessions_revoked_at;
+ALTER TABLE users ADD COLUMN two_factor_confirmed_at DATETIME NULL AFTER two_factor_secret;
+ALTER TABLE users ADD COLUMN two_factor_last_step BIGINT NULL AFTER two_factor_confirmed_at;
+
+ALTER TABLE roles ADD COLUMN require_two_factor TINYINT(1) NOT NULL DEFAULT 0 AFTER `description`;
+
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    code CHAR(64) NOT NULL,
+    used_at DATETIME NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    KEY two_factor_recovery_codes_user_id_index (user_id),
+    CONSTRAINT two_factor_recovery_codes_user_id_foreign
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
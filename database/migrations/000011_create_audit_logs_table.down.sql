@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'audit.view';
+
+DROP TABLE IF EXISTS audit_logs;
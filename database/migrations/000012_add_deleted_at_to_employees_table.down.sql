@@ -0,0 +1,2 @@
+ALTER TABLE employees DROP INDEX employees_deleted_at_index;
+ALTER TABLE employees DROP COLUMN deleted_at;
@@ -0,0 +1,2 @@
+ALTER TABLE employees ADD COLUMN deleted_at DATETIME NULL AFTER updated_at;
+ALTER TABLE employees ADD INDEX employees_deleted_at_index (deleted_at);
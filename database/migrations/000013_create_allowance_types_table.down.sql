@@ -0,0 +1,15 @@
+DELETE FROM permissions WHERE name = 'allowances.manage';
+
+ALTER TABLE employee_allowances DROP FOREIGN KEY employee_allowances_allowance_type_id_foreign;
+ALTER TABLE employee_allowances DROP INDEX employee_allowances_allowance_type_id_foreign;
+ALTER TABLE employee_allowances ADD COLUMN allowance VARCHAR(50) NULL AFTER employee_id;
+
+UPDATE employee_allowances SET allowance = (
+    SELECT allowance_types.name FROM allowance_types WHERE allowance_types.id = employee_allowances.allowance_type_id
+);
+
+ALTER TABLE employee_allowances MODIFY allowance VARCHAR(50) NOT NULL;
+ALTER TABLE employee_allowances DROP COLUMN amount;
+ALTER TABLE employee_allowances DROP COLUMN allowance_type_id;
+
+DROP TABLE IF EXISTS allowance_types;
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS allowance_types (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    default_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    is_taxable TINYINT(1) NOT NULL DEFAULT 0,
+    is_active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY allowance_types_code_unique (code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO allowance_types (code, name, is_taxable) VALUES
+    ('MEDICAL', 'Medical', 0),
+    ('TRANSPORTATION', 'Transportation', 1),
+    ('HOUSING', 'Housing', 1),
+    ('EDUCATION', 'Education', 0),
+    ('CHILDCARE', 'Childcare', 0),
+    ('ENTERTAINMENT', 'Entertainment', 1);
+
+-- Labels outside the catalog (e.g. posted directly) are kept as inactive types so no allowance is lost
+INSERT INTO allowance_types (code, name, is_active)
+SELECT DISTINCT UPPER(REPLACE(TRIM(allowance), ' ', '_')), TRIM(allowance), 0 FROM employee_allowances
+WHERE TRIM(allowance) NOT IN (SELECT name FROM allowance_types);
+
+ALTER TABLE employee_allowances ADD COLUMN allowance_type_id INT UNSIGNED NULL AFTER employee_id;
+ALTER TABLE employee_allowances ADD COLUMN amount DECIMAL(15,2) NULL AFTER allowance_type_id;
+
+UPDATE employee_allowances SET allowance_type_id = (
+    SELECT allowance_types.id FROM allowance_types WHERE allowance_types.name = TRIM(employee_allowances.allowance)
+);
+
+ALTER TABLE employee_allowances MODIFY allowance_type_id INT UNSIGNED NOT NULL;
+ALTER TABLE employee_allowances DROP COLUMN allowance;
+ALTER TABLE employee_allowances ADD CONSTRAINT employee_allowances_allowance_type_id_foreign
+    FOREIGN KEY (allowance_type_id) REFERENCES allowance_types (id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('allowances.manage', 'Manage allowance types and their default amounts');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles CROSS JOIN permissions
+WHERE roles.name = 'ADMINISTRATOR' AND permissions.name = 'allowances.manage';
@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE name IN ('payroll.view', 'payroll.manage');
+
+DROP TABLE IF EXISTS payslip_items;
+DROP TABLE IF EXISTS payslips;
+DROP TABLE IF EXISTS payroll_runs;
+
+ALTER TABLE employees DROP COLUMN base_salary;
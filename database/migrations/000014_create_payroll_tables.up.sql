@@ -0,0 +1,69 @@
+ALTER TABLE employees ADD COLUMN base_salary DECIMAL(15,2) NOT NULL DEFAULT 0 AFTER `status`;
+
+CREATE TABLE IF NOT EXISTS payroll_runs (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    period DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'DRAFT',
+    corrects_run_id INT UNSIGNED NULL,
+    notes VARCHAR(500) NULL,
+    total_employee INT UNSIGNED NOT NULL DEFAULT 0,
+    total_gross DECIMAL(15,2) NOT NULL DEFAULT 0,
+    total_deduction DECIMAL(15,2) NOT NULL DEFAULT 0,
+    total_net DECIMAL(15,2) NOT NULL DEFAULT 0,
+    created_by INT UNSIGNED NULL,
+    reviewed_by INT UNSIGNED NULL,
+    reviewed_at DATETIME NULL,
+    locked_by INT UNSIGNED NULL,
+    locked_at DATETIME NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    KEY payroll_runs_period_index (period),
+    CONSTRAINT payroll_runs_corrects_run_id_foreign
+        FOREIGN KEY (corrects_run_id) REFERENCES payroll_runs (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Employee data is copied so payslips stay the same after the employee is changed or purged
+CREATE TABLE IF NOT EXISTS payslips (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    payroll_run_id INT UNSIGNED NOT NULL,
+    employee_id INT UNSIGNED NULL,
+    employee_name VARCHAR(100) NOT NULL,
+    employee_email VARCHAR(100) NULL,
+    employee_tax_number VARCHAR(20) NULL,
+    base_salary DECIMAL(15,2) NOT NULL DEFAULT 0,
+    total_allowance DECIMAL(15,2) NOT NULL DEFAULT 0,
+    total_deduction DECIMAL(15,2) NOT NULL DEFAULT 0,
+    gross_pay DECIMAL(15,2) NOT NULL DEFAULT 0,
+    net_pay DECIMAL(15,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY payslips_payroll_run_id_employee_id_unique (payroll_run_id, employee_id),
+    KEY payslips_employee_id_index (employee_id),
+    CONSTRAINT payslips_payroll_run_id_foreign
+        FOREIGN KEY (payroll_run_id) REFERENCES payroll_runs (id) ON DELETE CASCADE,
+    CONSTRAINT payslips_employee_id_foreign
+        FOREIGN KEY (employee_id) REFERENCES employees (id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS payslip_items (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    payslip_id INT UNSIGNED NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    is_taxable TINYINT(1) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    KEY payslip_items_payslip_id_index (payslip_id),
+    CONSTRAINT payslip_items_payslip_id_foreign
+        FOREIGN KEY (payslip_id) REFERENCES payslips (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO permissions (name, description) VALUES
+    ('payroll.view', 'View payroll runs and payslips'),
+    ('payroll.manage', 'Run, review and lock payroll');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles CROSS JOIN permissions
+WHERE roles.name = 'ADMINISTRATOR' AND permissions.name IN ('payroll.view', 'payroll.manage');
@@ -0,0 +1,14 @@
+DELETE FROM permissions WHERE name = 'organization.manage';
+
+ALTER TABLE employees DROP FOREIGN KEY employees_manager_id_foreign;
+ALTER TABLE employees DROP FOREIGN KEY employees_position_id_foreign;
+ALTER TABLE employees DROP FOREIGN KEY employees_department_id_foreign;
+ALTER TABLE employees DROP INDEX employees_manager_id_foreign;
+ALTER TABLE employees DROP INDEX employees_position_id_foreign;
+ALTER TABLE employees DROP INDEX employees_department_id_foreign;
+ALTER TABLE employees DROP COLUMN manager_id;
+ALTER TABLE employees DROP COLUMN position_id;
+ALTER TABLE employees DROP COLUMN department_id;
+
+DROP TABLE IF EXISTS positions;
+DROP TABLE IF EXISTS departments;
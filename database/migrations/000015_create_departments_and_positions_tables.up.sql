@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS departments (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    parent_id INT UNSIGNED NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(255) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY departments_name_unique (name),
+    KEY departments_parent_id_index (parent_id),
+    CONSTRAINT departments_parent_id_foreign
+        FOREIGN KEY (parent_id) REFERENCES departments (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS positions (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(255) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY positions_name_unique (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE employees ADD COLUMN department_id INT UNSIGNED NULL;
+ALTER TABLE employees ADD COLUMN position_id INT UNSIGNED NULL;
+ALTER TABLE employees ADD COLUMN manager_id INT UNSIGNED NULL;
+ALTER TABLE employees ADD CONSTRAINT employees_department_id_foreign
+    FOREIGN KEY (department_id) REFERENCES departments (id);
+ALTER TABLE employees ADD CONSTRAINT employees_position_id_foreign
+    FOREIGN KEY (position_id) REFERENCES positions (id);
+ALTER TABLE employees ADD CONSTRAINT employees_manager_id_foreign
+    FOREIGN KEY (manager_id) REFERENCES employees (id) ON DELETE SET NULL;
+
+INSERT INTO permissions (name, description) VALUES
+    ('organization.manage', 'Manage departments and job positions');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles CROSS JOIN permissions
+WHERE roles.name = 'ADMINISTRATOR' AND permissions.name = 'organization.manage';
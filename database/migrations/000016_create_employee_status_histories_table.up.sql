@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS employee_status_histories (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    employee_id INT UNSIGNED NOT NULL,
+    from_status VARCHAR(20) NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason VARCHAR(500) NULL,
+    effective_date DATE NOT NULL,
+    created_by INT UNSIGNED NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    KEY employee_status_histories_employee_id_index (employee_id),
+    CONSTRAINT employee_status_histories_employee_id_foreign
+        FOREIGN KEY (employee_id) REFERENCES employees (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Current status of existing employees is the first entry of their history
+INSERT INTO employee_status_histories (employee_id, to_status, effective_date)
+SELECT id, status, COALESCE(hired_date, DATE(created_at)) FROM employees WHERE status IS NOT NULL;
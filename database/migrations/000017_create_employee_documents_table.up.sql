@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS employee_documents (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    employee_id INT UNSIGNED NOT NULL,
+    document_type VARCHAR(20) NOT NULL,
+    description VARCHAR(500) NULL,
+    file_path VARCHAR(255) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    file_size INT UNSIGNED NOT NULL,
+    expired_at DATE NULL,
+    created_by INT UNSIGNED NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    KEY employee_documents_employee_id_index (employee_id),
+    KEY employee_documents_expired_at_index (expired_at),
+    CONSTRAINT employee_documents_employee_id_foreign
+        FOREIGN KEY (employee_id) REFERENCES employees (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
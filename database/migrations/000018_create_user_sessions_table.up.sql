@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id CHAR(64) NOT NULL,
+    user_id INT UNSIGNED NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(500) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    last_seen_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    KEY user_sessions_user_id_index (user_id),
+    KEY user_sessions_expires_at_index (expires_at),
+    CONSTRAINT user_sessions_user_id_foreign
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+DELETE FROM failed_logins WHERE factor <> 'PASSWORD';
+ALTER TABLE failed_logins DROP COLUMN factor;
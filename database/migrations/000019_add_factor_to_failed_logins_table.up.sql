@@ -0,0 +1 @@
+ALTER TABLE failed_logins ADD COLUMN factor VARCHAR(20) NOT NULL DEFAULT 'PASSWORD' AFTER ip_address;
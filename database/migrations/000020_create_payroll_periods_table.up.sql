@@ -0,0 +1,5 @@
+-- Lock row of the month, runs of the same month are created one at a time
+CREATE TABLE IF NOT EXISTS payroll_periods (
+    period DATE NOT NULL,
+    PRIMARY KEY (period)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+ALTER TABLE failed_logins DROP INDEX failed_logins_attempted_at_index;
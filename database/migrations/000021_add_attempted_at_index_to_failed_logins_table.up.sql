@@ -0,0 +1 @@
+ALTER TABLE failed_logins ADD INDEX failed_logins_attempted_at_index (attempted_at);
@@ -0,0 +1,298 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/tozd/go/errors"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MigrationDir is where `migrate create` writes new files, relative to project root
+const MigrationDir = "database/migrations"
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration Migration
+	Applied   bool
+	AppliedAt sql.NullTime
+}
+
+type Migrator struct {
+	db    *sql.DB
+	files fs.FS
+}
+
+func NewMigrator(db *sql.DB) *Migrator {
+	return &Migrator{db: db, files: migrationFiles}
+}
+
+// Migrations returns all embedded migrations ordered by version
+func (m *Migrator) Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(m.files, "migrations")
+	if err != nil {
+		return nil, errors.Errorf("failed to read migrations: %w", err)
+	}
+
+	migrations := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(matches[1], 10, 64)
+		content, err := fs.ReadFile(m.files, "migrations/"+entry.Name())
+		if err != nil {
+			return nil, errors.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := migrations[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			migrations[version] = migration
+		}
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		result = append(result, *migration)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (version)
+		)
+	`
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return errors.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, errors.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, errors.Errorf("failed to get schema_migrations rows: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Errorf("failed to get schema_migrations rows: %w", err)
+	}
+	return applied, nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			Applied:   ok,
+			AppliedAt: sql.NullTime{Time: appliedAt, Valid: ok},
+		})
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies all pending migrations in version order, stops at the first failure
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated []Migration
+	for _, migration := range pending {
+		err := m.run(ctx, migration.Up, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(
+				ctx,
+				`INSERT INTO schema_migrations(version, name) VALUES(?, ?)`,
+				migration.Version,
+				migration.Name,
+			)
+			return err
+		})
+		if err != nil {
+			return migrated, errors.Errorf("failed to migrate %06d_%s: %w", migration.Version, migration.Name, err)
+		}
+		migrated = append(migrated, migration)
+	}
+	return migrated, nil
+}
+
+// Down rolls back the given number of latest applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rolledBack []Migration
+	for i := len(statuses) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		if !statuses[i].Applied {
+			continue
+		}
+		migration := statuses[i].Migration
+		err := m.run(ctx, migration.Down, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, migration.Version)
+			return err
+		})
+		if err != nil {
+			return rolledBack, errors.Errorf("failed to rollback %06d_%s: %w", migration.Version, migration.Name, err)
+		}
+		rolledBack = append(rolledBack, migration)
+	}
+	return rolledBack, nil
+}
+
+// run executes migration statements within a transaction,
+// note that MySQL implicitly commits DDL statements so only DML is rolled back on failure
+func (m *Migrator) run(ctx context.Context, script string, record func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// splitStatements splits script by semicolons placed at the end of a line
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// Create writes a new pair of empty up/down migration files into MigrationDir
+func (m *Migrator) Create(name string) ([]string, error) {
+	name = strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("migration name is required")
+	}
+
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	var version int64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+	// Also consider files created after the binary was built
+	entries, _ := os.ReadDir(MigrationDir)
+	for _, entry := range entries {
+		if matches := migrationFilePattern.FindStringSubmatch(entry.Name()); matches != nil {
+			existing, _ := strconv.ParseInt(matches[1], 10, 64)
+			version = max(version, existing+1)
+		}
+	}
+
+	if err := os.MkdirAll(MigrationDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(MigrationDir, fmt.Sprintf("%06d_%s.%s.sql", version, name, direction))
+		content := fmt.Sprintf("-- %s migration of %s\n", direction, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return files, err
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
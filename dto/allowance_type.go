@@ -0,0 +1,20 @@
+package dto
+
+import "github.com/anggadarkprince/crud-employee-go/models"
+
+type CreateAllowanceTypeRequest struct {
+    Code string `form:"code" validate:"required,max=50,allowance_code"`
+    Name string `form:"name" validate:"required,max=100"`
+    DefaultAmount models.Money `form:"default_amount" validate:"gte=0"`
+    IsTaxable bool `form:"is_taxable"`
+    IsActive bool `form:"is_active"`
+}
+
+type UpdateAllowanceTypeRequest struct {
+    Id int `validate:"required,number,numeric,gt=0"`
+    Code string `form:"code" validate:"required,max=50,allowance_code"`
+    Name string `form:"name" validate:"required,max=100"`
+    DefaultAmount models.Money `form:"default_amount" validate:"gte=0"`
+    IsTaxable bool `form:"is_taxable"`
+    IsActive bool `form:"is_active"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+type AuditLogFilter struct {
+    Page int
+    PerPage int
+    UserId int
+    EntityType string
+    EntityId int
+    DateFrom string
+    DateTo string
+}
+
+func (filter *AuditLogFilter) Offset() int {
+    return (filter.Page - 1) * filter.PerPage
+}
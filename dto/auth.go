@@ -7,4 +7,14 @@ type RegisterUserRequest struct {
     Password string `form:"password" validate:"required,min=3,max=20"`
     PasswordConfirmation string `form:"password_confirmation" validate:"required,eqfield=Password"`
     Agreement string `form:"agreement" validate:"required,oneof=0 1 yes no"`
+}
+
+type ForgotPasswordRequest struct {
+    Email string `form:"email" validate:"required,email,max=100"`
+}
+
+type ResetPasswordRequest struct {
+    Token string `form:"token" validate:"required"`
+    Password string `form:"password" validate:"required,min=3,max=20"`
+    PasswordConfirmation string `form:"password_confirmation" validate:"required,eqfield=Password"`
 }
\ No newline at end of file
@@ -0,0 +1,123 @@
+package dto
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+
+	"github.com/anggadarkprince/crud-employee-go/models"
+)
+
+// DashboardCounterResource is a dashboard number, Url opens the employee list filtered by it
+type DashboardCounterResource struct {
+    Key string `json:"key"`
+    Label string `json:"label"`
+    Total int `json:"total"`
+    Url string `json:"url"`
+}
+
+type DashboardTrendResource struct {
+    Month string `json:"month"`
+    Label string `json:"label"`
+    Hires int `json:"hires"`
+    Exits int `json:"exits"`
+    Headcount int `json:"headcount"`
+    Url string `json:"url"`
+}
+
+type DashboardAnniversaryResource struct {
+    EmployeeId int `json:"employee_id"`
+    Name string `json:"name"`
+    HiredDate string `json:"hired_date"`
+    Date string `json:"date"`
+    Years int `json:"years"`
+    DaysLeft int `json:"days_left"`
+    Url string `json:"url"`
+}
+
+type DashboardAnalyticsResource struct {
+    Months int `json:"months"`
+    From string `json:"from"`
+    To string `json:"to"`
+    Counters []DashboardCounterResource `json:"counters"`
+    Trends []DashboardTrendResource `json:"trends"`
+    Genders []DashboardCounterResource `json:"genders"`
+    AllowanceTypes []DashboardCounterResource `json:"allowance_types"`
+    AverageTenureYears float64 `json:"average_tenure_years"`
+    Anniversaries []DashboardAnniversaryResource `json:"anniversaries"`
+}
+
+// employeeListUrl returns url of the employee list filtered by the param, empty value lists all employees
+func employeeListUrl(param string, value string) string {
+    if value == "" {
+        return "/employees"
+    }
+    return "/employees?" + url.Values{param: {value}}.Encode()
+}
+
+func newDistributionResources(distributions []models.DashboardDistribution, param string) []DashboardCounterResource {
+    resources := []DashboardCounterResource{}
+    for _, distribution := range distributions {
+        resources = append(resources, DashboardCounterResource{
+            Key: distribution.Key,
+            Label: distribution.Label,
+            Total: distribution.Total,
+            Url: employeeListUrl(param, distribution.Key),
+        })
+    }
+    return resources
+}
+
+func NewDashboardAnalyticsResource(statistics *models.DashboardStatistics, analytics *models.DashboardAnalytics) DashboardAnalyticsResource {
+    counters := []DashboardCounterResource{
+        {Key: "total", Label: "Total Employees", Total: statistics.Total, Url: employeeListUrl("status", "")},
+    }
+    for _, status := range statistics.Statuses {
+        counters = append(counters, DashboardCounterResource{
+            Key: status.Status.Code,
+            Label: fmt.Sprintf("%s Employees", status.Status.Label),
+            Total: status.Total,
+            Url: employeeListUrl("status", status.Status.Code),
+        })
+    }
+
+    trends := []DashboardTrendResource{}
+    for _, trend := range analytics.Trends {
+        trends = append(trends, DashboardTrendResource{
+            Month: trend.Month.Format("2006-01"),
+            Label: trend.Month.Format("Jan 2006"),
+            Hires: trend.Hires,
+            Exits: trend.Exits,
+            Headcount: trend.Headcount,
+            Url: "/employees?" + url.Values{
+                "hired_from": {trend.Month.Format("2006-01-02")},
+                "hired_to": {trend.Month.AddDate(0, 1, -1).Format("2006-01-02")},
+            }.Encode(),
+        })
+    }
+
+    anniversaries := []DashboardAnniversaryResource{}
+    for _, anniversary := range analytics.Anniversaries {
+        anniversaries = append(anniversaries, DashboardAnniversaryResource{
+            EmployeeId: anniversary.EmployeeId,
+            Name: anniversary.Name,
+            HiredDate: anniversary.HiredDate.Format("2006-01-02"),
+            Date: anniversary.Date.Format("2006-01-02"),
+            Years: anniversary.Years,
+            DaysLeft: anniversary.DaysLeft,
+            Url: fmt.Sprintf("/employees/%d", anniversary.EmployeeId),
+        })
+    }
+
+    return DashboardAnalyticsResource{
+        Months: analytics.Months,
+        From: analytics.From.Format("2006-01-02"),
+        To: analytics.To.Format("2006-01-02"),
+        Counters: counters,
+        Trends: trends,
+        Genders: newDistributionResources(analytics.Genders, "gender"),
+        AllowanceTypes: newDistributionResources(analytics.AllowanceTypes, "allowance"),
+        AverageTenureYears: math.Round(analytics.AverageTenureYears * 100) / 100,
+        Anniversaries: anniversaries,
+    }
+}
@@ -1,24 +1,47 @@
 package dto
 
+import "github.com/anggadarkprince/crud-employee-go/models"
+
 type CreateEmployeeRequest struct {
-    Name string `form:"name" validate:"required"`
-    Email string `form:"email" validate:"required,email"`
-    TaxNumber string `form:"tax_number" validate:"required"`
-    Gender string `form:"gender" validate:"required,gender"`
-    HiredDate string `form:"hired_date" validate:"required"`
-    Address string `form:"address" validate:"required"`
-    Status string `form:"status" validate:"required"`
-    Allowances []string `form:"allowances" validate:"required"`
+    Name string `form:"name" json:"name" validate:"required"`
+    Email string `form:"email" json:"email" validate:"required,email"`
+    TaxNumber string `form:"tax_number" json:"tax_number" validate:"required"`
+    Gender string `form:"gender" json:"gender" validate:"required,gender"`
+    HiredDate string `form:"hired_date" json:"hired_date" validate:"required,datetime=2006-01-02"`
+    Address string `form:"address" json:"address" validate:"required"`
+    Status string `form:"status" json:"status" validate:"required,employee_status"`
+    BaseSalary models.Money `form:"base_salary" json:"base_salary" validate:"gte=0"`
+    DepartmentId int `form:"department_id" json:"department_id,omitempty" validate:"omitempty,gt=0"`
+    PositionId int `form:"position_id" json:"position_id,omitempty" validate:"omitempty,gt=0"`
+    ManagerId int `form:"manager_id" json:"manager_id,omitempty" validate:"omitempty,gt=0"`
+    Allowances []string `form:"allowances" json:"allowances" validate:"required,dive,required"`
+    // AllowanceAmounts overrides default amount of the allowance types, keyed by allowance type code
+    AllowanceAmounts map[string]models.Money `form:"allowance_amounts" json:"allowance_amounts,omitempty" validate:"omitempty,dive,gte=0"`
 }
 
 type UpdateEmployeeRequest struct {
-    Id int `validate:"required,number,numeric,gt=0"`
-    Name string `form:"name" validate:"required"`
-    Email string `form:"email" validate:"required,email"`
-    TaxNumber string `form:"tax_number" validate:"required"`
-    Gender string `form:"gender" validate:"required,gender"`
-    HiredDate string `form:"hired_date" validate:"required"`
-    Address string `form:"address" validate:"required"`
-    Status string `form:"status" validate:"required"`
-    Allowances []string `form:"allowances" validate:"required"`
+    Id int `json:"-" validate:"required,number,numeric,gt=0"`
+    Name string `form:"name" json:"name" validate:"required"`
+    Email string `form:"email" json:"email" validate:"required,email"`
+    TaxNumber string `form:"tax_number" json:"tax_number" validate:"required"`
+    Gender string `form:"gender" json:"gender" validate:"required,gender"`
+    HiredDate string `form:"hired_date" json:"hired_date" validate:"required,datetime=2006-01-02"`
+    Address string `form:"address" json:"address" validate:"required"`
+    // Status is only accepted when it's unchanged, it's changed with ChangeEmployeeStatusRequest
+    Status string `form:"status" json:"status,omitempty" validate:"omitempty,employee_status"`
+    // BaseSalary keeps the stored salary when it's absent
+    BaseSalary *models.Money `form:"base_salary" json:"base_salary,omitempty" validate:"omitempty,gte=0"`
+    DepartmentId int `form:"department_id" json:"department_id,omitempty" validate:"omitempty,gt=0"`
+    PositionId int `form:"position_id" json:"position_id,omitempty" validate:"omitempty,gt=0"`
+    ManagerId int `form:"manager_id" json:"manager_id,omitempty" validate:"omitempty,gt=0"`
+    Allowances []string `form:"allowances" json:"allowances" validate:"required,dive,required"`
+    // AllowanceAmounts overrides default amount of the allowance types, keyed by allowance type code
+    AllowanceAmounts map[string]models.Money `form:"allowance_amounts" json:"allowance_amounts,omitempty" validate:"omitempty,dive,gte=0"`
+}
+
+type ChangeEmployeeStatusRequest struct {
+    Id int `json:"-" validate:"required,number,numeric,gt=0"`
+    Status string `form:"status" json:"status" validate:"required,employee_status"`
+    Reason string `form:"reason" json:"reason" validate:"required,max=500"`
+    EffectiveDate string `form:"effective_date" json:"effective_date" validate:"required,datetime=2006-01-02"`
 }
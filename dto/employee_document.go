@@ -0,0 +1,11 @@
+package dto
+
+import "mime/multipart"
+
+type StoreEmployeeDocumentsRequest struct {
+    EmployeeId int `validate:"required,number,numeric,gt=0"`
+    DocumentType string `form:"document_type" validate:"required,document_type"`
+    Description string `form:"description" validate:"max=500"`
+    ExpiredAt string `form:"expired_at" validate:"omitempty,datetime=2006-01-02"`
+    Files []*multipart.FileHeader `form:"files" validate:"min=1,max=10,document"`
+}
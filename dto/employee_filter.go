@@ -0,0 +1,23 @@
+package dto
+
+type EmployeeFilter struct {
+    Page int
+    PerPage int
+    Sort string
+    Order string
+    Status string
+    Gender string
+    HiredFrom string
+    HiredTo string
+    Allowance string
+    // Department includes employees of its sub departments
+    Department int
+    Position int
+    Manager int
+    // Trashed lists soft deleted employees instead of the active ones
+    Trashed bool
+}
+
+func (filter *EmployeeFilter) Offset() int {
+    return (filter.Page - 1) * filter.PerPage
+}
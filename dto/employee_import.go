@@ -0,0 +1,16 @@
+package dto
+
+// EmployeeImportRow is a row of the import file, Line is the row number in the file
+type EmployeeImportRow struct {
+    Line int `json:"line"`
+    Data CreateEmployeeRequest `json:"data"`
+    // Department, Position and Manager are written as in the file, Data keeps the resolved ids
+    Department string `json:"department,omitempty"`
+    Position string `json:"position,omitempty"`
+    Manager string `json:"manager,omitempty"`
+    Errors map[string]string `json:"-"`
+}
+
+func (row *EmployeeImportRow) IsValid() bool {
+    return len(row.Errors) == 0
+}
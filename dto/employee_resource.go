@@ -0,0 +1,105 @@
+package dto
+
+import (
+	"database/sql"
+
+	"github.com/anggadarkprince/crud-employee-go/models"
+)
+
+type EmployeeResource struct {
+    Id int `json:"id"`
+    Name string `json:"name"`
+    Email *string `json:"email"`
+    TaxNumber *string `json:"tax_number"`
+    Gender *string `json:"gender"`
+    HiredDate *string `json:"hired_date"`
+    Address *string `json:"address"`
+    Status *string `json:"status"`
+    BaseSalary models.Money `json:"base_salary"`
+    DepartmentId *int64 `json:"department_id"`
+    Department *string `json:"department"`
+    PositionId *int64 `json:"position_id"`
+    Position *string `json:"position"`
+    ManagerId *int64 `json:"manager_id"`
+    Manager *string `json:"manager"`
+    TotalAllowance int `json:"total_allowance"`
+}
+
+type EmployeeAllowanceResource struct {
+    Id int `json:"id"`
+    EmployeeId int `json:"employee_id"`
+    AllowanceTypeId int `json:"allowance_type_id"`
+    Code string `json:"code"`
+    Allowance string `json:"allowance"`
+    Amount models.Money `json:"amount"`
+    IsOverridden bool `json:"is_overridden"`
+    IsTaxable bool `json:"is_taxable"`
+}
+
+func nullString(value sql.NullString) *string {
+    if !value.Valid {
+        return nil
+    }
+    return &value.String
+}
+
+func nullInt(value sql.NullInt64) *int64 {
+    if !value.Valid {
+        return nil
+    }
+    return &value.Int64
+}
+
+func nullDate(value sql.NullTime) *string {
+    if !value.Valid {
+        return nil
+    }
+    date := value.Time.Format("2006-01-02")
+    return &date
+}
+
+func NewEmployeeResource(employee *models.Employee) EmployeeResource {
+    return EmployeeResource{
+        Id: employee.Id,
+        Name: employee.Name,
+        Email: nullString(employee.Email),
+        TaxNumber: nullString(employee.TaxNumber),
+        Gender: nullString(employee.Gender),
+        HiredDate: nullDate(employee.HiredDate),
+        Address: nullString(employee.Address),
+        Status: nullString(employee.Status),
+        BaseSalary: employee.BaseSalary,
+        DepartmentId: nullInt(employee.DepartmentId),
+        Department: nullString(employee.DepartmentName),
+        PositionId: nullInt(employee.PositionId),
+        Position: nullString(employee.PositionName),
+        ManagerId: nullInt(employee.ManagerId),
+        Manager: nullString(employee.ManagerName),
+        TotalAllowance: employee.TotalAllowance,
+    }
+}
+
+func NewEmployeeResources(employees []models.Employee) []EmployeeResource {
+    resources := make([]EmployeeResource, 0, len(employees))
+    for i := range employees {
+        resources = append(resources, NewEmployeeResource(&employees[i]))
+    }
+    return resources
+}
+
+func NewEmployeeAllowanceResources(employeeAllowances []models.EmployeeAllowance) []EmployeeAllowanceResource {
+    resources := make([]EmployeeAllowanceResource, 0, len(employeeAllowances))
+    for _, item := range employeeAllowances {
+        resources = append(resources, EmployeeAllowanceResource{
+            Id: item.Id,
+            EmployeeId: item.EmployeeId,
+            AllowanceTypeId: item.AllowanceTypeId,
+            Code: item.Code,
+            Allowance: item.Allowance,
+            Amount: item.EffectiveAmount(),
+            IsOverridden: item.Amount.Valid,
+            IsTaxable: item.IsTaxable,
+        })
+    }
+    return resources
+}
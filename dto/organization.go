@@ -0,0 +1,25 @@
+package dto
+
+type CreateDepartmentRequest struct {
+    ParentId int `form:"parent_id" validate:"omitempty,gt=0"`
+    Name string `form:"name" validate:"required,max=100"`
+    Description string `form:"description" validate:"max=255"`
+}
+
+type UpdateDepartmentRequest struct {
+    Id int `validate:"required,number,numeric,gt=0"`
+    ParentId int `form:"parent_id" validate:"omitempty,gt=0"`
+    Name string `form:"name" validate:"required,max=100"`
+    Description string `form:"description" validate:"max=255"`
+}
+
+type CreatePositionRequest struct {
+    Name string `form:"name" validate:"required,max=100"`
+    Description string `form:"description" validate:"max=255"`
+}
+
+type UpdatePositionRequest struct {
+    Id int `validate:"required,number,numeric,gt=0"`
+    Name string `form:"name" validate:"required,max=100"`
+    Description string `form:"description" validate:"max=255"`
+}
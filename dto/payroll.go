@@ -0,0 +1,22 @@
+package dto
+
+type PayrollRunFilter struct {
+    Page int
+    PerPage int
+    Status string
+    Year int
+}
+
+func (filter *PayrollRunFilter) Offset() int {
+    return (filter.Page - 1) * filter.PerPage
+}
+
+type CreatePayrollRunRequest struct {
+    // Period is the paid month in YYYY-MM format
+    Period string `form:"period" json:"period" validate:"required,datetime=2006-01"`
+}
+
+type CreatePayrollCorrectionRequest struct {
+    Id int `json:"-" validate:"required,gt=0"`
+    Notes string `form:"notes" json:"notes" validate:"required,max=500"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+type CreatePersonalAccessTokenRequest struct {
+    UserId int `validate:"required,gt=0"`
+    Name string `form:"name" validate:"required,max=100"`
+    Scopes []string `form:"scopes" validate:"required,min=1,dive,required"`
+    ExpiredAt string `form:"expired_at" validate:"omitempty,datetime=2006-01-02"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+type UpdateRolePermissionRequest struct {
+    Id int `validate:"required,number,numeric,gt=0"`
+    Permissions []string `form:"permissions" validate:"dive,required"`
+    RequireTwoFactor bool `form:"require_two_factor"`
+}
+
+type UpdateUserRoleRequest struct {
+    Id int `validate:"required,number,numeric,gt=0"`
+    UserType string `form:"user_type" validate:"required,max=20"`
+}
+
+type UpdateUserStatusRequest struct {
+    Id int `validate:"required,number,numeric,gt=0"`
+    ActorId int `validate:"required,gt=0"`
+    Status string `form:"status" validate:"required,oneof=ACTIVATED SUSPENDED"`
+}
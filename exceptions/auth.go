@@ -6,4 +6,7 @@ var (
     ErrUserNotFound = errors.New("user not found")
     ErrUserInactive = errors.New("user not activated")
     ErrWrongPassword = errors.New("wrong password")
+    ErrInvalidResetToken = errors.New("invalid or expired reset token")
+    ErrInvalidSignedToken = errors.New("invalid or expired signed token")
+    ErrInvalidTwoFactorCode = errors.New("invalid two factor code")
 )
\ No newline at end of file
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"strconv"
+	"time"
 
+	"github.com/anggadarkprince/crud-employee-go/commands"
 	"github.com/anggadarkprince/crud-employee-go/configs"
 	"github.com/anggadarkprince/crud-employee-go/database"
 	"github.com/anggadarkprince/crud-employee-go/middlewares"
 	"github.com/anggadarkprince/crud-employee-go/pkg/logger"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/storage"
 	"github.com/anggadarkprince/crud-employee-go/routes"
 	"github.com/anggadarkprince/crud-employee-go/utilities"
 	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
@@ -20,28 +29,106 @@ func main() {
     }
 
 	logger.Initialize()
-	
+
+	db := database.InitDatabase()
+
+	// Console commands, e.g. `go run . migrate up`
+	if len(os.Args) > 1 && os.Args[1] != "serve" {
+		var err error
+		switch os.Args[1] {
+		case "migrate":
+			err = commands.Migrate(db, os.Args[2:])
+		case "user":
+			err = commands.User(db, os.Args[2:])
+		case "employee":
+			err = commands.Employee(db, os.Args[2:])
+		default:
+			err = fmt.Errorf("unknown command %q, available commands: serve, migrate, user, employee", os.Args[1])
+		}
+		if err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	serve(db)
+}
+
+// fatal prints to stderr because the standard logger is redirected to the log file
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
+}
+
+func serve(db *sql.DB) {
+	if configs.Get().Database.MigrationCheck {
+		pending, err := database.NewMigrator(db).Pending(context.Background())
+		if err != nil {
+			fatal(fmt.Errorf("failed to check migrations: %w", err))
+		}
+		if len(pending) > 0 {
+			fatal(fmt.Errorf("there are %d pending migrations, run `migrate up` first or set DB_MIGRATION_CHECK=false", len(pending)))
+		}
+	}
+
+	fileStorage, err := storage.New(configs.Get().Storage)
+	if err != nil {
+		fatal(fmt.Errorf("failed to initialize storage: %w", err))
+	}
+	sessionStore, err := session.NewStore(configs.Get().Session, db)
+	if err != nil {
+		fatal(fmt.Errorf("failed to initialize session store: %w", err))
+	}
+
 	utilities.InitTemplates()
 
+	go purgePeriodically(db, fileStorage)
+
 	validation.Init()
 
-	db := database.InitDatabase()
-	
 	server := http.NewServeMux()
 
-	fs := http.FileServer(http.Dir("./uploads"))
-    server.Handle("/statics/", http.StripPrefix("/statics/", fs))
-	
+	// Only these assets are public, uploaded files are served by the authorized /files/ route
 	server.HandleFunc("GET /favicon.ico", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, "public/favicon.ico")
 	})
 	server.HandleFunc("GET /statics/img/no-avatar.png", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, "public/img/no-avatar.png")
 	})
-	routes.MapRoutes(server, db)
+	routes.MapRoutes(server, db, fileStorage, sessionStore)
 
 	port := configs.Get().App.Port
 	portStr := strconv.Itoa(int(port))
 
-	http.ListenAndServe(":" + portStr, middlewares.MethodOverride(server))
+	// CSRF check runs after MethodOverride rewrites the method
+	// Body limits apply before MethodOverride and CSRF parse the form
+	http.ListenAndServe(":" + portStr, middlewares.MaxBodySize(routes.BodyLimits, middlewares.MethodOverride(middlewares.CSRF(server))))
+}
+
+// purgePeriodically permanently deletes expired trash and failed logins while the server is running
+func purgePeriodically(db *sql.DB, fileStorage storage.Storage) {
+	trash := configs.Get().Trash
+	if trash.PurgeInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(trash.PurgeInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		if trash.RetentionDays > 0 {
+			purged, err := commands.PurgeTrash(context.Background(), db, fileStorage)
+			if err != nil {
+				slog.Error("Failed to purge trash", slog.Any("error", err))
+			} else if purged > 0 {
+				slog.Info("Trash is purged", slog.Int("employees", purged))
+			}
+		}
+
+		purged, err := commands.PurgeFailedLogins(context.Background(), db)
+		if err != nil {
+			slog.Error("Failed to purge failed logins", slog.Any("error", err))
+		} else if purged > 0 {
+			slog.Info("Failed logins are purged", slog.Int64("attempts", purged))
+		}
+		<-ticker.C
+	}
 }
@@ -2,11 +2,20 @@ package middlewares
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/anggadarkprince/crud-employee-go/configs"
 	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/audit"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/storage"
 	"github.com/anggadarkprince/crud-employee-go/repositories"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -15,11 +24,22 @@ import (
 type contextKey string
 
 const userContextKey contextKey = "user"
+const permissionsContextKey contextKey = "permissions"
+const accessTokenContextKey contextKey = "access_token"
+const signedContextKey contextKey = "signed"
 
 // Auth holds dependencies for middleware
 type Auth struct {
-	UserRepository *repositories.UserRepository
-	SecretKey      string
+	UserRepository       *repositories.UserRepository
+	PermissionRepository *repositories.PermissionRepository
+	PersonalAccessTokenRepository *repositories.PersonalAccessTokenRepository
+	RevokedTokenRepository *repositories.RevokedTokenRepository
+	RoleRepository       *repositories.RoleRepository
+	// SessionStore keeps cookie sessions server-side, nil when the cookie is a JWT
+	SessionStore         session.Store
+	SecretKey            string
+	// ForbiddenHandler renders the 403 page for non JSON requests
+	ForbiddenHandler     http.Handler
 }
 
 func (c *Auth) GetAuthToken(r *http.Request) string {
@@ -45,71 +65,283 @@ func (c *Auth) GetAuthToken(r *http.Request) string {
 	return tokenString
 }
 
-// AuthMiddleware protects routes - redirects to login if not authenticated
-func (c *Auth) AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get JWT token from cookie or Header
-		authToken := c.GetAuthToken(r)
+// authenticate resolves the user owning the auth token of the request,
+// access token is only returned when authenticated by personal access token
+func (c *Auth) authenticate(r *http.Request) (*models.User, *models.PersonalAccessToken, error) {
+	// Get JWT or personal access token from cookie or Header
+	authToken := c.GetAuthToken(r)
+	if authToken == "" {
+		return nil, nil, errors.New("missing auth token")
+	}
 
-		if authToken == "" {
-			http.Redirect(w, r, "/login", http.StatusSeeOther)
-			return
+	var user *models.User
+	var accessToken *models.PersonalAccessToken
+	var err error
+	if strings.HasPrefix(authToken, models.PersonalAccessTokenPrefix) {
+		user, accessToken, err = c.authenticatePersonalAccessToken(r, authToken)
+	} else if c.SessionStore != nil {
+		user, err = c.authenticateSession(r, authToken)
+	} else {
+		user, err = c.authenticateJWT(r, authToken)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Suspended user loses access immediately
+	if user.Status != "ACTIVATED" {
+		return nil, nil, errors.New("user is not activated")
+	}
+
+	return user, accessToken, nil
+}
+
+func (c *Auth) authenticatePersonalAccessToken(r *http.Request, plainToken string) (*models.User, *models.PersonalAccessToken, error) {
+	if c.PersonalAccessTokenRepository == nil {
+		return nil, nil, errors.New("personal access tokens are not supported")
+	}
+	accessToken, err := c.PersonalAccessTokenRepository.GetByToken(r.Context(), hashToken(plainToken))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if accessToken.IsExpired(now) {
+		return nil, nil, errors.New("personal access token is expired")
+	}
+
+	user, err := c.UserRepository.GetById(r.Context(), accessToken.UserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Record usage for auditing automation, failing to do so should not block the request
+	if err := c.PersonalAccessTokenRepository.Touch(r.Context(), accessToken.Id, now, clientIP(r)); err != nil {
+		slog.Warn("Failed to record personal access token usage", slog.Int("id", accessToken.Id), slog.Any("error", err))
+	}
+
+	return user, accessToken, nil
+}
+
+// sessionTouchInterval limits how often last seen time of the session is written
+const sessionTouchInterval = time.Minute
+
+func (c *Auth) authenticateSession(r *http.Request, token string) (*models.User, error) {
+	userSession, err := c.SessionStore.Get(r.Context(), session.SessionId(token))
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := c.UserRepository.GetById(r.Context(), userSession.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions created before the sessions are revoked (e.g. password change) are no longer valid
+	if user.SessionsRevokedAt.Valid && userSession.CreatedAt.Before(user.SessionsRevokedAt.Time.Truncate(time.Second)) {
+		if err := c.SessionStore.Delete(r.Context(), userSession.Id); err != nil {
+			slog.Warn("Failed to delete revoked session", slog.Int("user_id", user.Id), slog.Any("error", err))
 		}
+		return nil, errors.New("session is revoked")
+	}
 
-		// Validate JWT token
-		token, err := jwt.Parse(authToken, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(c.SecretKey), nil
+	// Last seen time is shown in the device list, failing to record it should not block the request
+	now := time.Now()
+	if now.Sub(userSession.LastSeenAt) >= sessionTouchInterval || userSession.IpAddress != clientIP(r) {
+		if err := c.SessionStore.Touch(r.Context(), userSession.Id, now, clientIP(r)); err != nil {
+			slog.Warn("Failed to record session activity", slog.Int("user_id", user.Id), slog.Any("error", err))
+		}
+	}
+
+	return user, nil
+}
+
+func (c *Auth) authenticateJWT(r *http.Request, authToken string) (*models.User, error) {
+	// Validate JWT token
+	token, err := jwt.Parse(authToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(c.SecretKey), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid auth token")
+	}
+
+	// Extract claims
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+
+	// Token logged out explicitly
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil, errors.New("missing token id")
+	}
+	revoked, err := c.RevokedTokenRepository.IsRevoked(r.Context(), jti)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errors.New("auth token is revoked")
+	}
+
+	// Get user ID from "sub" claim
+	var userID int
+	switch v := claims["sub"].(type) {
+	case float64:
+		userID = int(v)
+	case int:
+		userID = v
+	case int64:
+		userID = int(v)
+	case string:
+		// Sometimes sub is stored as string
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		userID = parsed
+	default:
+		return nil, errors.New("invalid token subject")
+	}
+
+	// Query user from database, user not found or database error
+	user, err := c.UserRepository.GetById(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tokens issued before the sessions are revoked (e.g. password change) are no longer valid
+	if user.SessionsRevokedAt.Valid {
+		issuedAt, err := claims.GetIssuedAt()
+		if err != nil || issuedAt == nil || issuedAt.Before(user.SessionsRevokedAt.Time.Truncate(time.Second)) {
+			return nil, errors.New("auth token is revoked")
+		}
+	}
+
+	return user, nil
+}
+
+// withUser stores authenticated user and permissions of its role (user type) in context,
+// permissions are narrowed down to the scopes when authenticated by personal access token
+func (c *Auth) withUser(r *http.Request, user *models.User, accessToken *models.PersonalAccessToken) (context.Context, error) {
+	ctx := r.Context()
+	permissions, err := c.PermissionRepository.GetNamesByRole(ctx, user.UserType)
+	if err != nil {
+		return ctx, err
+	}
+	if accessToken != nil {
+		permissions = slices.DeleteFunc(permissions, func(permission string) bool {
+			return !accessToken.HasScope(permission)
 		})
-		if err != nil || !token.Valid {
-			http.Redirect(w, r, "/login", http.StatusSeeOther)
-			return
+		ctx = context.WithValue(ctx, accessTokenContextKey, accessToken)
+	}
+	ctx = context.WithValue(ctx, userContextKey, user)
+	ctx = context.WithValue(ctx, permissionsContextKey, permissions)
+	// Services record the actor of data changes in audit logs
+	ctx = audit.WithActor(ctx, audit.Actor{
+		UserId: user.Id,
+		IpAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	return ctx, nil
+}
+
+// missingTwoFactor tells whether role (user type) of the user requires two factor authentication
+// but the user has not enabled it yet
+func (c *Auth) missingTwoFactor(ctx context.Context, user *models.User) (bool, error) {
+	if c.RoleRepository == nil || user.HasTwoFactor() {
+		return false, nil
+	}
+	role, err := c.RoleRepository.GetByName(ctx, user.UserType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
 		}
+		return false, err
+	}
+	return role.RequireTwoFactor, nil
+}
 
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
+// AuthMiddleware protects routes - redirects to login if not authenticated
+func (c *Auth) AuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, accessToken, err := c.authenticate(r)
+		if err != nil {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
 
-		// Get user ID from "sub" claim
-		var userID int
-		switch v := claims["sub"].(type) {
-		case float64:
-			userID = int(v)
-		case int:
-			userID = v
-		case int64:
-			userID = int(v)
-		case string:
-			// Sometimes sub is stored as string
-			parsed, err := strconv.Atoi(v)
+		// Only the account page (to enable it) and logout are allowed until two factor is enabled
+		if r.URL.Path != "/account" && !strings.HasPrefix(r.URL.Path, "/account/") && r.URL.Path != "/logout" {
+			missing, err := c.missingTwoFactor(r.Context(), user)
 			if err != nil {
-				http.Redirect(w, r, "/login", http.StatusSeeOther)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
-			userID = parsed
-		default:
-			// fmt.Printf("Unexpected sub type: %T, value: %v\n", claims["sub"], claims["sub"])
-			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			if missing {
+				session.Flash(w, "warning", "Two-factor authentication is required for your account, please enable it first")
+				http.Redirect(w, r, "/account#two-factor", http.StatusSeeOther)
+				return
+			}
+		}
+
+		// Store user and its permissions in context for later retrieval
+		ctx, err := c.withUser(r, user, accessToken)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		// Query user from database
-		user, err := c.UserRepository.GetById(r.Context(), userID)
+		// Pass the new context to the next handler
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// SignedOrAuthMiddleware lets request of a valid signed file url through without authentication
+// (e.g. file linked from emails), other requests must be authenticated
+func (c *Auth) SignedOrAuthMiddleware(next http.Handler) http.Handler {
+	authenticated := c.AuthMiddleware(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if storage.VerifySignedUrl(r.URL) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), signedContextKey, true)))
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
+// IsSigned tells whether the request is authorized by signed url instead of authenticated user
+func IsSigned(r *http.Request) bool {
+	signed, _ := r.Context().Value(signedContextKey).(bool)
+	return signed
+}
+
+// ApiMiddleware protects API routes - responds 401 JSON if not authenticated
+func (c *Auth) ApiMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, accessToken, err := c.authenticate(r)
 		if err != nil {
-			// User not found or database error
-			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			writeJSONError(w, http.StatusUnauthorized, "Unauthenticated")
 			return
 		}
 
-		// Store user in context for later retrieval
-		ctx := context.WithValue(r.Context(), userContextKey, user)
+		missing, err := c.missingTwoFactor(r.Context(), user)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "Failed to load role")
+			return
+		}
+		if missing {
+			writeJSONError(w, http.StatusForbidden, "Two-factor authentication is required, enable it from the account page")
+			return
+		}
 
-		// Pass the new context to the next handler
+		ctx, err := c.withUser(r, user, accessToken)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "Failed to load permissions")
+			return
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -124,15 +356,8 @@ func (c *Auth) GuestMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		token, err := jwt.Parse(authToken, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(c.SecretKey), nil
-		})
-
-		if err != nil || !token.Valid {
-			// Invalid token, user is guest
+		// Invalid or revoked token, user is guest
+		if _, _, err := c.authenticate(r); err != nil {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -150,3 +375,57 @@ func GetUser(r *http.Request) *models.User {
 	}
 	return user
 }
+
+// GetAccessToken returns personal access token of the request, nil when authenticated by session
+func GetAccessToken(r *http.Request) *models.PersonalAccessToken {
+	accessToken, ok := r.Context().Value(accessTokenContextKey).(*models.PersonalAccessToken)
+	if !ok {
+		return nil
+	}
+	return accessToken
+}
+
+// GetPermissions returns permission names of the authenticated user
+func GetPermissions(r *http.Request) []string {
+	permissions, ok := r.Context().Value(permissionsContextKey).([]string)
+	if !ok {
+		return []string{}
+	}
+	return permissions
+}
+
+// Can checks whether the authenticated user has the permission
+func Can(r *http.Request, permission string) bool {
+	return slices.Contains(GetPermissions(r), permission)
+}
+
+// PermissionMiddleware must be placed after AuthMiddleware or ApiMiddleware
+func (c *Auth) PermissionMiddleware(permission string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Can(r, permission) {
+			c.forbidden(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SessionMiddleware must be placed after AuthMiddleware, it rejects requests authenticated by personal
+// access token so a token cannot manage the account (e.g. mint tokens or disable two factor)
+func (c *Auth) SessionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetAccessToken(r) != nil {
+			c.forbidden(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c *Auth) forbidden(w http.ResponseWriter, r *http.Request) {
+	if WantsJSON(r) || c.ForbiddenHandler == nil {
+		writeJSONError(w, http.StatusForbidden, "This action is unauthorized")
+		return
+	}
+	c.ForbiddenHandler.ServeHTTP(w, r)
+}
@@ -0,0 +1,167 @@
+package middlewares
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+)
+
+// userConnector is a database/sql driver answering the user query of UserRepository.GetById
+// with a single activated user, sessionsRevokedAt is nil when sessions are never revoked
+type userConnector struct {
+	sessionsRevokedAt any
+}
+
+func (c userConnector) Connect(context.Context) (driver.Conn, error) { return userConn(c), nil }
+func (c userConnector) Driver() driver.Driver                        { return nil }
+
+type userConn userConnector
+
+func (c userConn) Prepare(query string) (driver.Stmt, error) { return userStmt(c), nil }
+func (c userConn) Close() error                              { return nil }
+func (c userConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type userStmt userConn
+
+func (s userStmt) Close() error  { return nil }
+func (s userStmt) NumInput() int { return -1 }
+func (s userStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s userStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &userRows{values: []driver.Value{
+		args[0], "Admin", "admin", "admin@example.com", "", "ADMINISTRATOR", "ACTIVATED", nil,
+		s.sessionsRevokedAt, nil, nil, nil,
+	}}, nil
+}
+
+type userRows struct {
+	values []driver.Value
+	done   bool
+}
+
+func (r *userRows) Columns() []string {
+	return []string{
+		"id", "name", "username", "email", "password", "user_type", "status", "avatar",
+		"sessions_revoked_at", "two_factor_secret", "two_factor_confirmed_at", "two_factor_last_step",
+	}
+}
+func (r *userRows) Close() error { return nil }
+func (r *userRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.values)
+	return nil
+}
+
+func newSessionAuth(t *testing.T, sessionsRevokedAt any) (*Auth, *session.MemoryStore) {
+	db := sql.OpenDB(userConnector{sessionsRevokedAt: sessionsRevokedAt})
+	t.Cleanup(func() { db.Close() })
+	store := session.NewMemoryStore()
+	return &Auth{UserRepository: repositories.NewUserRepository(db), SessionStore: store}, store
+}
+
+// createSession stores a session of user 1 and returns its cookie token
+func createSession(t *testing.T, store session.Store, createdAt time.Time, lastSeenAt time.Time) string {
+	token, err := session.NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Create(context.Background(), &session.Session{
+		Id:         session.SessionId(token),
+		UserId:     1,
+		IpAddress:  "10.0.0.1",
+		CreatedAt:  createdAt,
+		LastSeenAt: lastSeenAt,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthenticateSessionRevocation(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tests := []struct {
+		name              string
+		createdAt         time.Time
+		sessionsRevokedAt any
+		wantValid         bool
+	}{
+		{name: "never revoked", createdAt: now, sessionsRevokedAt: nil, wantValid: true},
+		{name: "created before revocation", createdAt: now.Add(-time.Minute), sessionsRevokedAt: now, wantValid: false},
+		{name: "created after revocation", createdAt: now, sessionsRevokedAt: now.Add(-time.Minute), wantValid: true},
+		// Session reissued after password change is created in the second the sessions are revoked
+		{name: "reissued in the same second", createdAt: now, sessionsRevokedAt: now.Add(700 * time.Millisecond), wantValid: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auth, store := newSessionAuth(t, test.sessionsRevokedAt)
+			token := createSession(t, store, test.createdAt, now)
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = "10.0.0.1:51000"
+
+			user, err := auth.authenticateSession(r, token)
+			if test.wantValid {
+				if err != nil || user.Id != 1 {
+					t.Fatalf("got user %v, error %v, want user 1", user, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("revoked session is authenticated")
+			}
+			if _, err := store.Get(context.Background(), session.SessionId(token)); !errors.Is(err, session.ErrSessionNotFound) {
+				t.Errorf("revoked session is not deleted: %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthenticateSessionTouch(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tests := []struct {
+		name        string
+		lastSeenAt  time.Time
+		remoteAddr  string
+		wantTouched bool
+	}{
+		{name: "seen recently", lastSeenAt: now.Add(-sessionTouchInterval / 2), remoteAddr: "10.0.0.1:51000", wantTouched: false},
+		{name: "seen before the interval", lastSeenAt: now.Add(-2 * sessionTouchInterval), remoteAddr: "10.0.0.1:51000", wantTouched: true},
+		{name: "seen recently from another address", lastSeenAt: now.Add(-sessionTouchInterval / 2), remoteAddr: "10.0.0.2:51000", wantTouched: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auth, store := newSessionAuth(t, nil)
+			token := createSession(t, store, now.Add(-time.Hour), test.lastSeenAt)
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = test.remoteAddr
+
+			if _, err := auth.authenticateSession(r, token); err != nil {
+				t.Fatal(err)
+			}
+			userSession, err := store.Get(context.Background(), session.SessionId(token))
+			if err != nil {
+				t.Fatal(err)
+			}
+			touched := !userSession.LastSeenAt.Equal(test.lastSeenAt)
+			if touched != test.wantTouched {
+				t.Errorf("touched = %v, want %v", touched, test.wantTouched)
+			}
+			if touched && userSession.IpAddress != clientIP(r) {
+				t.Errorf("ip address = %s, want %s", userSession.IpAddress, clientIP(r))
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+)
+
+// bodyLimit is registered in the lookup mux of MaxBodySize, it is never served
+type bodyLimit int64
+
+func (limit bodyLimit) ServeHTTP(w http.ResponseWriter, r *http.Request) {}
+
+// MaxBodySize caps request body of the routes (pattern as registered, e.g. "POST /employees/import"),
+// must wrap MethodOverride and CSRF because they parse the form before the route is served and
+// multipart files above the memory limit would be written to temp files otherwise
+func MaxBodySize(limits map[string]int64, next http.Handler) http.Handler {
+	lookup := http.NewServeMux()
+	for pattern, limit := range limits {
+		lookup.Handle(pattern, bodyLimit(limit))
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, pattern := lookup.Handler(r)
+		limit, ok := handler.(bodyLimit)
+		if pattern == "" || !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// Browsers send the length of forms, the rest are cut off while reading
+		if r.ContentLength > int64(limit) {
+			bodyTooLarge(w, r)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, int64(limit))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func bodyTooLarge(w http.ResponseWriter, r *http.Request) {
+	message := "The uploaded data is too large"
+	if WantsJSON(r) {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, message)
+		return
+	}
+	referer := r.Header.Get("Referer")
+	if referer == "" {
+		referer = "/"
+	}
+	session.Flash(w, "danger", message)
+	http.Redirect(w, r, referer, http.StatusSeeOther)
+}
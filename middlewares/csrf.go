@@ -0,0 +1,106 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+)
+
+const csrfContextKey contextKey = "csrf_token"
+
+// CSRFFieldName is the form field holding the token, X-CSRF-Token header is accepted as well
+const CSRFFieldName = "_token"
+
+func generateCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CSRF issues a per-session token cookie and verifies it on state-changing requests,
+// must be wrapped by MethodOverride so spoofed methods are checked too
+func CSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionConfig := configs.Get().Session
+
+		var token string
+		if cookie, err := r.Cookie(sessionConfig.CsrfCookieName); err == nil && cookie.Value != "" {
+			token = cookie.Value
+		} else {
+			token, err = generateCSRFToken()
+			if err != nil {
+				http.Error(w, "Failed to generate CSRF token", http.StatusInternalServerError)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{
+				Name: sessionConfig.CsrfCookieName,
+				Value: token,
+				Path: sessionConfig.Path,
+				HttpOnly: true,
+				Secure: sessionConfig.Secure,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		default:
+			// API requests authenticated by bearer header are not sent automatically by browsers,
+			// the auth cookie takes precedence so the header is only trusted when there is no cookie
+			if !isBearerAuthenticated(r) && !validCSRFToken(r, token) {
+				csrfFailed(w, r)
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), csrfContextKey, token)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func isBearerAuthenticated(r *http.Request) bool {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		return false
+	}
+	_, err := r.Cookie(configs.Get().Session.CookieName)
+	return err != nil
+}
+
+func validCSRFToken(r *http.Request, token string) bool {
+	submitted := r.Header.Get("X-CSRF-Token")
+	if submitted == "" {
+		submitted = r.PostFormValue(CSRFFieldName)
+	}
+	if submitted == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) == 1
+}
+
+func csrfFailed(w http.ResponseWriter, r *http.Request) {
+	if WantsJSON(r) {
+		writeJSONError(w, http.StatusForbidden, "CSRF token mismatch")
+		return
+	}
+	// Browsers may omit the referer for privacy, the form is then left from the home page
+	referer := r.Header.Get("Referer")
+	if referer == "" {
+		referer = "/"
+	}
+	session.Flash(w, "danger", "Your session has expired, please try again.")
+	http.Redirect(w, r, referer, http.StatusSeeOther)
+}
+
+// GetCSRFToken returns token of the current request to be embedded in forms
+func GetCSRFToken(r *http.Request) string {
+	token, _ := r.Context().Value(csrfContextKey).(string)
+	return token
+}
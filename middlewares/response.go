@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// WantsJSON tells whether the request expects a JSON response (API routes or JSON clients)
+func WantsJSON(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// writeJSONError writes an error payload, middlewares cannot use utilities.JSON (import cycle)
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"message": message,
+	})
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type AllowanceType struct {
+	Id int
+	Code string
+	Name string
+	DefaultAmount Money
+	IsTaxable bool
+	IsActive bool
+	// TotalEmployee is number of employees receiving the allowance, only filled in list
+	TotalEmployee int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	AuditActionCreated = "created"
+	AuditActionUpdated = "updated"
+	AuditActionDeleted = "deleted"
+	AuditActionRestored = "restored"
+	AuditActionPurged = "purged"
+)
+
+const (
+	AuditEntityEmployee = "employee"
+	AuditEntityUser = "user"
+	AuditEntityPayrollRun = "payroll_run"
+)
+
+// AuditEntities lists entity types that are audited, used as filter options
+var AuditEntities = []string{AuditEntityEmployee, AuditEntityUser, AuditEntityPayrollRun}
+
+type AuditLog struct {
+	Id int
+	UserId sql.NullInt64
+	UserName sql.NullString
+	Action string
+	EntityType string
+	EntityId int
+	OldValues sql.NullString
+	NewValues sql.NullString
+	IpAddress sql.NullString
+	UserAgent sql.NullString
+	CreatedAt time.Time
+}
+
+// AuditChange is a single field change to display
+type AuditChange struct {
+	Field string
+	Old string
+	New string
+}
+
+// Changes decodes old and new values into field changes sorted by field name
+func (log *AuditLog) Changes() []AuditChange {
+	oldValues := decodeAuditValues(log.OldValues)
+	newValues := decodeAuditValues(log.NewValues)
+
+	fields := []string{}
+	for field := range oldValues {
+		fields = append(fields, field)
+	}
+	for field := range newValues {
+		if _, ok := oldValues[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+
+	changes := make([]AuditChange, 0, len(fields))
+	for _, field := range fields {
+		changes = append(changes, AuditChange{
+			Field: field,
+			Old: formatAuditValue(oldValues[field]),
+			New: formatAuditValue(newValues[field]),
+		})
+	}
+	return changes
+}
+
+func decodeAuditValues(value sql.NullString) map[string]any {
+	values := map[string]any{}
+	if value.Valid {
+		json.Unmarshal([]byte(value.String), &values)
+	}
+	return values
+}
+
+func formatAuditValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatAuditValue(item))
+		}
+		return strings.Join(items, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type DashboardStatusCount struct {
+	Status EmployeeStatus
+	Total  int
+}
+
+type DashboardStatistics struct {
+	Total    int
+	Statuses []DashboardStatusCount
+}
+
+// DashboardDistribution counts employees of a group, Key is the value used to filter employee list
+type DashboardDistribution struct {
+	Key   string
+	Label string
+	Total int
+}
+
+// DashboardMonthlyTrend is hires, exits and headcount at the end of a month
+type DashboardMonthlyTrend struct {
+	Month     time.Time
+	Hires     int
+	Exits     int
+	Headcount int
+}
+
+// DashboardAnniversary is an upcoming work anniversary of an employee
+type DashboardAnniversary struct {
+	EmployeeId int
+	Name       string
+	HiredDate  time.Time
+	Date       time.Time
+	Years      int
+	DaysLeft   int
+}
+
+type DashboardAnalytics struct {
+	Months             int
+	From               time.Time
+	To                 time.Time
+	Trends             []DashboardMonthlyTrend
+	Genders            []DashboardDistribution
+	AllowanceTypes     []DashboardDistribution
+	AverageTenureYears float64
+	Anniversaries      []DashboardAnniversary
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type Department struct {
+	Id int
+	ParentId sql.NullInt64
+	ParentName sql.NullString
+	Name string
+	Description sql.NullString
+	// TotalEmployee is number of employees directly in the department, only filled in list
+	TotalEmployee int
+	// Depth is level in the department tree starting from 0, only filled in tree list
+	Depth int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IndentedName prefixes the name by its depth, used in tree list and select options
+func (department *Department) IndentedName() string {
+	return strings.Repeat("— ", department.Depth) + department.Name
+}
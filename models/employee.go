@@ -11,5 +11,18 @@ type Employee struct {
 	HiredDate sql.NullTime
 	Address sql.NullString
 	Status sql.NullString
+	BaseSalary Money
+	DepartmentId sql.NullInt64
+	DepartmentName sql.NullString
+	PositionId sql.NullInt64
+	PositionName sql.NullString
+	ManagerId sql.NullInt64
+	ManagerName sql.NullString
 	TotalAllowance int
+	DeletedAt sql.NullTime
+}
+
+// StatusConfig returns lifecycle config of the current status
+func (employee Employee) StatusConfig() EmployeeStatus {
+	return EmployeeStatusOf(employee.Status.String)
 }
\ No newline at end of file
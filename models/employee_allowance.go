@@ -3,5 +3,20 @@ package models
 type EmployeeAllowance struct {
 	Id int
 	EmployeeId int
+	AllowanceTypeId int
+	Code string
+	// Allowance is name of the allowance type
 	Allowance string
-}
\ No newline at end of file
+	// Amount overrides the default amount of the allowance type for this employee
+	Amount NullMoney
+	DefaultAmount Money
+	IsTaxable bool
+}
+
+// EffectiveAmount returns the employee override when set, otherwise the default amount of the type
+func (allowance EmployeeAllowance) EffectiveAmount() Money {
+	if allowance.Amount.Valid {
+		return allowance.Amount.Money
+	}
+	return allowance.DefaultAmount
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	EmployeeDocumentContract = "CONTRACT"
+	EmployeeDocumentIdCard = "ID_CARD"
+	EmployeeDocumentCertificate = "CERTIFICATE"
+	EmployeeDocumentOther = "OTHER"
+)
+
+// EmployeeDocumentType is a kind of document attached to the employee
+type EmployeeDocumentType struct {
+	Code string
+	Label string
+	Icon string
+}
+
+// EmployeeDocumentTypes lists document types in the order of the upload form
+var EmployeeDocumentTypes = []EmployeeDocumentType{
+	{Code: EmployeeDocumentContract, Label: "Contract", Icon: "mdi-file-sign"},
+	{Code: EmployeeDocumentIdCard, Label: "ID Card", Icon: "mdi-card-account-details-outline"},
+	{Code: EmployeeDocumentCertificate, Label: "Certificate", Icon: "mdi-certificate-outline"},
+	{Code: EmployeeDocumentOther, Label: "Other", Icon: "mdi-file-outline"},
+}
+
+// EmployeeDocumentExpiringDays is how many days before expiry the document is flagged as expiring
+const EmployeeDocumentExpiringDays = 30
+
+func GetEmployeeDocumentType(code string) (EmployeeDocumentType, bool) {
+	for _, documentType := range EmployeeDocumentTypes {
+		if documentType.Code == code {
+			return documentType, true
+		}
+	}
+	return EmployeeDocumentType{}, false
+}
+
+type EmployeeDocument struct {
+	Id int
+	EmployeeId int
+	EmployeeName string
+	DocumentType string
+	Description sql.NullString
+	FilePath string
+	FileName string
+	MimeType string
+	FileSize int64
+	ExpiredAt sql.NullTime
+	CreatedBy sql.NullInt64
+	CreatedByName sql.NullString
+	CreatedAt time.Time
+}
+
+// DocumentTypeConfig returns the configured type, unknown code is shown as is
+func (document EmployeeDocument) DocumentTypeConfig() EmployeeDocumentType {
+	if documentType, ok := GetEmployeeDocumentType(document.DocumentType); ok {
+		return documentType
+	}
+	return EmployeeDocumentType{Code: document.DocumentType, Label: document.DocumentType, Icon: "mdi-file-outline"}
+}
+
+// DaysToExpiry returns days left until the document expires, negative when it is already expired
+func (document EmployeeDocument) DaysToExpiry() int {
+	if !document.ExpiredAt.Valid {
+		return 0
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	expiredAt := document.ExpiredAt.Time
+	expiredAt = time.Date(expiredAt.Year(), expiredAt.Month(), expiredAt.Day(), 0, 0, 0, 0, time.UTC)
+	return int(expiredAt.Sub(today).Hours() / 24)
+}
+
+func (document EmployeeDocument) IsExpired() bool {
+	return document.ExpiredAt.Valid && document.DaysToExpiry() < 0
+}
+
+func (document EmployeeDocument) IsExpiring() bool {
+	return document.ExpiredAt.Valid && !document.IsExpired() && document.DaysToExpiry() <= EmployeeDocumentExpiringDays
+}
+
+// AuditLabel identifies the document in audit logs of the employee
+func (document EmployeeDocument) AuditLabel() string {
+	return fmt.Sprintf("%s: %s", document.DocumentTypeConfig().Label, document.FileName)
+}
+
+// FileSizeLabel formats the file size for display e.g. 1.5 MB
+func (document EmployeeDocument) FileSizeLabel() string {
+	switch {
+	case document.FileSize >= 1 << 20:
+		return fmt.Sprintf("%.1f MB", float64(document.FileSize) / (1 << 20))
+	case document.FileSize >= 1 << 10:
+		return fmt.Sprintf("%.1f KB", float64(document.FileSize) / (1 << 10))
+	default:
+		return fmt.Sprintf("%d B", document.FileSize)
+	}
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"database/sql"
+	"slices"
+	"time"
+)
+
+const (
+	EmployeeStatusPending = "PENDING"
+	EmployeeStatusActive = "ACTIVE"
+	EmployeeStatusOnLeave = "ON_LEAVE"
+	EmployeeStatusInactive = "INACTIVE"
+	EmployeeStatusTerminated = "TERMINATED"
+)
+
+// EmployeeStatus is a state of the employee lifecycle
+type EmployeeStatus struct {
+	Code string
+	Label string
+	// Color is bootstrap theme color of the badge and dashboard card
+	Color string
+	Icon string
+	// Initial status can be set when the employee is created
+	Initial bool
+	// Transitions lists statuses the employee can move to from this status
+	Transitions []string
+}
+
+// EmployeeStatuses is the lifecycle of employees, the order is used in lists and dashboard
+var EmployeeStatuses = []EmployeeStatus{
+	{
+		Code: EmployeeStatusPending,
+		Label: "Pending",
+		Color: "secondary",
+		Icon: "mdi-account-clock-outline",
+		Initial: true,
+		Transitions: []string{EmployeeStatusActive, EmployeeStatusTerminated},
+	},
+	{
+		Code: EmployeeStatusActive,
+		Label: "Active",
+		Color: "success",
+		Icon: "mdi-account-check-outline",
+		Initial: true,
+		Transitions: []string{EmployeeStatusOnLeave, EmployeeStatusInactive, EmployeeStatusTerminated},
+	},
+	{
+		Code: EmployeeStatusOnLeave,
+		Label: "On Leave",
+		Color: "info",
+		Icon: "mdi-account-arrow-right-outline",
+		Transitions: []string{EmployeeStatusActive, EmployeeStatusTerminated},
+	},
+	{
+		Code: EmployeeStatusInactive,
+		Label: "Inactive",
+		Color: "warning",
+		Icon: "mdi-account-cancel-outline",
+		Transitions: []string{EmployeeStatusActive, EmployeeStatusTerminated},
+	},
+	{
+		Code: EmployeeStatusTerminated,
+		Label: "Terminated",
+		Color: "danger",
+		Icon: "mdi-account-remove-outline",
+	},
+}
+
+// GetEmployeeStatus finds configured status by its code
+func GetEmployeeStatus(code string) (EmployeeStatus, bool) {
+	index := slices.IndexFunc(EmployeeStatuses, func(status EmployeeStatus) bool {
+		return status.Code == code
+	})
+	if index < 0 {
+		return EmployeeStatus{}, false
+	}
+	return EmployeeStatuses[index], true
+}
+
+// EmployeeStatusOf returns configured status of the code, status that is not in the lifecycle
+// (e.g. legacy data) is returned with the code as label and without transition
+func EmployeeStatusOf(code string) EmployeeStatus {
+	if status, ok := GetEmployeeStatus(code); ok {
+		return status
+	}
+	if code == "" {
+		return EmployeeStatus{Label: "Unknown", Color: "dark"}
+	}
+	return EmployeeStatus{Code: code, Label: code, Color: "dark"}
+}
+
+// InitialEmployeeStatuses returns statuses that can be set when the employee is created
+func InitialEmployeeStatuses() []EmployeeStatus {
+	statuses := []EmployeeStatus{}
+	for _, status := range EmployeeStatuses {
+		if status.Initial {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+func (status EmployeeStatus) CanTransitionTo(code string) bool {
+	return slices.Contains(status.Transitions, code)
+}
+
+// NextStatuses returns configured statuses the employee can move to
+func (status EmployeeStatus) NextStatuses() []EmployeeStatus {
+	statuses := []EmployeeStatus{}
+	for _, code := range status.Transitions {
+		if next, ok := GetEmployeeStatus(code); ok {
+			statuses = append(statuses, next)
+		}
+	}
+	return statuses
+}
+
+// EmployeeStatusHistory is a status change of the employee, the first entry has no from status
+type EmployeeStatusHistory struct {
+	Id int
+	EmployeeId int
+	FromStatus sql.NullString
+	ToStatus string
+	Reason sql.NullString
+	EffectiveDate time.Time
+	CreatedBy sql.NullInt64
+	CreatedByName sql.NullString
+	CreatedAt time.Time
+}
+
+func (history EmployeeStatusHistory) FromStatusConfig() EmployeeStatus {
+	return EmployeeStatusOf(history.FromStatus.String)
+}
+
+func (history EmployeeStatusHistory) ToStatusConfig() EmployeeStatus {
+	return EmployeeStatusOf(history.ToStatus)
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Factors of the login, each one is throttled by its own attempts
+const (
+	LoginFactorPassword = "PASSWORD"
+	LoginFactorTwoFactor = "TWO_FACTOR"
+)
+
+type FailedLogin struct {
+	Id int
+	Username string
+	IpAddress string
+	Factor string
+	UserAgent sql.NullString
+	AttemptedAt time.Time
+}
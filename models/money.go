@@ -0,0 +1,141 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is amount in cents, it maps to DECIMAL(15,2) columns without going through float
+type Money int64
+
+// ParseMoney reads decimal amount with up to 2 decimals, e.g. "1500000.5" -> 150000050
+func ParseMoney(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+	digits, negative := strings.CutPrefix(value, "-")
+	integer, decimal, _ := strings.Cut(digits, ".")
+	if integer == "" && decimal == "" || len(decimal) > 2 || !isDigits(integer) || !isDigits(decimal) {
+		return 0, fmt.Errorf("invalid money amount %q", value)
+	}
+	decimal += strings.Repeat("0", 2-len(decimal))
+	cents, err := strconv.ParseInt("0"+integer+decimal, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid money amount %q: %w", value, err)
+	}
+	if negative {
+		cents = -cents
+	}
+	return Money(cents), nil
+}
+
+func isDigits(value string) bool {
+	for _, digit := range value {
+		if digit < '0' || digit > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount with 2 decimals and without thousand separator, e.g. 150000050 -> "1500000.50"
+func (money Money) String() string {
+	cents := int64(money)
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// Percent returns rate percent of the amount rounded half away from zero to cents,
+// the rate is kept to basis points so the amount is never multiplied as float
+func (money Money) Percent(rate float64) Money {
+	basisPoints := int64(math.Round(rate * 100))
+	cents := int64(money)
+	// Split to avoid overflow of large amounts, remainder * basis points fits in int64
+	result := cents / 10000 * basisPoints
+	remainder := cents % 10000 * basisPoints
+	if remainder >= 0 {
+		result += (remainder + 5000) / 10000
+	} else {
+		result += (remainder - 5000) / 10000
+	}
+	return Money(result)
+}
+
+func (money *Money) Scan(src any) error {
+	switch value := src.(type) {
+	case []byte:
+		return money.parse(string(value))
+	case string:
+		return money.parse(value)
+	case int64:
+		*money = Money(value * 100)
+		return nil
+	case float64:
+		*money = Money(math.Round(value * 100))
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into Money", src)
+}
+
+func (money *Money) parse(value string) error {
+	// DECIMAL(15,2) is read with 2 decimals, trailing zeros beyond that are not significant
+	if integer, decimal, ok := strings.Cut(value, "."); ok && len(decimal) > 2 {
+		value = integer + "." + strings.TrimRight(decimal, "0")
+	}
+	parsed, err := ParseMoney(value)
+	if err != nil {
+		return err
+	}
+	*money = parsed
+	return nil
+}
+
+// Value stores the amount as decimal string so the column gets the exact cents
+func (money Money) Value() (driver.Value, error) {
+	return money.String(), nil
+}
+
+// MarshalJSON writes the amount as number with 2 decimals
+func (money Money) MarshalJSON() ([]byte, error) {
+	return []byte(money.String()), nil
+}
+
+// UnmarshalJSON reads the amount from number or numeric string
+func (money *Money) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" {
+		return nil
+	}
+	parsed, err := ParseMoney(value)
+	if err != nil {
+		return err
+	}
+	*money = parsed
+	return nil
+}
+
+// NullMoney is Money that may be null, like sql.NullFloat64
+type NullMoney struct {
+	Money Money
+	Valid bool
+}
+
+func (money *NullMoney) Scan(src any) error {
+	if src == nil {
+		money.Money, money.Valid = 0, false
+		return nil
+	}
+	money.Valid = true
+	return money.Money.Scan(src)
+}
+
+func (money NullMoney) Value() (driver.Value, error) {
+	if !money.Valid {
+		return nil, nil
+	}
+	return money.Money.Value()
+}
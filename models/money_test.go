@@ -0,0 +1,165 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Money
+		wantErr bool
+	}{
+		{value: "1500000", want: 150000000},
+		{value: "1500000.5", want: 150000050},
+		{value: "1500000.05", want: 150000005},
+		{value: " 12.30 ", want: 1230},
+		{value: ".5", want: 50},
+		{value: "7.", want: 700},
+		{value: "-12.34", want: -1234},
+		{value: "0", want: 0},
+		{value: "12.345", wantErr: true},
+		{value: "1,500", wantErr: true},
+		{value: "1e6", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "-", wantErr: true},
+		{value: ".", wantErr: true},
+		{value: "99999999999999999999", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := ParseMoney(test.value)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseMoney(%q) = %d, want error", test.value, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("ParseMoney(%q) = %d, %v, want %d", test.value, got, err, test.want)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: 150000050, want: "1500000.50"},
+		{money: 5, want: "0.05"},
+		{money: 0, want: "0.00"},
+		{money: -1234, want: "-12.34"},
+		{money: -5, want: "-0.05"},
+	}
+	for _, test := range tests {
+		if got := test.money.String(); got != test.want {
+			t.Errorf("Money(%d).String() = %s, want %s", test.money, got, test.want)
+		}
+	}
+}
+
+func TestMoneyPercent(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		rate  float64
+		want  Money
+	}{
+		{name: "exact", money: 100000, rate: 5, want: 5000},
+		{name: "rounds down below half cent", money: 123456789, rate: 5, want: 6172839},
+		{name: "rounds up from half cent", money: 10, rate: 5, want: 1},
+		{name: "rounds up above half cent", money: 123456789, rate: 2, want: 2469136},
+		{name: "below half cent", money: 9, rate: 5, want: 0},
+		{name: "fractional rate", money: 100000, rate: 2.5, want: 2500},
+		{name: "fractional rate rounded to basis point", money: 100000, rate: 0.125, want: 130},
+		{name: "negative rounds away from zero", money: -10, rate: 5, want: -1},
+		{name: "zero rate", money: 123456789, rate: 0, want: 0},
+		{name: "large amount does not overflow", money: 999999999999999, rate: 100, want: 999999999999999},
+	}
+	for _, test := range tests {
+		if got := test.money.Percent(test.rate); got != test.want {
+			t.Errorf("%s: Money(%d).Percent(%v) = %d, want %d", test.name, test.money, test.rate, got, test.want)
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		src     any
+		want    Money
+		wantErr bool
+	}{
+		{src: []byte("1234567.89"), want: 123456789},
+		{src: "0.00", want: 0},
+		{src: "12.5", want: 1250},
+		{src: "12.5000", want: 1250},
+		{src: int64(12), want: 1200},
+		{src: 0.29, want: 29},
+		{src: "12.345", wantErr: true},
+		{src: nil, wantErr: true},
+		{src: true, wantErr: true},
+	}
+	for _, test := range tests {
+		var got Money
+		err := got.Scan(test.src)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("Scan(%#v) = %d, want error", test.src, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("Scan(%#v) = %d, %v, want %d", test.src, got, err, test.want)
+		}
+	}
+}
+
+func TestMoneyValue(t *testing.T) {
+	value, err := Money(123456789).Value()
+	if err != nil || value != "1234567.89" {
+		t.Errorf("Value() = %#v, %v, want \"1234567.89\"", value, err)
+	}
+
+	value, err = NullMoney{}.Value()
+	if err != nil || value != nil {
+		t.Errorf("null Value() = %#v, %v, want nil", value, err)
+	}
+	value, err = NullMoney{Money: 50, Valid: true}.Value()
+	if err != nil || value != "0.50" {
+		t.Errorf("valid Value() = %#v, %v, want \"0.50\"", value, err)
+	}
+
+	var null NullMoney
+	if err := null.Scan(nil); err != nil || null.Valid {
+		t.Errorf("Scan(nil) = %+v, %v, want invalid", null, err)
+	}
+	if err := null.Scan([]byte("3.10")); err != nil || !null.Valid || null.Money != 310 {
+		t.Errorf("Scan(3.10) = %+v, %v, want valid 310", null, err)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	encoded, err := json.Marshal(struct {
+		Amount Money `json:"amount"`
+	}{Amount: 150000050})
+	if err != nil || string(encoded) != `{"amount":1500000.50}` {
+		t.Errorf("Marshal = %s, %v", encoded, err)
+	}
+
+	for _, data := range []string{`{"amount":1500000.5}`, `{"amount":"1500000.50"}`} {
+		var decoded struct {
+			Amount *Money `json:"amount"`
+		}
+		if err := json.Unmarshal([]byte(data), &decoded); err != nil || decoded.Amount == nil || *decoded.Amount != 150000050 {
+			t.Errorf("Unmarshal(%s) = %v, %v, want 150000050", data, decoded.Amount, err)
+		}
+	}
+	var decoded struct {
+		Amount Money `json:"amount"`
+	}
+	if err := json.Unmarshal([]byte(`{"amount":1.005}`), &decoded); err == nil {
+		t.Error("Unmarshal of amount with 3 decimals is accepted")
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+// OrgChartNode is an employee with the employees reporting directly to them
+type OrgChartNode struct {
+	Employee Employee
+	Reports []*OrgChartNode
+}
+
+// TotalReports counts all employees under the node, directly or not
+func (node *OrgChartNode) TotalReports() int {
+	total := len(node.Reports)
+	for _, report := range node.Reports {
+		total += report.TotalReports()
+	}
+	return total
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type PasswordReset struct {
+	Id int
+	UserId int
+	// Token is sha256 hash of the token sent to the user
+	Token string
+	ExpiredAt time.Time
+	UsedAt sql.NullTime
+	CreatedAt time.Time
+}
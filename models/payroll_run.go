@@ -0,0 +1,50 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Payroll run goes from draft to reviewed to locked, a reviewed run can be reopened to draft
+const (
+	PayrollStatusDraft = "DRAFT"
+	PayrollStatusReviewed = "REVIEWED"
+	PayrollStatusLocked = "LOCKED"
+)
+
+type PayrollRun struct {
+	Id int
+	// Period is the first day of the paid month
+	Period time.Time
+	Status string
+	// CorrectsRunId is the locked run of the same period replaced by this correction
+	CorrectsRunId sql.NullInt64
+	Notes sql.NullString
+	TotalEmployee int
+	TotalGross Money
+	TotalDeduction Money
+	TotalNet Money
+	CreatedBy sql.NullInt64
+	CreatedByName sql.NullString
+	ReviewedBy sql.NullInt64
+	ReviewedByName sql.NullString
+	ReviewedAt sql.NullTime
+	LockedBy sql.NullInt64
+	LockedByName sql.NullString
+	LockedAt sql.NullTime
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (run *PayrollRun) IsLocked() bool {
+	return run.Status == PayrollStatusLocked
+}
+
+func (run *PayrollRun) IsCorrection() bool {
+	return run.CorrectsRunId.Valid
+}
+
+// PeriodLabel returns the paid month, e.g. January 2024
+func (run *PayrollRun) PeriodLabel() string {
+	return run.Period.Format("January 2006")
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	PayslipItemAllowance = "ALLOWANCE"
+	PayslipItemDeduction = "DEDUCTION"
+)
+
+// Payslip is snapshot of employee pay in a payroll run, employee data is copied
+// so it is kept as it was paid even when the employee changes later
+type Payslip struct {
+	Id int
+	PayrollRunId int
+	EmployeeId sql.NullInt64
+	EmployeeName string
+	EmployeeEmail sql.NullString
+	EmployeeTaxNumber sql.NullString
+	BaseSalary Money
+	TotalAllowance Money
+	TotalDeduction Money
+	GrossPay Money
+	NetPay Money
+	CreatedAt time.Time
+	Items []PayslipItem
+	// Period, RunStatus and IsCorrection come from the run, only filled in employee payslip history
+	Period time.Time
+	RunStatus string
+	IsCorrection bool
+}
+
+type PayslipItem struct {
+	Id int
+	PayslipId int
+	Type string
+	Code string
+	Name string
+	Amount Money
+	IsTaxable bool
+}
+
+// Allowances returns earning items besides the base salary
+func (payslip *Payslip) Allowances() []PayslipItem {
+	return payslip.itemsOf(PayslipItemAllowance)
+}
+
+func (payslip *Payslip) Deductions() []PayslipItem {
+	return payslip.itemsOf(PayslipItemDeduction)
+}
+
+func (payslip *Payslip) itemsOf(itemType string) []PayslipItem {
+	items := []PayslipItem{}
+	for _, item := range payslip.Items {
+		if item.Type == itemType {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,9 @@
+package models
+
+import "database/sql"
+
+type Permission struct {
+	Id int
+	Name string
+	Description sql.NullString
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"database/sql"
+	"slices"
+	"time"
+)
+
+// PersonalAccessTokenPrefix distinguishes personal access tokens from JWTs in the Authorization header
+const PersonalAccessTokenPrefix = "pat_"
+
+type PersonalAccessToken struct {
+	Id int
+	UserId int
+	Name string
+	// Token is sha256 hash of the plain token
+	Token string
+	// Scopes are permission names the token is allowed to use
+	Scopes []string
+	ExpiredAt sql.NullTime
+	LastUsedAt sql.NullTime
+	LastUsedIp sql.NullString
+	CreatedAt time.Time
+}
+
+func (token *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return token.ExpiredAt.Valid && !token.ExpiredAt.Time.After(now)
+}
+
+func (token *PersonalAccessToken) HasScope(scope string) bool {
+	return slices.Contains(token.Scopes, scope)
+}
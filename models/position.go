@@ -0,0 +1,16 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Position struct {
+	Id int
+	Name string
+	Description sql.NullString
+	// TotalEmployee is number of employees holding the position, only filled in list
+	TotalEmployee int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
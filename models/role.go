@@ -0,0 +1,11 @@
+package models
+
+import "database/sql"
+
+type Role struct {
+	Id int
+	Name string
+	Description sql.NullString
+	RequireTwoFactor bool
+	Permissions []string
+}
@@ -11,4 +11,13 @@ type User struct {
 	UserType string
 	Status string
 	Avatar sql.NullString
+	SessionsRevokedAt sql.NullTime
+	// TwoFactorSecret is encrypted TOTP secret, two factor is enabled once it is confirmed
+	TwoFactorSecret sql.NullString
+	TwoFactorConfirmedAt sql.NullTime
+	TwoFactorLastStep sql.NullInt64
+}
+
+func (user *User) HasTwoFactor() bool {
+	return user.TwoFactorSecret.Valid && user.TwoFactorConfirmedAt.Valid
 }
\ No newline at end of file
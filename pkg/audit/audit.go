@@ -0,0 +1,55 @@
+// Package audit carries the actor of the request down to the services
+// and computes the changed values of audited entities
+package audit
+
+import (
+	"context"
+	"reflect"
+)
+
+type contextKey string
+
+const actorContextKey contextKey = "audit_actor"
+
+// Actor is who made the change, UserId is zero for console commands
+type Actor struct {
+	UserId    int
+	IpAddress string
+	UserAgent string
+}
+
+// Values is snapshot of the audited fields, keyed by column name
+type Values map[string]any
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey, actor)
+}
+
+// ActorFromContext returns the actor stored by the auth middleware, empty when there is none
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorContextKey).(Actor)
+	return actor
+}
+
+// Diff returns old and new values of the changed fields only,
+// a nil snapshot (created or deleted entity) keeps all fields of the other one
+func Diff(before Values, after Values) (Values, Values) {
+	if before == nil || after == nil {
+		return before, after
+	}
+	oldValues := Values{}
+	newValues := Values{}
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) {
+			oldValues[key] = before[key]
+			newValues[key] = value
+		}
+	}
+	for key, value := range before {
+		if _, ok := after[key]; !ok {
+			oldValues[key] = value
+			newValues[key] = nil
+		}
+	}
+	return oldValues, newValues
+}
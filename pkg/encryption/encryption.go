@@ -0,0 +1,56 @@
+// Package encryption encrypts secrets at rest with AES-256-GCM using a key derived from APP_KEY
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+)
+
+func newCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(configs.Get().App.Key))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt returns base64 encoded nonce and cipher text
+func Encrypt(plainText string) (string, error) {
+	aead, err := newCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plainText), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func Decrypt(encrypted string) (string, error) {
+	aead, err := newCipher()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+	nonce, cipherText := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plainText, err := aead.Open(nil, nonce, cipherText, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plainText), nil
+}
@@ -0,0 +1,133 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	FormatPNG  = "png"
+	FormatJPEG = "jpeg"
+
+	// MaxDimension and MaxPixels are checked from the header before decoding, a small file can
+	// declare huge dimensions (decompression bomb) that would exhaust memory when decoded
+	MaxDimension = 6000
+	MaxPixels    = 24_000_000
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("image must be a PNG or JPEG file")
+	ErrTooLarge          = fmt.Errorf("image must not be larger than %dx%d pixels", MaxDimension, MaxDimension)
+)
+
+var (
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+	jpegSignature = []byte{0xFF, 0xD8, 0xFF}
+)
+
+// Sniff detects the format from magic bytes of the content, the file extension is never trusted
+func Sniff(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, pngSignature):
+		return FormatPNG
+	case bytes.HasPrefix(content, jpegSignature):
+		return FormatJPEG
+	default:
+		return ""
+	}
+}
+
+// Check validates magic bytes and dimensions of the image without decoding the pixels
+func Check(content []byte) (string, error) {
+	format := Sniff(content)
+	if format == "" {
+		return "", ErrUnsupportedFormat
+	}
+	config, decodedFormat, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || decodedFormat != format {
+		return "", ErrUnsupportedFormat
+	}
+	if config.Width < 1 || config.Height < 1 {
+		return "", ErrUnsupportedFormat
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension || config.Width * config.Height > MaxPixels {
+		return "", ErrTooLarge
+	}
+	return format, nil
+}
+
+// Read checks and decodes the image, limit is the maximum bytes read from the reader
+func Read(r io.Reader, limit int64) (image.Image, string, []byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, limit + 1))
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if int64(len(content)) > limit {
+		return nil, "", nil, fmt.Errorf("image must not be larger than %d bytes", limit)
+	}
+	format, err := Check(content)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", nil, ErrUnsupportedFormat
+	}
+	return img, format, content, nil
+}
+
+// SquareThumbnail crops the center square of the image and scales it to the size
+func SquareThumbnail(img image.Image, size int) *image.NRGBA {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx() - side) / 2
+	y := bounds.Min.Y + (bounds.Dy() - side) / 2
+
+	thumbnail := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), img, image.Rect(x, y, x + side, y + side), draw.Src, nil)
+	return thumbnail
+}
+
+// Encode writes the image in the format, metadata (EXIF, GPS, comments) of the source is never written
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatPNG:
+		return png.Encode(w, img)
+	case FormatJPEG:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// Extension returns file extension of the format
+func Extension(format string) string {
+	if format == FormatJPEG {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+var thumbnailSuffix = regexp.MustCompile(`_\d+$`)
+
+// ThumbnailPath returns path of the thumbnail size next to the image e.g. avatars/2025/01/3f2a_128.png
+func ThumbnailPath(imagePath string, size int) string {
+	ext := path.Ext(imagePath)
+	return fmt.Sprintf("%s_%d%s", strings.TrimSuffix(imagePath, ext), size, ext)
+}
+
+// OriginalPath returns path of the image the thumbnail is made of, other path is returned as is
+func OriginalPath(thumbnailPath string) string {
+	ext := path.Ext(thumbnailPath)
+	return thumbnailSuffix.ReplaceAllString(strings.TrimSuffix(thumbnailPath, ext), "") + ext
+}
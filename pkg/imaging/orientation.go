@@ -0,0 +1,102 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// Orientation reads EXIF orientation (1-8) of JPEG content, 1 (normal) is returned when it is missing
+func Orientation(content []byte) int {
+	if Sniff(content) != FormatJPEG {
+		return 1
+	}
+	// Walk the JPEG segments until APP1 (Exif) or the start of scan
+	offset := 2
+	for offset + 4 <= len(content) && content[offset] == 0xFF {
+		marker := content[offset + 1]
+		length := int(binary.BigEndian.Uint16(content[offset + 2:]))
+		if marker == 0xDA || length < 2 || offset + 2 + length > len(content) {
+			break
+		}
+		segment := content[offset + 4 : offset + 2 + length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		offset += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation finds orientation tag (0x0112) in the first IFD of the TIFF structure
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd + 2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := range entries {
+		entry := ifd + 2 + i * 12
+		if entry + 12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry + 8:]))
+			if orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// Orient rotates and flips the image to display as intended by EXIF orientation, it is applied
+// before metadata is stripped so photos taken in portrait are not shown sideways
+func Orient(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	// Orientation 5-8 swap width and height
+	if orientation >= 5 {
+		width, height = height, width
+	}
+	oriented := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			var sx, sy int
+			switch orientation {
+			case 2: // flipped horizontally
+				sx, sy = width - 1 - x, y
+			case 3: // rotated 180
+				sx, sy = width - 1 - x, height - 1 - y
+			case 4: // flipped vertically
+				sx, sy = x, height - 1 - y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // rotated 90 clockwise
+				sx, sy = y, width - 1 - x
+			case 7: // transversed
+				sx, sy = height - 1 - y, width - 1 - x
+			case 8: // rotated 90 counter clockwise
+				sx, sy = height - 1 - y, x
+			}
+			oriented.SetNRGBA(x, y, img.NRGBAAt(bounds.Min.X + sx, bounds.Min.Y + sy))
+		}
+	}
+	return oriented
+}
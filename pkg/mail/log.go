@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+)
+
+// LogMailer writes each message as .eml file into the outbox directory for local development
+type LogMailer struct {
+	from      string
+	outboxDir string
+}
+
+func NewLogMailer(config configs.MailConfig) *LogMailer {
+	return &LogMailer{
+		from:      formatAddress(config.FromName, config.FromAddress),
+		outboxDir: config.OutboxPath,
+	}
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func (mailer *LogMailer) Send(ctx context.Context, message Message) error {
+	if err := os.MkdirAll(mailer.outboxDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	filename := fmt.Sprintf("%s_%s.eml", now.Format("20060102150405.000000"), unsafeFilenameChars.ReplaceAllString(message.To, "_"))
+	path := filepath.Join(mailer.outboxDir, filename)
+
+	if err := os.WriteFile(path, buildMessage(mailer.from, message, now), 0644); err != nil {
+		return err
+	}
+
+	slog.Info("Mail written to outbox", slog.String("to", message.To), slog.String("subject", message.Subject), slog.String("path", path))
+	return nil
+}
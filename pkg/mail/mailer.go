@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	// Body is HTML content of the mail
+	Body    string
+}
+
+// Mailer delivers messages, drivers are selected by MAIL_DRIVER
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+func New(config configs.MailConfig) (Mailer, error) {
+	switch config.Driver {
+	case "log", "file":
+		return NewLogMailer(config), nil
+	case "smtp":
+		return NewSMTPMailer(config), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver %q", config.Driver)
+	}
+}
+
+func formatAddress(name string, address string) string {
+	if name == "" {
+		return address
+	}
+	return fmt.Sprintf("%s <%s>", mime.QEncoding.Encode("utf-8", name), address)
+}
+
+// buildMessage formats the message as RFC 5322 HTML mail
+func buildMessage(from string, message Message, date time.Time) []byte {
+	var content strings.Builder
+	fmt.Fprintf(&content, "From: %s\r\n", from)
+	fmt.Fprintf(&content, "To: %s\r\n", message.To)
+	fmt.Fprintf(&content, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&content, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	content.WriteString("MIME-Version: 1.0\r\n")
+	content.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	content.WriteString(message.Body)
+	return []byte(content.String())
+}
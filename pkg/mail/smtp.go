@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+)
+
+// SMTPMailer sends messages through SMTP server, STARTTLS is used when the server supports it
+type SMTPMailer struct {
+	address     string
+	auth        smtp.Auth
+	from        string
+	fromAddress string
+}
+
+func NewSMTPMailer(config configs.MailConfig) *SMTPMailer {
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+	return &SMTPMailer{
+		address:     fmt.Sprintf("%s:%d", config.Host, config.Port),
+		auth:        auth,
+		from:        formatAddress(config.FromName, config.FromAddress),
+		fromAddress: config.FromAddress,
+	}
+}
+
+func (mailer *SMTPMailer) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	body := buildMessage(mailer.from, message, time.Now())
+	if err := smtp.SendMail(mailer.address, mailer.auth, mailer.fromAddress, []string{message.To}, body); err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", message.To, err)
+	}
+	return nil
+}
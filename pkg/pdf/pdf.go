@@ -0,0 +1,232 @@
+// Package pdf writes simple text and line PDF documents using the standard Helvetica fonts.
+// Each page is written to the underlying writer as soon as the next page starts,
+// so long reports are streamed without keeping the whole document in memory.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Font string
+
+const (
+	Helvetica     Font = "Helvetica"
+	HelveticaBold Font = "Helvetica-Bold"
+)
+
+// Page sizes in points (1/72 inch)
+var (
+	A4          = Size{Width: 595.28, Height: 841.89}
+	A4Landscape = Size{Width: 841.89, Height: 595.28}
+)
+
+type Size struct {
+	Width  float64
+	Height float64
+}
+
+const (
+	catalogId = 1
+	pagesId   = 2
+)
+
+var fontIds = map[Font]int{Helvetica: 3, HelveticaBold: 4}
+var fontNames = map[Font]string{Helvetica: "F1", HelveticaBold: "F2"}
+
+// Document is a PDF being written, coordinates are in points with the origin at the top left corner
+type Document struct {
+	w       *countingWriter
+	size    Size
+	offsets map[int]int64
+	nextId  int
+	pageIds []int
+	content *bytes.Buffer
+	err     error
+}
+
+func New(w io.Writer, size Size) *Document {
+	document := &Document{
+		w:       &countingWriter{w: w},
+		size:    size,
+		offsets: map[int]int64{},
+		nextId:  5,
+	}
+	document.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	for _, font := range []Font{Helvetica, HelveticaBold} {
+		document.writeObject(fontIds[font], fmt.Sprintf(
+			"<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font,
+		))
+	}
+	return document
+}
+
+// Err returns the first error of the document, e.g. to stop writing rows once it failed
+func (document *Document) Err() error {
+	return document.err
+}
+
+func (document *Document) Size() Size {
+	return document.size
+}
+
+// PageCount returns number of pages started so far
+func (document *Document) PageCount() int {
+	return len(document.pageIds)
+}
+
+// AddPage flushes the current page and starts a new one
+func (document *Document) AddPage() error {
+	document.flushPage()
+	document.pageIds = append(document.pageIds, document.reserveId())
+	document.content = &bytes.Buffer{}
+	return document.err
+}
+
+// Text draws text with its baseline at y, text with a character outside WinAnsiEncoding
+// fails the document with UnsupportedCharacterError
+func (document *Document) Text(x, y float64, font Font, size float64, text string) {
+	encoded, err := encode(text)
+	if err != nil {
+		if document.err == nil {
+			document.err = err
+		}
+		return
+	}
+	fmt.Fprintf(document.page(), "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n",
+		fontNames[font], size, x, document.size.Height-y, escape(encoded))
+}
+
+// TextRight draws text that ends at x
+func (document *Document) TextRight(x, y float64, font Font, size float64, text string) {
+	document.Text(x-TextWidth(font, size, text), y, font, size, text)
+}
+
+func (document *Document) Line(x1, y1, x2, y2 float64, width float64) {
+	fmt.Fprintf(document.page(), "%.2f w %.2f %.2f m %.2f %.2f l S\n",
+		width, x1, document.size.Height-y1, x2, document.size.Height-y2)
+}
+
+// FillRect fills rectangle with gray level from 0 (black) to 1 (white)
+func (document *Document) FillRect(x, y, width, height float64, gray float64) {
+	fmt.Fprintf(document.page(), "q %.2f g %.2f %.2f %.2f %.2f re f Q\n",
+		gray, x, document.size.Height-y-height, width, height)
+}
+
+// Close writes the last page and the document trailer, the underlying writer is not closed
+func (document *Document) Close() error {
+	if len(document.pageIds) == 0 {
+		document.AddPage()
+	}
+	document.flushPage()
+
+	kids := make([]string, len(document.pageIds))
+	for i, id := range document.pageIds {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+	document.writeObject(pagesId, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	document.writeObject(catalogId, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesId))
+
+	xref := document.w.count
+	document.printf("xref\n0 %d\n0000000000 65535 f \n", document.nextId)
+	for id := 1; id < document.nextId; id++ {
+		document.printf("%010d 00000 n \n", document.offsets[id])
+	}
+	document.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", document.nextId, catalogId, xref)
+	return document.err
+}
+
+func (document *Document) page() *bytes.Buffer {
+	if document.content == nil {
+		document.AddPage()
+	}
+	return document.content
+}
+
+func (document *Document) flushPage() {
+	if document.content == nil {
+		return
+	}
+	contentId := document.reserveId()
+	document.writeObject(contentId, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", document.content.Len(), document.content.String()))
+	document.writeObject(document.pageIds[len(document.pageIds)-1], fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+		pagesId, document.size.Width, document.size.Height, fontIds[Helvetica], fontIds[HelveticaBold], contentId,
+	))
+	document.content = nil
+}
+
+func (document *Document) reserveId() int {
+	id := document.nextId
+	document.nextId++
+	return id
+}
+
+func (document *Document) writeObject(id int, body string) {
+	document.offsets[id] = document.w.count
+	document.printf("%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+func (document *Document) printf(format string, args ...any) {
+	if document.err != nil {
+		return
+	}
+	_, document.err = fmt.Fprintf(document.w, format, args...)
+}
+
+type countingWriter struct {
+	w     io.Writer
+	count int64
+}
+
+func (writer *countingWriter) Write(p []byte) (int, error) {
+	n, err := writer.w.Write(p)
+	writer.count += int64(n)
+	return n, err
+}
+
+// UnsupportedCharacterError is returned for text with a character outside WinAnsiEncoding,
+// the standard fonts have no glyph for it
+type UnsupportedCharacterError struct {
+	Char rune
+	Text string
+}
+
+func (err *UnsupportedCharacterError) Error() string {
+	return fmt.Sprintf("pdf: character %q of %q is not supported by the standard fonts", err.Char, err.Text)
+}
+
+// winAnsiCodes are characters of WinAnsiEncoding (Windows-1252) that are not at the same code in Latin-1
+var winAnsiCodes = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// encode converts text to WinAnsiEncoding, control characters become space
+func encode(text string) (string, error) {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r < 32 || (r >= 127 && r < 160):
+			encoded = append(encoded, ' ')
+		case r < 256:
+			// ASCII and the upper half of Latin-1 have the same codes in WinAnsiEncoding
+			encoded = append(encoded, byte(r))
+		default:
+			code, ok := winAnsiCodes[r]
+			if !ok {
+				return "", &UnsupportedCharacterError{Char: r, Text: text}
+			}
+			encoded = append(encoded, code)
+		}
+	}
+	return string(encoded), nil
+}
+
+func escape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(text)
+}
@@ -0,0 +1,177 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parsedPdf is the document read back through its cross reference table
+type parsedPdf struct {
+	objects map[int]string
+	root    int
+}
+
+var (
+	startXrefPattern = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	trailerPattern   = regexp.MustCompile(`trailer\n<< /Size (\d+) /Root (\d+) 0 R >>`)
+	lengthPattern    = regexp.MustCompile(`^<< /Length (\d+) >>\nstream\n`)
+)
+
+func parsePdf(t *testing.T, data []byte) *parsedPdf {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) {
+		t.Fatalf("header = %q, want %%PDF-1.4", data[:min(len(data), 9)])
+	}
+	match := startXrefPattern.FindSubmatch(data)
+	if match == nil {
+		t.Fatal("startxref is not found at the end")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	trailer := trailerPattern.FindSubmatch(data[xref:])
+	if trailer == nil {
+		t.Fatal("trailer is not found after xref")
+	}
+	size, _ := strconv.Atoi(string(trailer[1]))
+	root, _ := strconv.Atoi(string(trailer[2]))
+
+	header := fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", size)
+	if !bytes.HasPrefix(data[xref:], []byte(header)) {
+		t.Fatalf("xref at %d = %q, want %q", xref, data[xref:xref+len(header)], header)
+	}
+	parsed := &parsedPdf{objects: map[int]string{}, root: root}
+	entries := data[xref+len(header):]
+	for id := 1; id < size; id++ {
+		// Each entry is exactly 20 bytes, "nnnnnnnnnn ggggg n \n"
+		entry := string(entries[(id-1)*20 : id*20])
+		if !strings.HasSuffix(entry, " 00000 n \n") {
+			t.Fatalf("xref entry of %d = %q", id, entry)
+		}
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil {
+			t.Fatal(err)
+		}
+		prefix := fmt.Sprintf("%d 0 obj\n", id)
+		if !bytes.HasPrefix(data[offset:], []byte(prefix)) {
+			t.Fatalf("object %d at offset %d = %q, want %q", id, offset, data[offset:offset+len(prefix)], prefix)
+		}
+		body := data[offset+len(prefix):]
+		body = body[:bytes.Index(body, []byte("\nendobj\n"))]
+		parsed.objects[id] = string(body)
+	}
+	return parsed
+}
+
+// stream returns data of the stream object, its length must match /Length
+func (parsed *parsedPdf) stream(t *testing.T, id int) string {
+	t.Helper()
+	object := parsed.objects[id]
+	match := lengthPattern.FindStringSubmatch(object)
+	if match == nil {
+		t.Fatalf("object %d is not a stream: %q", id, object)
+	}
+	length, _ := strconv.Atoi(match[1])
+	data := strings.TrimPrefix(object, match[0])
+	if !strings.HasSuffix(data, "endstream") || len(data)-len("endstream") != length {
+		t.Fatalf("stream %d has %d bytes, /Length is %d", id, len(data)-len("endstream"), length)
+	}
+	return data[:length]
+}
+
+// pageContents follows catalog, pages and page objects to the content of each page
+func (parsed *parsedPdf) pageContents(t *testing.T) []string {
+	t.Helper()
+	pagesRef := regexp.MustCompile(`^<< /Type /Catalog /Pages (\d+) 0 R >>$`).FindStringSubmatch(parsed.objects[parsed.root])
+	if pagesRef == nil {
+		t.Fatalf("catalog = %q", parsed.objects[parsed.root])
+	}
+	pagesId, _ := strconv.Atoi(pagesRef[1])
+	pages := regexp.MustCompile(`^<< /Type /Pages /Kids \[([^\]]*)\] /Count (\d+) >>$`).FindStringSubmatch(parsed.objects[pagesId])
+	if pages == nil {
+		t.Fatalf("pages = %q", parsed.objects[pagesId])
+	}
+	kids := regexp.MustCompile(`(\d+) 0 R`).FindAllStringSubmatch(pages[1], -1)
+	if count, _ := strconv.Atoi(pages[2]); count != len(kids) {
+		t.Fatalf("page count = %d, want %d kids", count, len(kids))
+	}
+
+	contents := []string{}
+	for _, kid := range kids {
+		id, _ := strconv.Atoi(kid[1])
+		page := regexp.MustCompile(`^<< /Type /Page /Parent (\d+) 0 R .* /Contents (\d+) 0 R >>$`).FindStringSubmatch(parsed.objects[id])
+		if page == nil || page[1] != pagesRef[1] {
+			t.Fatalf("page %d = %q", id, parsed.objects[id])
+		}
+		contentId, _ := strconv.Atoi(page[2])
+		contents = append(contents, parsed.stream(t, contentId))
+	}
+	return contents
+}
+
+func TestDocument(t *testing.T) {
+	var buffer bytes.Buffer
+	document := New(&buffer, A4)
+	document.Text(50, 60, HelveticaBold, 18, "Employee (Report)")
+	document.Line(50, 70, 545, 70, 0.5)
+	document.AddPage()
+	document.Text(50, 60, Helvetica, 10, "José – “Café” €5…")
+	if err := document.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	contents := parsePdf(t, buffer.Bytes()).pageContents(t)
+	if len(contents) != 2 {
+		t.Fatalf("got %d pages, want 2", len(contents))
+	}
+	wantFirst := "BT /F2 18.00 Tf 50.00 781.89 Td (Employee \\(Report\\)) Tj ET\n0.50 w 50.00 771.89 m 545.00 771.89 l S\n"
+	if contents[0] != wantFirst {
+		t.Errorf("first page = %q, want %q", contents[0], wantFirst)
+	}
+	wantSecond := "BT /F1 10.00 Tf 50.00 781.89 Td (Jos\xe9 \x96 \x93Caf\xe9\x94 \x805\x85) Tj ET\n"
+	if contents[1] != wantSecond {
+		t.Errorf("second page = %q, want %q", contents[1], wantSecond)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Plain ASCII ~", want: "Plain ASCII ~"},
+		{text: "Müller, Ñandú, Ø", want: "M\xfcller, \xd1and\xfa, \xd8"},
+		{text: "‘single’ “double” „low‚", want: "\x91single\x92 \x93double\x94 \x84low\x82"},
+		{text: "€ † ‡ • ™ ‰ ƒ ˆ ˜", want: "\x80 \x86 \x87 \x95 \x99 \x89 \x83 \x88 \x98"},
+		{text: "Š š Ž ž Œ œ Ÿ ‹ ›", want: "\x8a \x9a \x8e \x9e \x8c \x9c \x9f \x8b \x9b"},
+		{text: "line\nbreak\ttab\u0085", want: "line break tab "},
+	}
+	for _, test := range tests {
+		got, err := encode(test.text)
+		if err != nil || got != test.want {
+			t.Errorf("encode(%q) = %q, %v, want %q", test.text, got, err, test.want)
+		}
+	}
+}
+
+func TestUnsupportedCharacter(t *testing.T) {
+	for _, text := range []string{"山田 太郎", "Ivan Іванов", "smile 🙂", "\xff invalid utf-8"} {
+		var buffer bytes.Buffer
+		document := New(&buffer, A4)
+		document.Text(50, 60, Helvetica, 10, "Before")
+		document.Text(50, 80, Helvetica, 10, text)
+		document.Text(50, 100, Helvetica, 10, "After")
+
+		var characterErr *UnsupportedCharacterError
+		err := document.Close()
+		if !errors.As(err, &characterErr) || characterErr.Text != text {
+			t.Errorf("close with %q = %v, want UnsupportedCharacterError of the text", text, err)
+		}
+		if document.Err() != err {
+			t.Errorf("Err() = %v, want %v", document.Err(), err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DatabaseStore keeps sessions in the user_sessions table, sessions of a deleted user are deleted by the foreign key
+type DatabaseStore struct {
+	db *sql.DB
+}
+
+func NewDatabaseStore(db *sql.DB) *DatabaseStore {
+	return &DatabaseStore{db: db}
+}
+
+func (store *DatabaseStore) Create(ctx context.Context, session *Session) error {
+	query := `
+		INSERT INTO user_sessions(id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := store.db.ExecContext(
+		ctx,
+		query,
+		session.Id,
+		session.UserId,
+		session.IpAddress,
+		session.UserAgent,
+		session.CreatedAt,
+		session.LastSeenAt,
+		session.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session of user id=%d: %w", session.UserId, err)
+	}
+	return nil
+}
+
+func (store *DatabaseStore) Get(ctx context.Context, id string) (*Session, error) {
+	query := `
+		SELECT id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at
+		FROM user_sessions
+		WHERE id = ? AND expires_at > ?
+	`
+	var session Session
+	err := store.db.QueryRowContext(ctx, query, id, time.Now()).Scan(
+		&session.Id,
+		&session.UserId,
+		&session.IpAddress,
+		&session.UserAgent,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return &session, nil
+}
+
+func (store *DatabaseStore) GetByUserId(ctx context.Context, userId int) ([]Session, error) {
+	query := `
+		SELECT id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at
+		FROM user_sessions
+		WHERE user_id = ? AND expires_at > ?
+		ORDER BY last_seen_at DESC
+	`
+	rows, err := store.db.QueryContext(ctx, query, userId, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions of user id=%d: %w", userId, err)
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var session Session
+		err := rows.Scan(
+			&session.Id,
+			&session.UserId,
+			&session.IpAddress,
+			&session.UserAgent,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session rows: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get session rows: %w", err)
+	}
+	return sessions, nil
+}
+
+func (store *DatabaseStore) Touch(ctx context.Context, id string, lastSeenAt time.Time, ipAddress string) error {
+	query := `UPDATE user_sessions SET last_seen_at = ?, ip_address = ? WHERE id = ?`
+	if _, err := store.db.ExecContext(ctx, query, lastSeenAt, ipAddress, id); err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+func (store *DatabaseStore) Delete(ctx context.Context, id string) error {
+	if _, err := store.db.ExecContext(ctx, `DELETE FROM user_sessions WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+func (store *DatabaseStore) DeleteByUserId(ctx context.Context, userId int) error {
+	if _, err := store.db.ExecContext(ctx, `DELETE FROM user_sessions WHERE user_id = ?`, userId); err != nil {
+		return fmt.Errorf("failed to delete sessions of user id=%d: %w", userId, err)
+	}
+	return nil
+}
+
+func (store *DatabaseStore) DeleteExpired(ctx context.Context, now time.Time) error {
+	if _, err := store.db.ExecContext(ctx, `DELETE FROM user_sessions WHERE expires_at <= ?`, now); err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps sessions in the process, they are lost on restart and not shared
+// between instances, it is meant for tests and local development
+type MemoryStore struct {
+	mu sync.RWMutex
+	sessions map[string]Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: map[string]Session{}}
+}
+
+func (store *MemoryStore) Create(ctx context.Context, session *Session) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.sessions[session.Id] = *session
+	return nil
+}
+
+func (store *MemoryStore) Get(ctx context.Context, id string) (*Session, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	session, ok := store.sessions[id]
+	if !ok || !session.ExpiresAt.After(time.Now()) {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (store *MemoryStore) GetByUserId(ctx context.Context, userId int) ([]Session, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	now := time.Now()
+	sessions := []Session{}
+	for _, session := range store.sessions {
+		if session.UserId == userId && session.ExpiresAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b Session) int {
+		return b.LastSeenAt.Compare(a.LastSeenAt)
+	})
+	return sessions, nil
+}
+
+func (store *MemoryStore) Touch(ctx context.Context, id string, lastSeenAt time.Time, ipAddress string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if session, ok := store.sessions[id]; ok {
+		session.LastSeenAt = lastSeenAt
+		session.IpAddress = ipAddress
+		store.sessions[id] = session
+	}
+	return nil
+}
+
+func (store *MemoryStore) Delete(ctx context.Context, id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	delete(store.sessions, id)
+	return nil
+}
+
+func (store *MemoryStore) DeleteByUserId(ctx context.Context, userId int) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for id, session := range store.sessions {
+		if session.UserId == userId {
+			delete(store.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (store *MemoryStore) DeleteExpired(ctx context.Context, now time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for id, session := range store.sessions {
+		if !session.ExpiresAt.After(now) {
+			delete(store.sessions, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+)
+
+// ErrSessionNotFound is returned for unknown, expired or revoked session
+var ErrSessionNotFound = errors.New("session is not found")
+
+// Session is a login of the user on a device, Id is the hash of the cookie token
+// so a leaked store does not leak usable tokens
+type Session struct {
+	Id string
+	UserId int
+	IpAddress string
+	UserAgent string
+	CreatedAt time.Time
+	LastSeenAt time.Time
+	ExpiresAt time.Time
+}
+
+// Store keeps server-side sessions keyed by the opaque cookie token, drivers are selected by SESSION_DRIVER.
+// The "jwt" driver has no store, the cookie is a self-contained token and sessions can't be listed.
+type Store interface {
+	Create(ctx context.Context, session *Session) error
+	// Get returns ErrSessionNotFound when the session does not exist or is expired
+	Get(ctx context.Context, id string) (*Session, error)
+	// GetByUserId returns unexpired sessions of the user, the latest seen first
+	GetByUserId(ctx context.Context, userId int) ([]Session, error)
+	Touch(ctx context.Context, id string, lastSeenAt time.Time, ipAddress string) error
+	Delete(ctx context.Context, id string) error
+	DeleteByUserId(ctx context.Context, userId int) error
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// NewStore returns nil store for the "jwt" driver
+func NewStore(config configs.SessionConfig, db *sql.DB) (Store, error) {
+	switch config.Driver {
+	case "jwt":
+		return nil, nil
+	case "database":
+		return NewDatabaseStore(db), nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unsupported session driver %q", config.Driver)
+	}
+}
+
+// NewToken returns random cookie token of a new session
+func NewToken() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	return hex.EncodeToString(random), nil
+}
+
+// SessionId returns the id of the session stored for the cookie token
+func SessionId(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// Device describes browser and platform of the session from its user agent e.g. "Chrome on Windows",
+// the raw user agent is shown when neither is recognized
+func (session Session) Device() string {
+	userAgent := session.UserAgent
+	browser := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+	platform := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			platform = candidate.name
+			break
+		}
+	}
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	case userAgent != "":
+		return userAgent
+	default:
+		return "Unknown device"
+	}
+}
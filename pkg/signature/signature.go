@@ -0,0 +1,21 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+)
+
+// Sign returns url safe HMAC-SHA256 signature of the data using APP_KEY
+func Sign(data string) string {
+	mac := hmac.New(sha256.New, []byte(configs.Get().App.Key))
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature in constant time
+func Verify(data string, signature string) bool {
+	return hmac.Equal([]byte(Sign(data)), []byte(signature))
+}
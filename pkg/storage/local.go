@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+)
+
+// LocalStorage keeps files in a directory of the local filesystem
+type LocalStorage struct {
+	root string
+}
+
+func NewLocalStorage(config configs.StorageConfig) *LocalStorage {
+	return &LocalStorage{
+		root: config.LocalPath,
+	}
+}
+
+func (storage *LocalStorage) fullPath(name string) (string, error) {
+	cleaned, err := cleanPath(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storage.root, filepath.FromSlash(cleaned)), nil
+}
+
+// Put writes the content into temporary file first, so a failed upload never leaves a partial file
+func (storage *LocalStorage) Put(ctx context.Context, name string, content io.Reader, contentType string) error {
+	fullPath, err := storage.fullPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory of %s: %w", name, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to create file %s: %w", name, err)
+	}
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), fullPath); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", name, err)
+	}
+	return nil
+}
+
+func (storage *LocalStorage) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	fullPath, err := storage.fullPath(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", name, err)
+	}
+	return file, nil
+}
+
+// Delete removes the file, deleting a missing file is not an error
+func (storage *LocalStorage) Delete(ctx context.Context, name string) error {
+	fullPath, err := storage.fullPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file %s: %w", name, err)
+	}
+	return nil
+}
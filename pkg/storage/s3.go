@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+)
+
+// S3Storage keeps files in a bucket of S3-compatible service (AWS S3, MinIO, etc.),
+// requests are signed with AWS Signature Version 4
+type S3Storage struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	client    *http.Client
+}
+
+func NewS3Storage(config configs.StorageConfig) (*S3Storage, error) {
+	if config.S3Bucket == "" {
+		return nil, fmt.Errorf("storage bucket is required by s3 driver")
+	}
+	endpoint, err := url.Parse(config.S3Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid storage endpoint %q", config.S3Endpoint)
+	}
+	return &S3Storage{
+		endpoint:  endpoint,
+		region:    config.S3Region,
+		bucket:    config.S3Bucket,
+		accessKey: config.S3AccessKey,
+		secretKey: config.S3SecretKey,
+		pathStyle: config.S3PathStyle,
+		client:    &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// objectUrl returns address of the object, path style puts the bucket in the path instead of the host
+func (storage *S3Storage) objectUrl(name string) *url.URL {
+	objectUrl := *storage.endpoint
+	objectPath := strings.TrimSuffix(objectUrl.Path, "/")
+	if storage.pathStyle {
+		objectPath += "/" + storage.bucket
+	} else {
+		objectUrl.Host = storage.bucket + "." + objectUrl.Host
+	}
+	objectUrl.Path = objectPath + "/" + name
+	objectUrl.RawPath = ""
+	return &objectUrl
+}
+
+func (storage *S3Storage) do(ctx context.Context, method string, name string, body []byte, contentType string) (*http.Response, error) {
+	cleaned, err := cleanPath(name)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, method, storage.objectUrl(cleaned).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	signRequest(request, body, storage.accessKey, storage.secretKey, storage.region, time.Now())
+
+	response, err := storage.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request storage object %s: %w", name, err)
+	}
+	return response, nil
+}
+
+// responseError reads S3 error code of the failed response, e.g. NoSuchBucket or AccessDenied
+func responseError(action string, name string, response *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	code := string(message)
+	if start := strings.Index(code, "<Code>"); start >= 0 {
+		if end := strings.Index(code[start:], "</Code>"); end >= 0 {
+			code = code[start + len("<Code>") : start + end]
+		}
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("failed to %s storage object %s: %s: %w", action, name, code, fs.ErrNotExist)
+	}
+	return fmt.Errorf("failed to %s storage object %s: %s %s", action, name, response.Status, code)
+}
+
+// Put uploads the whole content in a single request, uploads are limited by validation so it is kept in memory
+func (storage *S3Storage) Put(ctx context.Context, name string, content io.Reader, contentType string) error {
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return fmt.Errorf("failed to read content of %s: %w", name, err)
+	}
+	response, err := storage.do(ctx, http.MethodPut, name, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return responseError("upload", name, response)
+	}
+	return nil
+}
+
+func (storage *S3Storage) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	response, err := storage.do(ctx, http.MethodGet, name, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		return nil, responseError("read", name, response)
+	}
+	return response.Body, nil
+}
+
+// Delete removes the object, S3 does not report deleting a missing object as an error
+func (storage *S3Storage) Delete(ctx context.Context, name string) error {
+	response, err := storage.do(ctx, http.MethodDelete, name, nil, "")
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK {
+		return responseError("delete", name, response)
+	}
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// signRequest adds AWS Signature Version 4 authorization header, the host, content type, range
+// and x-amz-* headers are signed
+func signRequest(request *http.Request, body []byte, accessKey string, secretKey string, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for key, values := range request.Header {
+		key = strings.ToLower(key)
+		if key == "content-type" || key == "range" || strings.HasPrefix(key, "x-amz-") {
+			headers[key] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for key := range headers {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, key := range names {
+		canonicalHeaders.WriteString(key + ":" + headers[key] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4" + secretKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature,
+	))
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/pkg/signature"
+)
+
+// FilesPath is the route prefix serving stored files
+const FilesPath = "/files/"
+
+// SignedUrl returns url of the stored file that can be opened without authentication until it expires,
+// the expiry is rounded up to the ttl window so the url stays the same (and cacheable) within the window.
+// Prepend APP_URL for urls leaving the application e.g. in emails.
+func SignedUrl(path string, ttl time.Duration) string {
+	window := int64(ttl.Seconds())
+	if window < 1 {
+		window = 1
+	}
+	expires := strconv.FormatInt((time.Now().Unix() / window + 2) * window, 10)
+	path = strings.TrimPrefix(path, "/")
+
+	return FilesPath + path + "?" + url.Values{
+		"expires": {expires},
+		"signature": {signature.Sign("file|" + path + "|" + expires)},
+	}.Encode()
+}
+
+// VerifySignedUrl checks the signature of the file url and that it is not expired yet
+func VerifySignedUrl(fileUrl *url.URL) bool {
+	path, ok := strings.CutPrefix(fileUrl.Path, FilesPath)
+	if !ok {
+		return false
+	}
+	query := fileUrl.Query()
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	return signature.Verify("file|" + path + "|" + query.Get("expires"), query.Get("signature"))
+}
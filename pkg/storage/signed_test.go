@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+	"github.com/anggadarkprince/crud-employee-go/pkg/signature"
+)
+
+func setAppKey(t *testing.T, key string) {
+	previous := configs.Configs
+	configs.Configs = &configs.Config{App: configs.AppConfig{Key: key}}
+	t.Cleanup(func() { configs.Configs = previous })
+}
+
+func TestSignedUrl(t *testing.T) {
+	setAppKey(t, "test-key")
+
+	signedUrl, err := url.Parse(SignedUrl("/avatars/2025/01/photo.png", time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signedUrl.Path != "/files/avatars/2025/01/photo.png" {
+		t.Errorf("path = %s, want /files/avatars/2025/01/photo.png", signedUrl.Path)
+	}
+	expires, err := strconv.ParseInt(signedUrl.Query().Get("expires"), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Expiry is rounded up to the window, it's between one and two windows from now
+	if now := time.Now().Unix(); expires <= now+3600 || expires > now+7200 {
+		t.Errorf("expires in %d seconds, want between 1 and 2 hours", expires-now)
+	}
+	if !VerifySignedUrl(signedUrl) {
+		t.Error("signed url is not verified")
+	}
+	if again := SignedUrl("avatars/2025/01/photo.png", time.Hour); again != signedUrl.String() {
+		t.Errorf("url changed within the window: %s, want %s", again, signedUrl)
+	}
+}
+
+func TestVerifySignedUrl(t *testing.T) {
+	setAppKey(t, "test-key")
+	valid, err := url.Parse(SignedUrl("documents/contract.pdf", time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name   string
+		modify func(fileUrl *url.URL)
+	}{
+		{name: "other file", modify: func(fileUrl *url.URL) { fileUrl.Path = "/files/documents/other.pdf" }},
+		{name: "path outside files", modify: func(fileUrl *url.URL) { fileUrl.Path = "/documents/contract.pdf" }},
+		{name: "extended expiry", modify: func(fileUrl *url.URL) {
+			query := fileUrl.Query()
+			query.Set("expires", strconv.FormatInt(time.Now().Add(24*time.Hour).Unix(), 10))
+			fileUrl.RawQuery = query.Encode()
+		}},
+		{name: "tampered signature", modify: func(fileUrl *url.URL) {
+			query := fileUrl.Query()
+			query.Set("signature", query.Get("signature")[1:]+"A")
+			fileUrl.RawQuery = query.Encode()
+		}},
+		{name: "missing signature", modify: func(fileUrl *url.URL) {
+			query := fileUrl.Query()
+			query.Del("signature")
+			fileUrl.RawQuery = query.Encode()
+		}},
+		{name: "expired", modify: func(fileUrl *url.URL) {
+			fileUrl.RawQuery = url.Values{
+				"expires":   {past},
+				"signature": {signature.Sign("file|documents/contract.pdf|" + past)},
+			}.Encode()
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileUrl := *valid
+			test.modify(&fileUrl)
+			if VerifySignedUrl(&fileUrl) {
+				t.Errorf("url %s is verified", fileUrl.String())
+			}
+		})
+	}
+
+	t.Run("signed by another key", func(t *testing.T) {
+		setAppKey(t, "other-key")
+		if VerifySignedUrl(valid) {
+			t.Error("url signed by another key is verified")
+		}
+	})
+}
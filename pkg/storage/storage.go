@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+)
+
+// Storage keeps uploaded files, paths are slash separated and relative to the storage root
+// (e.g. avatars/2025/01/3f2a...c1.png), drivers are selected by STORAGE_DRIVER.
+// Reading a missing file returns an error wrapping fs.ErrNotExist.
+// Files are never public, they are served by the application through signed urls (see SignedUrl).
+type Storage interface {
+	Put(ctx context.Context, path string, content io.Reader, contentType string) error
+	Get(ctx context.Context, path string) (io.ReadCloser, error)
+	Delete(ctx context.Context, path string) error
+}
+
+func New(config configs.StorageConfig) (Storage, error) {
+	switch config.Driver {
+	case "local":
+		return NewLocalStorage(config), nil
+	case "s3":
+		return NewS3Storage(config)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver %q", config.Driver)
+	}
+}
+
+// RandomName returns unique path in the directory partitioned by month, only the extension
+// of the client filename is kept so uploads never overwrite each other
+func RandomName(directory string, filename string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate file name: %w", err)
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	return path.Join(directory, time.Now().Format("2006/01"), hex.EncodeToString(random) + ext), nil
+}
+
+// cleanPath rejects path escaping the storage root e.g. ../../etc/passwd
+func cleanPath(name string) (string, error) {
+	cleaned := path.Clean(strings.TrimPrefix(name, "/"))
+	if name == "" || cleaned == "." || !filepath.IsLocal(filepath.FromSlash(cleaned)) {
+		return "", fmt.Errorf("invalid storage path %q", name)
+	}
+	return cleaned, nil
+}
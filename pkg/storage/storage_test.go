@@ -0,0 +1,34 @@
+package storage
+
+import "testing"
+
+func TestCleanPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "avatars/2025/01/photo.png", want: "avatars/2025/01/photo.png"},
+		{name: "/avatars/photo.png", want: "avatars/photo.png"},
+		{name: "avatars/./2025//photo.png", want: "avatars/2025/photo.png"},
+		{name: "avatars/../documents/file.pdf", want: "documents/file.pdf"},
+		{name: "../etc/passwd", wantErr: true},
+		{name: "avatars/../../etc/passwd", wantErr: true},
+		{name: "/../etc/passwd", wantErr: true},
+		{name: "..", wantErr: true},
+		{name: ".", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := cleanPath(test.name)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("cleanPath(%q) = %q, want error", test.name, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("cleanPath(%q) = %q, %v, want %q", test.name, got, err, test.want)
+		}
+	}
+}
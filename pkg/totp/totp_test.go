@@ -0,0 +1,77 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed of RFC 6238 Appendix B, ASCII "12345678901234567890"
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+// TestCodeAt uses the SHA1 test vectors of RFC 6238 Appendix B, the RFC shows 8 digits
+// and a 6 digit code is the last 6 of them
+func TestCodeAt(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "94287082"},
+		{unix: 1111111109, want: "07081804"},
+		{unix: 1111111111, want: "14050471"},
+		{unix: 1234567890, want: "89005924"},
+		{unix: 2000000000, want: "69279037"},
+		{unix: 20000000000, want: "65353130"},
+	}
+	for _, test := range tests {
+		got, err := CodeAt(rfcSecret, Step(time.Unix(test.unix, 0)))
+		if err != nil || got != test.want[8-Digits:] {
+			t.Errorf("code at %d = %s, %v, want %s", test.unix, got, err, test.want[8-Digits:])
+		}
+	}
+}
+
+func TestCodeAtInvalidSecret(t *testing.T) {
+	if _, err := CodeAt("not base32!", 1); err == nil {
+		t.Error("invalid secret is accepted")
+	}
+	// Secret typed by the user may be lowercase or surrounded by spaces
+	lower, err := CodeAt(" "+strings.ToLower(rfcSecret)+" ", 1)
+	if want, _ := CodeAt(rfcSecret, 1); err != nil || lower != want {
+		t.Errorf("lowercase secret code = %s, %v, want %s", lower, err, want)
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+	code := func(step int64) string {
+		code, err := CodeAt(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOk   bool
+	}{
+		{name: "current step", code: code(current), wantStep: current, wantOk: true},
+		{name: "previous step", code: code(current - 1), wantStep: current - 1, wantOk: true},
+		{name: "next step", code: code(current + 1), wantStep: current + 1, wantOk: true},
+		{name: "outside skew before", code: code(current - Skew - 1)},
+		{name: "outside skew after", code: code(current + Skew + 1)},
+		{name: "spaces are ignored", code: " " + code(current)[:3] + " " + code(current)[3:] + " ", wantStep: current, wantOk: true},
+		{name: "8 digits of RFC", code: "14050471"},
+		{name: "empty", code: ""},
+	}
+	for _, test := range tests {
+		step, ok := Validate(rfcSecret, test.code, now)
+		if ok != test.wantOk || step != test.wantStep {
+			t.Errorf("%s: Validate(%q) = %d, %v, want %d, %v", test.name, test.code, step, ok, test.wantStep, test.wantOk)
+		}
+	}
+}
@@ -2,12 +2,15 @@ package validation
 
 import (
 	"fmt"
+	"io"
 	"mime/multipart"
-	"path/filepath"
+	"net/http"
 	"reflect"
 	"regexp"
 	"strings"
 
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/imaging"
 	"github.com/anggadarkprince/crud-employee-go/utilities"
 	english "github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
@@ -18,6 +21,22 @@ import (
 var Validator *validator.Validate
 var Trans ut.Translator
 
+// MaxAvatarSize is the maximum size of uploaded avatar in bytes
+const MaxAvatarSize = 2 << 20
+
+// MaxDocumentSize is the maximum size of each uploaded employee document in bytes
+const MaxDocumentSize = 10 << 20
+
+// MaxDocumentFiles is how many employee documents can be uploaded at once, keep it in sync with dto.StoreEmployeeDocumentsRequest
+const MaxDocumentFiles = 10
+
+// DocumentMimeTypes is the allow-list of employee document types sniffed from the content, mapped to file extension
+var DocumentMimeTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/png": ".png",
+	"image/jpeg": ".jpg",
+}
+
 func Init() {
 	eng := english.New()
 	uni := ut.New(eng, eng)
@@ -46,6 +65,12 @@ func Init() {
 		g := fl.Field().String()
 		return g == "Male" || g == "Female"
 	})
+	Validator.RegisterTranslation("gender", Trans, func(ut ut.Translator) error {
+		return ut.Add("gender", "{0} must be Male or Female", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("gender", fe.Field())
+		return t
+	})
 
 	Validator.RegisterValidation("username", func(fl validator.FieldLevel) bool {
 		username := fl.Field().String()
@@ -54,23 +79,129 @@ func Init() {
 		return matched
 	})
 	
+	Validator.RegisterValidation("allowance_code", func(fl validator.FieldLevel) bool {
+		matched, _ := regexp.MatchString(`^[A-Z0-9_]+$`, fl.Field().String())
+		return matched
+	})
+	Validator.RegisterTranslation("allowance_code", Trans, func(ut ut.Translator) error {
+		return ut.Add("allowance_code", "{0} may only contain uppercase letters, numbers and underscore", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("allowance_code", fe.Field())
+		return t
+	})
+	
+	Validator.RegisterValidation("employee_status", func(fl validator.FieldLevel) bool {
+		_, ok := models.GetEmployeeStatus(fl.Field().String())
+		return ok
+	})
+	Validator.RegisterTranslation("employee_status", Trans, func(ut ut.Translator) error {
+		return ut.Add("employee_status", "{0} is not a valid employee status", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("employee_status", fe.Field())
+		return t
+	})
+	
+	// Avatar is checked by magic bytes and dimensions of the content, extension and
+	// content type from the client are never trusted
 	Validator.RegisterValidation("avatar", func(fl validator.FieldLevel) bool {
-		fileHeader, ok := fl.Field().Interface().(*multipart.FileHeader)
-		if !ok || fileHeader == nil {
+		// Pointer field is passed dereferenced
+		var fileHeader *multipart.FileHeader
+		switch value := fl.Field().Interface().(type) {
+		case *multipart.FileHeader:
+			fileHeader = value
+		case multipart.FileHeader:
+			fileHeader = &value
+		}
+		if fileHeader == nil {
 			return true // optional file → valid
 		}
+		if fileHeader.Size > MaxAvatarSize {
+			return false
+		}
 
-		// 1. Check size (e.g. max 2MB)
-		if fileHeader.Size > 2<<20 {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return false
+		}
+		defer file.Close()
+		content, err := io.ReadAll(io.LimitReader(file, MaxAvatarSize))
+		if err != nil {
 			return false
 		}
+		_, err = imaging.Check(content)
+		return err == nil
+	})
+	Validator.RegisterTranslation("avatar", Trans, func(ut ut.Translator) error {
+		return ut.Add("avatar", fmt.Sprintf("{0} must be a PNG or JPEG image up to %dMB and %dx%d pixels", MaxAvatarSize >> 20, imaging.MaxDimension, imaging.MaxDimension), true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("avatar", fe.Field())
+		return t
+	})
 
-		// 2. Check extension (optional)
-		ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-		allowed := map[string]bool{".jpg": true, ".jpeg": true, ".png": true}
-		
-		return allowed[ext]
+	// Every uploaded document must be within the size limit and of allowed type,
+	// the type is sniffed from the content like the avatar
+	Validator.RegisterValidation("document", func(fl validator.FieldLevel) bool {
+		var fileHeaders []*multipart.FileHeader
+		switch value := fl.Field().Interface().(type) {
+		case []*multipart.FileHeader:
+			fileHeaders = value
+		case *multipart.FileHeader:
+			fileHeaders = []*multipart.FileHeader{value}
+		case multipart.FileHeader:
+			fileHeaders = []*multipart.FileHeader{&value}
+		}
+		for _, fileHeader := range fileHeaders {
+			if _, err := DocumentMimeType(fileHeader); err != nil {
+				return false
+			}
+		}
+		return true
+	})
+	Validator.RegisterTranslation("document", Trans, func(ut ut.Translator) error {
+		return ut.Add("document", fmt.Sprintf("{0} must be PDF, PNG or JPEG files up to %dMB each", MaxDocumentSize >> 20), true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("document", fe.Field())
+		return t
 	})
+
+	Validator.RegisterValidation("document_type", func(fl validator.FieldLevel) bool {
+		_, ok := models.GetEmployeeDocumentType(fl.Field().String())
+		return ok
+	})
+	Validator.RegisterTranslation("document_type", Trans, func(ut ut.Translator) error {
+		return ut.Add("document_type", "{0} is not a valid document type", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("document_type", fe.Field())
+		return t
+	})
+}
+
+// DocumentMimeType sniffs type of the uploaded document, it fails when the file is too large
+// or the type is not in DocumentMimeTypes
+func DocumentMimeType(fileHeader *multipart.FileHeader) (string, error) {
+	if fileHeader == nil || fileHeader.Size == 0 {
+		return "", fmt.Errorf("document is empty")
+	}
+	if fileHeader.Size > MaxDocumentSize {
+		return "", fmt.Errorf("document %s is larger than %dMB", fileHeader.Filename, MaxDocumentSize >> 20)
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	// DetectContentType only considers the first 512 bytes
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	mimeType := strings.SplitN(http.DetectContentType(head[:n]), ";", 2)[0]
+	if _, ok := DocumentMimeTypes[mimeType]; !ok {
+		return "", fmt.Errorf("document %s is not PDF, PNG or JPEG file", fileHeader.Filename)
+	}
+	return mimeType, nil
 }
 
 func FormatValidationErrors(err error) map[string]string {
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type AllowanceTypeRepository struct {
+	db database.Transaction
+}
+
+func NewAllowanceTypeRepository(db *sql.DB) *AllowanceTypeRepository {
+	return &AllowanceTypeRepository{db: db}
+}
+
+func (r *AllowanceTypeRepository) WithTx(tx *sql.Tx) *AllowanceTypeRepository {
+	return &AllowanceTypeRepository{
+		db: tx,
+	}
+}
+
+// GetAll returns the catalog ordered by name, activeOnly hides deactivated types
+func (repository *AllowanceTypeRepository) GetAll(ctx context.Context, activeOnly bool) (*[]models.AllowanceType, error) {
+	query := `
+		SELECT
+			allowance_types.id, code, name, default_amount, is_taxable, is_active, created_at, updated_at,
+			COALESCE(allowances.total, 0) AS total_employee
+		FROM allowance_types
+		LEFT JOIN (
+			SELECT allowance_type_id, COUNT(*) AS total FROM employee_allowances GROUP BY allowance_type_id
+		) AS allowances ON allowances.allowance_type_id = allowance_types.id
+	`
+	if activeOnly {
+		query += ` WHERE is_active = 1`
+	}
+	query += ` ORDER BY name`
+
+	rows, err := repository.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Errorf("failed to query allowance types: %w", err)
+	}
+	defer rows.Close()
+
+	allowanceTypes := []models.AllowanceType{}
+	for rows.Next() {
+		var allowanceType models.AllowanceType
+		err = rows.Scan(
+			&allowanceType.Id,
+			&allowanceType.Code,
+			&allowanceType.Name,
+			&allowanceType.DefaultAmount,
+			&allowanceType.IsTaxable,
+			&allowanceType.IsActive,
+			&allowanceType.CreatedAt,
+			&allowanceType.UpdatedAt,
+			&allowanceType.TotalEmployee,
+		)
+		if err != nil {
+			return nil, errors.Errorf("failed to get allowance type rows: %w", err)
+		}
+		allowanceTypes = append(allowanceTypes, allowanceType)
+	}
+	return &allowanceTypes, nil
+}
+
+func (repository *AllowanceTypeRepository) GetById(ctx context.Context, id int) (*models.AllowanceType, error) {
+	return repository.findBy(ctx, "id", id)
+}
+
+func (repository *AllowanceTypeRepository) GetByCode(ctx context.Context, code string) (*models.AllowanceType, error) {
+	return repository.findBy(ctx, "code", code)
+}
+
+func (repository *AllowanceTypeRepository) findBy(ctx context.Context, column string, value any) (*models.AllowanceType, error) {
+	query := `
+		SELECT id, code, name, default_amount, is_taxable, is_active, created_at, updated_at
+		FROM allowance_types WHERE ` + column + ` = ?`
+	var allowanceType models.AllowanceType
+	err := repository.db.QueryRowContext(ctx, query, value).Scan(
+		&allowanceType.Id,
+		&allowanceType.Code,
+		&allowanceType.Name,
+		&allowanceType.DefaultAmount,
+		&allowanceType.IsTaxable,
+		&allowanceType.IsActive,
+		&allowanceType.CreatedAt,
+		&allowanceType.UpdatedAt,
+	)
+	if err != nil {
+		return nil, errors.Errorf("allowance type not found %s=%v: %w", column, value, err)
+	}
+	return &allowanceType, nil
+}
+
+func (repository *AllowanceTypeRepository) Store(ctx context.Context, allowanceType *models.AllowanceType) (*models.AllowanceType, error) {
+	query := `
+		INSERT INTO allowance_types(code, name, default_amount, is_taxable, is_active)
+		VALUES(?, ?, ?, ?, ?)
+	`
+	result, err := repository.db.ExecContext(
+		ctx,
+		query,
+		allowanceType.Code,
+		allowanceType.Name,
+		allowanceType.DefaultAmount,
+		allowanceType.IsTaxable,
+		allowanceType.IsActive,
+	)
+	if err != nil {
+		return nil, errors.Errorf("failed to store allowance type: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, errors.Errorf("failed to get last id: %w", err)
+	}
+	return repository.GetById(ctx, int(id))
+}
+
+func (repository *AllowanceTypeRepository) Update(ctx context.Context, allowanceType *models.AllowanceType) (*models.AllowanceType, error) {
+	query := `
+		UPDATE allowance_types
+		SET code = ?, name = ?, default_amount = ?, is_taxable = ?, is_active = ?
+		WHERE id = ?
+	`
+	_, err := repository.db.ExecContext(
+		ctx,
+		query,
+		allowanceType.Code,
+		allowanceType.Name,
+		allowanceType.DefaultAmount,
+		allowanceType.IsTaxable,
+		allowanceType.IsActive,
+		allowanceType.Id,
+	)
+	if err != nil {
+		return nil, errors.Errorf("failed to update allowance type id=%d: %w", allowanceType.Id, err)
+	}
+	return repository.GetById(ctx, allowanceType.Id)
+}
+
+// CountUsage returns number of employee allowances referencing the type, including employees in trash
+func (repository *AllowanceTypeRepository) CountUsage(ctx context.Context, id int) (int, error) {
+	var total int
+	err := repository.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM employee_allowances WHERE allowance_type_id = ?`, id).Scan(&total)
+	if err != nil {
+		return 0, errors.Errorf("failed to count usage of allowance type id=%d: %w", id, err)
+	}
+	return total, nil
+}
+
+func (repository *AllowanceTypeRepository) Destroy(ctx context.Context, id int) (int64, error) {
+	result, err := repository.db.ExecContext(ctx, `DELETE FROM allowance_types WHERE id = ?`, id)
+	if err != nil {
+		return 0, errors.Errorf("failed to delete allowance type id=%d: %w", id, err)
+	}
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowAffected, nil
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type AuditLogRepository struct {
+	db database.Transaction
+}
+
+func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) WithTx(tx *sql.Tx) *AuditLogRepository {
+	return &AuditLogRepository{
+		db: tx,
+	}
+}
+
+func (repository *AuditLogRepository) Create(ctx context.Context, auditLog *models.AuditLog) error {
+	query := `
+		INSERT INTO audit_logs(user_id, action, entity_type, entity_id, old_values, new_values, ip_address, user_agent)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := repository.db.ExecContext(
+		ctx,
+		query,
+		auditLog.UserId,
+		auditLog.Action,
+		auditLog.EntityType,
+		auditLog.EntityId,
+		auditLog.OldValues,
+		auditLog.NewValues,
+		auditLog.IpAddress,
+		auditLog.UserAgent,
+	)
+	if err != nil {
+		return errors.Errorf("failed to store audit log of %s id=%d: %w", auditLog.EntityType, auditLog.EntityId, err)
+	}
+	return nil
+}
+
+func (repository *AuditLogRepository) buildFilterConditions(filter *dto.AuditLogFilter) (string, []any) {
+	conditions := []string{"1 = 1"}
+	args := []any{}
+
+	if filter.UserId > 0 {
+		conditions = append(conditions, "audit_logs.user_id = ?")
+		args = append(args, filter.UserId)
+	}
+	if filter.EntityType != "" {
+		conditions = append(conditions, "audit_logs.entity_type = ?")
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityId > 0 {
+		conditions = append(conditions, "audit_logs.entity_id = ?")
+		args = append(args, filter.EntityId)
+	}
+	if filter.DateFrom != "" {
+		conditions = append(conditions, "audit_logs.created_at >= ?")
+		args = append(args, filter.DateFrom)
+	}
+	if filter.DateTo != "" {
+		// Date to is inclusive, compare with the start of the next day
+		conditions = append(conditions, "audit_logs.created_at < DATE_ADD(?, INTERVAL 1 DAY)")
+		args = append(args, filter.DateTo)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func (repository *AuditLogRepository) Paginate(ctx context.Context, filter *dto.AuditLogFilter) (*[]models.AuditLog, int, error) {
+	conditions, args := repository.buildFilterConditions(filter)
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM audit_logs WHERE " + conditions
+	err := repository.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, errors.Errorf("failed to count audit logs: %w", err)
+	}
+
+	query := `
+		SELECT
+			audit_logs.id, audit_logs.user_id, users.name, audit_logs.action, audit_logs.entity_type, audit_logs.entity_id,
+			audit_logs.old_values, audit_logs.new_values, audit_logs.ip_address, audit_logs.user_agent, audit_logs.created_at
+		FROM audit_logs
+		LEFT JOIN users ON users.id = audit_logs.user_id
+		WHERE ` + conditions + `
+		ORDER BY audit_logs.id DESC
+		LIMIT ? OFFSET ?
+	`
+	rows, err := repository.db.QueryContext(ctx, query, append(args, filter.PerPage, filter.Offset())...)
+	if err != nil {
+		return nil, 0, errors.Errorf("failed to query audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	auditLogs := []models.AuditLog{}
+	for rows.Next() {
+		var auditLog models.AuditLog
+		err = rows.Scan(
+			&auditLog.Id,
+			&auditLog.UserId,
+			&auditLog.UserName,
+			&auditLog.Action,
+			&auditLog.EntityType,
+			&auditLog.EntityId,
+			&auditLog.OldValues,
+			&auditLog.NewValues,
+			&auditLog.IpAddress,
+			&auditLog.UserAgent,
+			&auditLog.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, errors.Errorf("failed to get audit log rows: %w", err)
+		}
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	return &auditLogs, total, nil
+}
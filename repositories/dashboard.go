@@ -3,16 +3,14 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/anggadarkprince/crud-employee-go/models"
 	"gitlab.com/tozd/go/errors"
 )
 
-type DashboardStatistics struct {
-    Total    int
-    Active   int
-    Inactive int
-    Pending  int
-}
+// workforceCondition limits employees to the current workforce, terminated employees are excluded
+const workforceCondition = `employees.deleted_at IS NULL AND COALESCE(employees.status, '') <> '` + models.EmployeeStatusTerminated + `'`
 
 type DashboardRepository struct {
 	db *sql.DB
@@ -22,24 +20,208 @@ func NewDashboardRepository(db *sql.DB) *DashboardRepository {
 	return &DashboardRepository{db: db}
 }
 
-func (repository *DashboardRepository) GetStatistics(ctx context.Context) (*DashboardStatistics, error) {
-	var stats DashboardStatistics
+// GetStatistics counts employees of each configured status, employee with status outside
+// the lifecycle (legacy data) is counted in total only
+func (repository *DashboardRepository) GetStatistics(ctx context.Context) (*models.DashboardStatistics, error) {
+	var stats models.DashboardStatistics
 
 	query := `
-		SELECT
-			COUNT(*) AS total_employees,
-			COUNT(IF(status = 'ACTIVE', 1, NULL)) AS active_employees,
-			COUNT(IF(status = 'INACTIVE', 1, NULL)) AS inactive_employees,
-			COUNT(IF(status = 'PENDING', 1, NULL)) AS pending_employees
+		SELECT COALESCE(status, '') AS status, COUNT(*) AS total_employees
 		FROM employees
+		WHERE deleted_at IS NULL
+		GROUP BY status
 	`
-	err := repository.db.
-		QueryRowContext(ctx, query).
-		Scan(&stats.Total, &stats.Active, &stats.Inactive, &stats.Pending)
-	
+	rows, err := repository.db.QueryContext(ctx, query)
 	if err != nil {
         return nil, errors.Errorf("failed to query dashboard statistic: %w", err)
     }
+	defer rows.Close()
+
+	totals := map[string]int{}
+	for rows.Next() {
+		var status string
+		var total int
+		if err := rows.Scan(&status, &total); err != nil {
+			return nil, errors.Errorf("failed to scan dashboard statistic: %w", err)
+		}
+		totals[status] += total
+		stats.Total += total
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Errorf("failed to query dashboard statistic: %w", err)
+	}
+
+	for _, status := range models.EmployeeStatuses {
+		stats.Statuses = append(stats.Statuses, models.DashboardStatusCount{Status: status, Total: totals[status.Code]})
+	}
 
 	return &stats, nil
-}
\ No newline at end of file
+}
+
+func (repository *DashboardRepository) monthlyCounts(ctx context.Context, query string, args ...any) (map[string]int, error) {
+	rows, err := repository.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Errorf("failed to query monthly statistic: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var month string
+		var total int
+		if err := rows.Scan(&month, &total); err != nil {
+			return nil, errors.Errorf("failed to scan monthly statistic: %w", err)
+		}
+		counts[month] = total
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Errorf("failed to query monthly statistic: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetMonthlyHires counts hired employees per month (YYYY-MM) within the date range,
+// employee without hired date is counted on the created date
+func (repository *DashboardRepository) GetMonthlyHires(ctx context.Context, from time.Time, to time.Time) (map[string]int, error) {
+	query := `
+		SELECT DATE_FORMAT(COALESCE(hired_date, DATE(created_at)), '%Y-%m') AS month, COUNT(*)
+		FROM employees
+		WHERE deleted_at IS NULL
+			AND COALESCE(hired_date, DATE(created_at)) >= ?
+			AND COALESCE(hired_date, DATE(created_at)) < ?
+		GROUP BY DATE_FORMAT(COALESCE(hired_date, DATE(created_at)), '%Y-%m')
+	`
+	return repository.monthlyCounts(ctx, query, from, to)
+}
+
+// GetMonthlyExits counts employees terminated per month (YYYY-MM) within the date range
+func (repository *DashboardRepository) GetMonthlyExits(ctx context.Context, from time.Time, to time.Time) (map[string]int, error) {
+	query := `
+		SELECT DATE_FORMAT(employee_status_histories.effective_date, '%Y-%m') AS month, COUNT(*)
+		FROM employee_status_histories
+		INNER JOIN employees ON employees.id = employee_status_histories.employee_id
+		WHERE employees.deleted_at IS NULL
+			AND employee_status_histories.to_status = ?
+			AND employee_status_histories.effective_date >= ?
+			AND employee_status_histories.effective_date < ?
+		GROUP BY DATE_FORMAT(employee_status_histories.effective_date, '%Y-%m')
+	`
+	return repository.monthlyCounts(ctx, query, models.EmployeeStatusTerminated, from, to)
+}
+
+// GetHeadcountAt counts employees that are hired and not yet terminated before the date
+func (repository *DashboardRepository) GetHeadcountAt(ctx context.Context, date time.Time) (int, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM employees
+				WHERE deleted_at IS NULL AND COALESCE(hired_date, DATE(created_at)) < ?)
+			-
+			(SELECT COUNT(*) FROM employee_status_histories
+				INNER JOIN employees ON employees.id = employee_status_histories.employee_id
+				WHERE employees.deleted_at IS NULL
+					AND employee_status_histories.to_status = ?
+					AND employee_status_histories.effective_date < ?)
+	`
+	var headcount int
+	err := repository.db.
+		QueryRowContext(ctx, query, date, models.EmployeeStatusTerminated, date).
+		Scan(&headcount)
+	if err != nil {
+		return 0, errors.Errorf("failed to query headcount: %w", err)
+	}
+
+	return headcount, nil
+}
+
+func (repository *DashboardRepository) distributions(ctx context.Context, query string) ([]models.DashboardDistribution, error) {
+	rows, err := repository.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Errorf("failed to query distribution: %w", err)
+	}
+	defer rows.Close()
+
+	distributions := []models.DashboardDistribution{}
+	for rows.Next() {
+		var distribution models.DashboardDistribution
+		if err := rows.Scan(&distribution.Key, &distribution.Label, &distribution.Total); err != nil {
+			return nil, errors.Errorf("failed to scan distribution: %w", err)
+		}
+		distributions = append(distributions, distribution)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Errorf("failed to query distribution: %w", err)
+	}
+
+	return distributions, nil
+}
+
+// GetGenderDistribution counts the current workforce by gender
+func (repository *DashboardRepository) GetGenderDistribution(ctx context.Context) ([]models.DashboardDistribution, error) {
+	query := `
+		SELECT COALESCE(gender, ''), COALESCE(gender, 'Unknown'), COUNT(*)
+		FROM employees
+		WHERE ` + workforceCondition + `
+		GROUP BY gender
+		ORDER BY COUNT(*) DESC
+	`
+	return repository.distributions(ctx, query)
+}
+
+// GetAllowanceTypeDistribution counts employees of the current workforce receiving each allowance type
+func (repository *DashboardRepository) GetAllowanceTypeDistribution(ctx context.Context) ([]models.DashboardDistribution, error) {
+	query := `
+		SELECT allowance_types.code, allowance_types.name, COUNT(DISTINCT employees.id)
+		FROM allowance_types
+		INNER JOIN employee_allowances ON employee_allowances.allowance_type_id = allowance_types.id
+		INNER JOIN employees ON employees.id = employee_allowances.employee_id
+		WHERE ` + workforceCondition + `
+		GROUP BY allowance_types.id, allowance_types.code, allowance_types.name
+		ORDER BY COUNT(DISTINCT employees.id) DESC, allowance_types.name
+	`
+	return repository.distributions(ctx, query)
+}
+
+// GetAverageTenureDays returns average days since hired of the current workforce
+func (repository *DashboardRepository) GetAverageTenureDays(ctx context.Context, date time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(AVG(DATEDIFF(?, employees.hired_date)), 0)
+		FROM employees
+		WHERE ` + workforceCondition + ` AND employees.hired_date IS NOT NULL AND employees.hired_date <= ?
+	`
+	var days float64
+	err := repository.db.QueryRowContext(ctx, query, date, date).Scan(&days)
+	if err != nil {
+		return 0, errors.Errorf("failed to query average tenure: %w", err)
+	}
+
+	return days, nil
+}
+
+// GetHiredEmployees returns the current workforce hired before the date
+func (repository *DashboardRepository) GetHiredEmployees(ctx context.Context, before time.Time) ([]models.Employee, error) {
+	query := `
+		SELECT employees.id, employees.name, employees.hired_date
+		FROM employees
+		WHERE ` + workforceCondition + ` AND employees.hired_date IS NOT NULL AND employees.hired_date < ?
+	`
+	rows, err := repository.db.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, errors.Errorf("failed to query hired employees: %w", err)
+	}
+	defer rows.Close()
+
+	employees := []models.Employee{}
+	for rows.Next() {
+		var employee models.Employee
+		if err := rows.Scan(&employee.Id, &employee.Name, &employee.HiredDate); err != nil {
+			return nil, errors.Errorf("failed to scan hired employee: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Errorf("failed to query hired employees: %w", err)
+	}
+
+	return employees, nil
+}
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type DepartmentRepository struct {
+	db database.Transaction
+}
+
+func NewDepartmentRepository(db *sql.DB) *DepartmentRepository {
+	return &DepartmentRepository{db: db}
+}
+
+func (r *DepartmentRepository) WithTx(tx *sql.Tx) *DepartmentRepository {
+	return &DepartmentRepository{
+		db: tx,
+	}
+}
+
+// GetAll returns departments ordered by name with number of active employees (trash excluded)
+func (repository *DepartmentRepository) GetAll(ctx context.Context) (*[]models.Department, error) {
+	query := `
+		SELECT
+			departments.id, departments.parent_id, parents.name AS parent_name,
+			departments.name, departments.description, departments.created_at, departments.updated_at,
+			COALESCE(employees.total, 0) AS total_employee
+		FROM departments
+		LEFT JOIN departments AS parents ON parents.id = departments.parent_id
+		LEFT JOIN (
+			SELECT department_id, COUNT(*) AS total FROM employees
+			WHERE deleted_at IS NULL
+			GROUP BY department_id
+		) AS employees ON employees.department_id = departments.id
+		ORDER BY departments.name
+	`
+	rows, err := repository.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Errorf("failed to query departments: %w", err)
+	}
+	defer rows.Close()
+
+	departments := []models.Department{}
+	for rows.Next() {
+		var department models.Department
+		err = rows.Scan(
+			&department.Id,
+			&department.ParentId,
+			&department.ParentName,
+			&department.Name,
+			&department.Description,
+			&department.CreatedAt,
+			&department.UpdatedAt,
+			&department.TotalEmployee,
+		)
+		if err != nil {
+			return nil, errors.Errorf("failed to get department rows: %w", err)
+		}
+		departments = append(departments, department)
+	}
+	return &departments, nil
+}
+
+func (repository *DepartmentRepository) GetById(ctx context.Context, id int) (*models.Department, error) {
+	return repository.findBy(ctx, "departments.id", id)
+}
+
+func (repository *DepartmentRepository) GetByName(ctx context.Context, name string) (*models.Department, error) {
+	return repository.findBy(ctx, "departments.name", name)
+}
+
+func (repository *DepartmentRepository) findBy(ctx context.Context, column string, value any) (*models.Department, error) {
+	query := `
+		SELECT
+			departments.id, departments.parent_id, parents.name AS parent_name,
+			departments.name, departments.description, departments.created_at, departments.updated_at
+		FROM departments
+		LEFT JOIN departments AS parents ON parents.id = departments.parent_id
+		WHERE ` + column + ` = ?`
+	var department models.Department
+	err := repository.db.QueryRowContext(ctx, query, value).Scan(
+		&department.Id,
+		&department.ParentId,
+		&department.ParentName,
+		&department.Name,
+		&department.Description,
+		&department.CreatedAt,
+		&department.UpdatedAt,
+	)
+	if err != nil {
+		return nil, errors.Errorf("department not found %s=%v: %w", column, value, err)
+	}
+	return &department, nil
+}
+
+func (repository *DepartmentRepository) Store(ctx context.Context, department *models.Department) (*models.Department, error) {
+	query := `INSERT INTO departments(parent_id, name, description) VALUES(?, ?, ?)`
+	result, err := repository.db.ExecContext(ctx, query, department.ParentId, department.Name, department.Description)
+	if err != nil {
+		return nil, errors.Errorf("failed to store department: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, errors.Errorf("failed to get last id: %w", err)
+	}
+	return repository.GetById(ctx, int(id))
+}
+
+func (repository *DepartmentRepository) Update(ctx context.Context, department *models.Department) (*models.Department, error) {
+	query := `UPDATE departments SET parent_id = ?, name = ?, description = ? WHERE id = ?`
+	_, err := repository.db.ExecContext(ctx, query, department.ParentId, department.Name, department.Description, department.Id)
+	if err != nil {
+		return nil, errors.Errorf("failed to update department id=%d: %w", department.Id, err)
+	}
+	return repository.GetById(ctx, department.Id)
+}
+
+// GetParentIdForUpdate must run in a transaction, the department row stays locked until it commits
+// so the parent read by the caller can't be changed by another request meanwhile
+func (repository *DepartmentRepository) GetParentIdForUpdate(ctx context.Context, id int) (sql.NullInt64, error) {
+	var parentId sql.NullInt64
+	err := repository.db.QueryRowContext(ctx, `SELECT parent_id FROM departments WHERE id = ? FOR UPDATE`, id).Scan(&parentId)
+	if err != nil {
+		return sql.NullInt64{}, errors.Errorf("department not found id=%d: %w", id, err)
+	}
+	return parentId, nil
+}
+
+// CountChildren returns number of departments directly under the department
+func (repository *DepartmentRepository) CountChildren(ctx context.Context, id int) (int, error) {
+	var total int
+	err := repository.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM departments WHERE parent_id = ?`, id).Scan(&total)
+	if err != nil {
+		return 0, errors.Errorf("failed to count children of department id=%d: %w", id, err)
+	}
+	return total, nil
+}
+
+// CountEmployees returns number of employees in the department, including employees in trash
+func (repository *DepartmentRepository) CountEmployees(ctx context.Context, id int) (int, error) {
+	var total int
+	err := repository.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM employees WHERE department_id = ?`, id).Scan(&total)
+	if err != nil {
+		return 0, errors.Errorf("failed to count employees of department id=%d: %w", id, err)
+	}
+	return total, nil
+}
+
+func (repository *DepartmentRepository) Destroy(ctx context.Context, id int) (int64, error) {
+	result, err := repository.db.ExecContext(ctx, `DELETE FROM departments WHERE id = ?`, id)
+	if err != nil {
+		return 0, errors.Errorf("failed to delete department id=%d: %w", id, err)
+	}
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowAffected, nil
+}
@@ -3,10 +3,13 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 
 	"gitlab.com/tozd/go/errors"
 
 	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/dto"
 	"github.com/anggadarkprince/crud-employee-go/models"
 )
 
@@ -24,22 +27,123 @@ func (r *EmployeeRepository) WithTx(tx *sql.Tx) *EmployeeRepository {
     }
 }
 
-func (repository *EmployeeRepository) GetAll(ctx context.Context) (*[]models.Employee, error) {
+// Sortable columns of employee list, key is the value accepted from query param
+var employeeSortColumns = map[string]string{
+	"id": "employees.id",
+	"name": "employees.name",
+	"email": "employees.email",
+	"gender": "employees.gender",
+	"hired_date": "employees.hired_date",
+	"status": "employees.status",
+	"total_allowance": "total_allowance",
+	"deleted_at": "employees.deleted_at",
+}
+
+func (repository *EmployeeRepository) buildFilterConditions(filter *dto.EmployeeFilter) (string, []any) {
+	// Soft deleted employees are only listed in trash
+	conditions := []string{"employees.deleted_at IS NULL"}
+	if filter.Trashed {
+		conditions = []string{"employees.deleted_at IS NOT NULL"}
+	}
+	args := []any{}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "employees.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Gender != "" {
+		conditions = append(conditions, "employees.gender = ?")
+		args = append(args, filter.Gender)
+	}
+	if filter.HiredFrom != "" {
+		conditions = append(conditions, "employees.hired_date >= ?")
+		args = append(args, filter.HiredFrom)
+	}
+	if filter.HiredTo != "" {
+		conditions = append(conditions, "employees.hired_date <= ?")
+		args = append(args, filter.HiredTo)
+	}
+	if filter.Department > 0 {
+		// Employees of sub departments are included
+		conditions = append(conditions, `employees.department_id IN (
+			WITH RECURSIVE department_tree AS (
+				SELECT id FROM departments WHERE id = ?
+				UNION ALL
+				SELECT departments.id FROM departments
+				INNER JOIN department_tree ON departments.parent_id = department_tree.id
+			)
+			SELECT id FROM department_tree
+		)`)
+		args = append(args, filter.Department)
+	}
+	if filter.Position > 0 {
+		conditions = append(conditions, "employees.position_id = ?")
+		args = append(args, filter.Position)
+	}
+	if filter.Manager > 0 {
+		conditions = append(conditions, "employees.manager_id = ?")
+		args = append(args, filter.Manager)
+	}
+	if filter.Allowance != "" {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM employee_allowances
+			INNER JOIN allowance_types ON allowance_types.id = employee_allowances.allowance_type_id
+			WHERE employee_allowances.employee_id = employees.id AND allowance_types.code = ?
+		)`)
+		args = append(args, filter.Allowance)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// buildOrder returns ORDER BY clause of the filter, id is the tie breaker so paging is stable
+func (repository *EmployeeRepository) buildOrder(filter *dto.EmployeeFilter) string {
+	sortColumn, ok := employeeSortColumns[filter.Sort]
+	if !ok {
+		sortColumn = employeeSortColumns["id"]
+	}
+	order := "DESC"
+	if filter.Order == "asc" {
+		order = "ASC"
+	}
+	return sortColumn + " " + order + ", employees.id " + order
+}
+
+func (repository *EmployeeRepository) Paginate(ctx context.Context, filter *dto.EmployeeFilter) (*[]models.Employee, int, error) {
+	conditions, args := repository.buildFilterConditions(filter)
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM employees WHERE " + conditions
+	err := repository.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, errors.Errorf("failed to count employees: %w", err)
+	}
+
 	query := `
 		SELECT 
-			id, name, email, tax_number, gender, hired_date, address, status, 
-			(SELECT COUNT(*) FROM employee_allowances WHERE employee_id = employees.id) AS total_allowance 
+			employees.id, employees.name, employees.email, employees.tax_number, employees.gender, 
+			employees.hired_date, employees.address, employees.status, 
+			COALESCE(allowances.total, 0) AS total_allowance, employees.deleted_at,
+			departments.name AS department_name, positions.name AS position_name, managers.name AS manager_name
 		FROM employees
-		ORDER BY id DESC
+		LEFT JOIN (
+			SELECT employee_id, COUNT(*) AS total FROM employee_allowances GROUP BY employee_id
+		) AS allowances ON allowances.employee_id = employees.id
+		LEFT JOIN departments ON departments.id = employees.department_id
+		LEFT JOIN positions ON positions.id = employees.position_id
+		LEFT JOIN employees AS managers ON managers.id = employees.manager_id
+		WHERE ` + conditions + `
+		ORDER BY ` + repository.buildOrder(filter) + `
+		LIMIT ? OFFSET ?
 	`
-	rows, err := repository.db.QueryContext(ctx, query)
+	rows, err := repository.db.QueryContext(ctx, query, append(args, filter.PerPage, filter.Offset())...)
 	
 	if err != nil {
-        return nil, errors.Errorf("failed to query employees: %w", err)
+        return nil, 0, errors.Errorf("failed to query employees: %w", err)
     }
 	defer rows.Close()
 
-	var employees []models.Employee
+	employees := []models.Employee{}
 	for rows.Next() {
 		var employee models.Employee
 
@@ -53,22 +157,123 @@ func (repository *EmployeeRepository) GetAll(ctx context.Context) (*[]models.Emp
 			&employee.Address,
 			&employee.Status,
 			&employee.TotalAllowance,
+			&employee.DeletedAt,
+			&employee.DepartmentName,
+			&employee.PositionName,
+			&employee.ManagerName,
 		)
 		if err != nil {
-			return nil, errors.Errorf("failed to get employee rows: %w", err)
+			return nil, 0, errors.Errorf("failed to get employee rows: %w", err)
 		}
 
 		employees = append(employees, employee)
 	}
 
-	return &employees, nil
+	return &employees, total, nil
 }
 
+// Each iterates employees matching the filter without paging, rows are read one by one
+// so large exports don't load the whole table in memory
+func (repository *EmployeeRepository) Each(ctx context.Context, filter *dto.EmployeeFilter, fn func(employee *models.Employee, allowances []string) error) error {
+	conditions, args := repository.buildFilterConditions(filter)
+
+	// Allowances are joined as rows, rows of the same employee are next to each other
+	query := `
+		SELECT 
+			employees.id, employees.name, employees.email, employees.tax_number, employees.gender,
+			employees.hired_date, employees.address, employees.status, employees.base_salary,
+			COALESCE(allowances.total, 0) AS total_allowance, employees.deleted_at,
+			departments.name AS department_name, positions.name AS position_name, managers.name AS manager_name,
+			allowance_types.name
+		FROM employees
+		LEFT JOIN (
+			SELECT employee_id, COUNT(*) AS total FROM employee_allowances GROUP BY employee_id
+		) AS allowances ON allowances.employee_id = employees.id
+		LEFT JOIN departments ON departments.id = employees.department_id
+		LEFT JOIN positions ON positions.id = employees.position_id
+		LEFT JOIN employees AS managers ON managers.id = employees.manager_id
+		LEFT JOIN employee_allowances ON employee_allowances.employee_id = employees.id
+		LEFT JOIN allowance_types ON allowance_types.id = employee_allowances.allowance_type_id
+		WHERE ` + conditions + `
+		ORDER BY ` + repository.buildOrder(filter) + `, allowance_types.name ASC
+	`
+	rows, err := repository.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.Errorf("failed to query employees: %w", err)
+	}
+	defer rows.Close()
+
+	var current *models.Employee
+	var allowances []string
+	for rows.Next() {
+		var employee models.Employee
+		var allowance sql.NullString
+		err = rows.Scan(
+			&employee.Id,
+			&employee.Name,
+			&employee.Email,
+			&employee.TaxNumber,
+			&employee.Gender,
+			&employee.HiredDate,
+			&employee.Address,
+			&employee.Status,
+			&employee.BaseSalary,
+			&employee.TotalAllowance,
+			&employee.DeletedAt,
+			&employee.DepartmentName,
+			&employee.PositionName,
+			&employee.ManagerName,
+			&allowance,
+		)
+		if err != nil {
+			return errors.Errorf("failed to get employee rows: %w", err)
+		}
+
+		if current == nil || current.Id != employee.Id {
+			if current != nil {
+				if err := fn(current, allowances); err != nil {
+					return err
+				}
+			}
+			current = &employee
+			allowances = []string{}
+		}
+		if allowance.Valid {
+			allowances = append(allowances, allowance.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Errorf("failed to iterate employee rows: %w", err)
+	}
+	if current != nil {
+		return fn(current, allowances)
+	}
+	return nil
+}
+
+// GetById finds employee that is not in trash
 func (repository *EmployeeRepository) GetById(ctx context.Context, employeeId int) (*models.Employee, error) {
+	return repository.findById(ctx, employeeId, "employees.deleted_at IS NULL")
+}
+
+// GetTrashedById finds soft deleted employee
+func (repository *EmployeeRepository) GetTrashedById(ctx context.Context, employeeId int) (*models.Employee, error) {
+	return repository.findById(ctx, employeeId, "employees.deleted_at IS NOT NULL")
+}
+
+func (repository *EmployeeRepository) findById(ctx context.Context, employeeId int, condition string) (*models.Employee, error) {
 	query := `
-		SELECT id, name, email, tax_number, gender, hired_date, address, status
-		FROM employees WHERE id = ?
-	`;
+		SELECT 
+			employees.id, employees.name, employees.email, employees.tax_number, employees.gender, 
+			employees.hired_date, employees.address, employees.status, employees.base_salary, employees.deleted_at,
+			employees.department_id, departments.name AS department_name,
+			employees.position_id, positions.name AS position_name,
+			employees.manager_id, managers.name AS manager_name
+		FROM employees
+		LEFT JOIN departments ON departments.id = employees.department_id
+		LEFT JOIN positions ON positions.id = employees.position_id
+		LEFT JOIN employees AS managers ON managers.id = employees.manager_id
+		WHERE employees.id = ? AND ` + condition;
 	row := repository.db.QueryRowContext(ctx, query, employeeId)
 	if row.Err() != nil {
 		return nil, errors.Errorf("failed to query employee id=%d: %w", employeeId, row.Err())
@@ -83,6 +288,14 @@ func (repository *EmployeeRepository) GetById(ctx context.Context, employeeId in
 		&employee.HiredDate,
 		&employee.Address,
 		&employee.Status,
+		&employee.BaseSalary,
+		&employee.DeletedAt,
+		&employee.DepartmentId,
+		&employee.DepartmentName,
+		&employee.PositionId,
+		&employee.PositionName,
+		&employee.ManagerId,
+		&employee.ManagerName,
 	)
 	if err != nil {
 		return nil, errors.Errorf("employee not found id=%d: %w", employeeId, err)
@@ -91,10 +304,115 @@ func (repository *EmployeeRepository) GetById(ctx context.Context, employeeId in
 	return &employee, nil
 }
 
+// GetPayable returns active employees hired on or before the date, they are paid in payroll of the month
+func (repository *EmployeeRepository) GetPayable(ctx context.Context, hiredUntil time.Time) (*[]models.Employee, error) {
+	query := `
+		SELECT id, name, email, tax_number, gender, hired_date, address, status, base_salary, deleted_at
+		FROM employees
+		WHERE deleted_at IS NULL AND status = 'ACTIVE' AND (hired_date IS NULL OR hired_date <= ?)
+		ORDER BY name, id
+	`
+	rows, err := repository.db.QueryContext(ctx, query, hiredUntil.Format("2006-01-02"))
+	if err != nil {
+		return nil, errors.Errorf("failed to query payable employees: %w", err)
+	}
+	defer rows.Close()
+
+	employees := []models.Employee{}
+	for rows.Next() {
+		var employee models.Employee
+		err = rows.Scan(
+			&employee.Id,
+			&employee.Name,
+			&employee.Email,
+			&employee.TaxNumber,
+			&employee.Gender,
+			&employee.HiredDate,
+			&employee.Address,
+			&employee.Status,
+			&employee.BaseSalary,
+			&employee.DeletedAt,
+		)
+		if err != nil {
+			return nil, errors.Errorf("failed to get payable employee rows: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+	return &employees, nil
+}
+
+// GetOptions returns id and name of employees that are not in trash, used in select inputs
+func (repository *EmployeeRepository) GetOptions(ctx context.Context) (*[]models.Employee, error) {
+	rows, err := repository.db.QueryContext(ctx, `SELECT id, name, email FROM employees WHERE deleted_at IS NULL ORDER BY name, id`)
+	if err != nil {
+		return nil, errors.Errorf("failed to query employee options: %w", err)
+	}
+	defer rows.Close()
+
+	employees := []models.Employee{}
+	for rows.Next() {
+		var employee models.Employee
+		if err := rows.Scan(&employee.Id, &employee.Name, &employee.Email); err != nil {
+			return nil, errors.Errorf("failed to get employee option rows: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+	return &employees, nil
+}
+
+// GetReportingLines returns employees matching the filter with their manager, used to build org chart
+func (repository *EmployeeRepository) GetReportingLines(ctx context.Context, filter *dto.EmployeeFilter) (*[]models.Employee, error) {
+	conditions, args := repository.buildFilterConditions(filter)
+	query := `
+		SELECT
+			employees.id, employees.name, employees.status, employees.manager_id,
+			departments.name AS department_name, positions.name AS position_name
+		FROM employees
+		LEFT JOIN departments ON departments.id = employees.department_id
+		LEFT JOIN positions ON positions.id = employees.position_id
+		WHERE ` + conditions + `
+		ORDER BY employees.name, employees.id
+	`
+	rows, err := repository.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Errorf("failed to query reporting lines: %w", err)
+	}
+	defer rows.Close()
+
+	employees := []models.Employee{}
+	for rows.Next() {
+		var employee models.Employee
+		err = rows.Scan(
+			&employee.Id,
+			&employee.Name,
+			&employee.Status,
+			&employee.ManagerId,
+			&employee.DepartmentName,
+			&employee.PositionName,
+		)
+		if err != nil {
+			return nil, errors.Errorf("failed to get reporting line rows: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+	return &employees, nil
+}
+
+// GetManagerId returns manager of the employee including employee in trash,
+// it's used to walk up the reporting line
+func (repository *EmployeeRepository) GetManagerId(ctx context.Context, employeeId int) (sql.NullInt64, error) {
+	var managerId sql.NullInt64
+	err := repository.db.QueryRowContext(ctx, `SELECT manager_id FROM employees WHERE id = ?`, employeeId).Scan(&managerId)
+	if err != nil {
+		return managerId, errors.Errorf("failed to get manager of employee id=%d: %w", employeeId, err)
+	}
+	return managerId, nil
+}
+
 func (repository *EmployeeRepository) Store(ctx context.Context, employee *models.Employee) (*models.Employee, error) {
 	query := `
-		INSERT INTO employees(name, email, tax_number, gender, hired_date, address, status)
-		VALUES(?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO employees(name, email, tax_number, gender, hired_date, address, status, base_salary, department_id, position_id, manager_id)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	result, err := repository.db.ExecContext(
 		ctx,
@@ -106,6 +424,10 @@ func (repository *EmployeeRepository) Store(ctx context.Context, employee *model
 		employee.HiredDate,
 		employee.Address,
 		employee.Status,
+		employee.BaseSalary,
+		employee.DepartmentId,
+		employee.PositionId,
+		employee.ManagerId,
 	)
 
 	if err != nil {
@@ -123,8 +445,9 @@ func (repository *EmployeeRepository) Store(ctx context.Context, employee *model
 func (repository *EmployeeRepository) Update(ctx context.Context, employee *models.Employee) (*models.Employee, error) {
 	query := `
 		UPDATE employees 
-		SET name = ?, email = ?, tax_number = ?, gender = ?, hired_date = ?, address = ?, status = ? 
-		WHERE id = ?
+		SET name = ?, email = ?, tax_number = ?, gender = ?, hired_date = ?, address = ?, status = ?, base_salary = ?, 
+			department_id = ?, position_id = ?, manager_id = ? 
+		WHERE id = ? AND deleted_at IS NULL
 	`
 	_, err := repository.db.ExecContext(
 		ctx,
@@ -136,6 +459,10 @@ func (repository *EmployeeRepository) Update(ctx context.Context, employee *mode
 		employee.HiredDate,
 		employee.Address,
 		employee.Status,
+		employee.BaseSalary,
+		employee.DepartmentId,
+		employee.PositionId,
+		employee.ManagerId,
 		employee.Id,
 	)
 
@@ -146,6 +473,69 @@ func (repository *EmployeeRepository) Update(ctx context.Context, employee *mode
 	return repository.GetById(ctx, employee.Id)
 }
 
+// UpdateStatus changes status of the employee only when it's still the given status,
+// zero rows affected means the status is changed by someone else
+func (repository *EmployeeRepository) UpdateStatus(ctx context.Context, employeeId int, from string, to string) (int64, error) {
+	query := `UPDATE employees SET status = ? WHERE id = ? AND COALESCE(status, '') = ? AND deleted_at IS NULL`
+	result, err := repository.db.ExecContext(ctx, query, to, employeeId, from)
+	if err != nil {
+		return 0, errors.Errorf("failed to update status of employee id=%d: %w", employeeId, err)
+	}
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowAffected, nil
+}
+
+// SoftDelete moves the employee to trash, it can be restored until it's purged
+func (repository *EmployeeRepository) SoftDelete(ctx context.Context, employeeId int, deletedAt time.Time) (int64, error) {
+	query := `UPDATE employees SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+	result, err := repository.db.ExecContext(ctx, query, deletedAt, employeeId)
+	if err != nil {
+		return 0, errors.Errorf("failed to soft delete employee id=%d: %w", employeeId, err)
+	}
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowAffected, nil
+}
+
+func (repository *EmployeeRepository) Restore(ctx context.Context, employeeId int) (int64, error) {
+	query := `UPDATE employees SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`
+	result, err := repository.db.ExecContext(ctx, query, employeeId)
+	if err != nil {
+		return 0, errors.Errorf("failed to restore employee id=%d: %w", employeeId, err)
+	}
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowAffected, nil
+}
+
+// GetTrashedIdsBefore returns id of employees that are in trash since before the time
+func (repository *EmployeeRepository) GetTrashedIdsBefore(ctx context.Context, before time.Time) ([]int, error) {
+	query := `SELECT id FROM employees WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY id`
+	rows, err := repository.db.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, errors.Errorf("failed to query trashed employees: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Errorf("failed to get trashed employee rows: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Destroy permanently deletes the employee, use SoftDelete to move it to trash
 func (repository *EmployeeRepository) Destroy(ctx context.Context, employeeId int) (int64, error) {
 	query := `DELETE FROM employees WHERE id = ?`
 	result, err := repository.db.ExecContext(ctx, query, employeeId)
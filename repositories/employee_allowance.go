@@ -18,7 +18,15 @@ func NewEmployeeAllowanceRepository(db *sql.DB) *EmployeeAllowanceRepository {
 }
 
 func (r *EmployeeAllowanceRepository) GetByEmployeeId(ctx context.Context, employeeId int) (*[]models.EmployeeAllowance, error) {
-	query := `SELECT id, employee_id, allowance FROM employee_allowances WHERE employee_id = ?`;
+	query := `
+		SELECT 
+			employee_allowances.id, employee_id, allowance_type_id, allowance_types.code, allowance_types.name, 
+			amount, allowance_types.default_amount, allowance_types.is_taxable
+		FROM employee_allowances
+		INNER JOIN allowance_types ON allowance_types.id = employee_allowances.allowance_type_id
+		WHERE employee_id = ?
+		ORDER BY allowance_types.name
+	`
 	rows, err := r.db.QueryContext(ctx, query, employeeId)
 	if err != nil {
 		return nil, errors.Errorf("failed to query employee allowance by employee id=%d: %w", employeeId, err)
@@ -32,7 +40,12 @@ func (r *EmployeeAllowanceRepository) GetByEmployeeId(ctx context.Context, emplo
 		err = rows.Scan(
 			&employeeAllowance.Id,
 			&employeeAllowance.EmployeeId,
+			&employeeAllowance.AllowanceTypeId,
+			&employeeAllowance.Code,
 			&employeeAllowance.Allowance,
+			&employeeAllowance.Amount,
+			&employeeAllowance.DefaultAmount,
+			&employeeAllowance.IsTaxable,
 		)
 		if err != nil {
 			return nil, errors.Errorf("allowance by employee not found id=%d: %w", employeeId, err)
@@ -50,7 +63,14 @@ func (r *EmployeeAllowanceRepository) WithTx(tx *sql.Tx) *EmployeeAllowanceRepos
 }
 
 func (repository *EmployeeAllowanceRepository) GetById(ctx context.Context, id int) (*models.EmployeeAllowance, error) {
-	query := `SELECT id, employee_id, allowance FROM employee_allowances WHERE id = ?`
+	query := `
+		SELECT 
+			employee_allowances.id, employee_id, allowance_type_id, allowance_types.code, allowance_types.name, 
+			amount, allowance_types.default_amount, allowance_types.is_taxable
+		FROM employee_allowances
+		INNER JOIN allowance_types ON allowance_types.id = employee_allowances.allowance_type_id
+		WHERE employee_allowances.id = ?
+	`
 	row := repository.db.QueryRowContext(ctx, query, id)
 	if row.Err() != nil {
 		return nil, errors.Errorf("failed to query employee allowance id=%d: %w", id, row.Err())
@@ -59,7 +79,12 @@ func (repository *EmployeeAllowanceRepository) GetById(ctx context.Context, id i
 	err := row.Scan(
 		&employeeAllowance.Id,
 		&employeeAllowance.EmployeeId,
+		&employeeAllowance.AllowanceTypeId,
+		&employeeAllowance.Code,
 		&employeeAllowance.Allowance,
+		&employeeAllowance.Amount,
+		&employeeAllowance.DefaultAmount,
+		&employeeAllowance.IsTaxable,
 	)
 	if err != nil {
 		return nil, errors.Errorf("employee allowance not found id=%d: %w", id, err)
@@ -68,8 +93,14 @@ func (repository *EmployeeAllowanceRepository) GetById(ctx context.Context, id i
 }
 
 func (repository *EmployeeAllowanceRepository) Store(ctx context.Context, employeeAllowance *models.EmployeeAllowance) (*models.EmployeeAllowance, error) {
-	query := `INSERT INTO employee_allowances(employee_id, allowance) VALUES(?, ?)`
-	result, err := repository.db.ExecContext(ctx, query)
+	query := `INSERT INTO employee_allowances(employee_id, allowance_type_id, amount) VALUES(?, ?, ?)`
+	result, err := repository.db.ExecContext(
+		ctx,
+		query,
+		employeeAllowance.EmployeeId,
+		employeeAllowance.AllowanceTypeId,
+		employeeAllowance.Amount,
+	)
 	if err != nil {
 		return nil, errors.Errorf("failed to store employee allowance: %w", err)
 	}
@@ -81,8 +112,9 @@ func (repository *EmployeeAllowanceRepository) Store(ctx context.Context, employ
 	return employeeAllowance, nil
 }
 
-func (repository *EmployeeAllowanceRepository) StoreMany(ctx context.Context, employeeId int, allowances []string) (*[]models.EmployeeAllowance, error) {
-	query := `INSERT INTO employee_allowances(employee_id, allowance) VALUES(?, ?)`
+// StoreMany stores allowances of the employee, EmployeeId of the items is replaced with employeeId
+func (repository *EmployeeAllowanceRepository) StoreMany(ctx context.Context, employeeId int, allowances []models.EmployeeAllowance) (*[]models.EmployeeAllowance, error) {
+	query := `INSERT INTO employee_allowances(employee_id, allowance_type_id, amount) VALUES(?, ?, ?)`
 	statement, err := repository.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, errors.Errorf("failed to prepare statement: %w", err)
@@ -91,7 +123,7 @@ func (repository *EmployeeAllowanceRepository) StoreMany(ctx context.Context, em
 
 	var employeeAllowances []models.EmployeeAllowance
 	for _, item := range allowances {
-		result, err := statement.Exec(employeeId, item)
+		result, err := statement.ExecContext(ctx, employeeId, item.AllowanceTypeId, item.Amount)
 		if err != nil {
 			return nil, errors.Errorf("failed to store employee allowance: %w", err)
 		}
@@ -99,11 +131,9 @@ func (repository *EmployeeAllowanceRepository) StoreMany(ctx context.Context, em
 		if err != nil {
 			return nil, errors.Errorf("failed to get last id: %w", err)
 		}
-		employeeAllowances = append(employeeAllowances, models.EmployeeAllowance{
-			Id: int(id),
-			EmployeeId: employeeId,
-			Allowance: item,
-		})
+		item.Id = int(id)
+		item.EmployeeId = employeeId
+		employeeAllowances = append(employeeAllowances, item)
 	}
 	return &employeeAllowances, nil
 }
@@ -111,14 +141,15 @@ func (repository *EmployeeAllowanceRepository) StoreMany(ctx context.Context, em
 func (repository *EmployeeAllowanceRepository) Update(ctx context.Context, employeeAllowance *models.EmployeeAllowance) (*models.EmployeeAllowance, error) {
 	query := `
 		UPDATE employee_allowances 
-		SET employee_id = ?, allowance = ? 
+		SET employee_id = ?, allowance_type_id = ?, amount = ? 
 		WHERE id = ?
 	`
 	_, err := repository.db.ExecContext(
 		ctx,
 		query,
 		employeeAllowance.EmployeeId,
-		employeeAllowance.Allowance,
+		employeeAllowance.AllowanceTypeId,
+		employeeAllowance.Amount,
 		employeeAllowance.Id,
 	)
 	if err != nil {
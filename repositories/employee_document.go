@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type EmployeeDocumentRepository struct {
+	db database.Transaction
+}
+
+func NewEmployeeDocumentRepository(db *sql.DB) *EmployeeDocumentRepository {
+	return &EmployeeDocumentRepository{db: db}
+}
+
+func (r *EmployeeDocumentRepository) WithTx(tx *sql.Tx) *EmployeeDocumentRepository {
+	return &EmployeeDocumentRepository{
+		db: tx,
+	}
+}
+
+const employeeDocumentColumns = `
+	employee_documents.id, employee_id, employees.name AS employee_name, document_type, description,
+	file_path, file_name, mime_type, file_size, expired_at,
+	created_by, users.name AS created_by_name, employee_documents.created_at
+`
+
+const employeeDocumentJoins = `
+	INNER JOIN employees ON employees.id = employee_documents.employee_id
+	LEFT JOIN users ON users.id = employee_documents.created_by
+`
+
+// GetByEmployeeId returns documents of the employee, latest first
+func (repository *EmployeeDocumentRepository) GetByEmployeeId(ctx context.Context, employeeId int) (*[]models.EmployeeDocument, error) {
+	query := `SELECT ` + employeeDocumentColumns + ` FROM employee_documents ` + employeeDocumentJoins + `
+		WHERE employee_id = ?
+		ORDER BY employee_documents.id DESC
+	`
+	rows, err := repository.db.QueryContext(ctx, query, employeeId)
+	if err != nil {
+		return nil, errors.Errorf("failed to query documents of employee id=%d: %w", employeeId, err)
+	}
+	return scanEmployeeDocuments(rows)
+}
+
+// GetExpiringBefore returns documents of employees not in trash that expire before the date (already expired
+// documents are included), the earliest expiry first
+func (repository *EmployeeDocumentRepository) GetExpiringBefore(ctx context.Context, before time.Time, limit int) (*[]models.EmployeeDocument, error) {
+	query := `SELECT ` + employeeDocumentColumns + ` FROM employee_documents ` + employeeDocumentJoins + `
+		WHERE expired_at IS NOT NULL AND expired_at < ? AND employees.deleted_at IS NULL
+		ORDER BY expired_at, employee_documents.id
+		LIMIT ?
+	`
+	rows, err := repository.db.QueryContext(ctx, query, before.Format("2006-01-02"), limit)
+	if err != nil {
+		return nil, errors.Errorf("failed to query expiring documents: %w", err)
+	}
+	return scanEmployeeDocuments(rows)
+}
+
+func (repository *EmployeeDocumentRepository) GetById(ctx context.Context, id int) (*models.EmployeeDocument, error) {
+	query := `SELECT ` + employeeDocumentColumns + ` FROM employee_documents ` + employeeDocumentJoins + `
+		WHERE employee_documents.id = ?
+	`
+	rows, err := repository.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, errors.Errorf("failed to query document id=%d: %w", id, err)
+	}
+	documents, err := scanEmployeeDocuments(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(*documents) == 0 {
+		return nil, errors.Errorf("document id=%d is not found: %w", id, sql.ErrNoRows)
+	}
+	return &(*documents)[0], nil
+}
+
+func (repository *EmployeeDocumentRepository) Store(ctx context.Context, document *models.EmployeeDocument) (int, error) {
+	query := `
+		INSERT INTO employee_documents(
+			employee_id, document_type, description, file_path, file_name, mime_type, file_size, expired_at, created_by
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	var expiredAt any
+	if document.ExpiredAt.Valid {
+		expiredAt = document.ExpiredAt.Time.Format("2006-01-02")
+	}
+	result, err := repository.db.ExecContext(
+		ctx,
+		query,
+		document.EmployeeId,
+		document.DocumentType,
+		document.Description,
+		document.FilePath,
+		document.FileName,
+		document.MimeType,
+		document.FileSize,
+		expiredAt,
+		document.CreatedBy,
+	)
+	if err != nil {
+		return 0, errors.Errorf("failed to store document of employee id=%d: %w", document.EmployeeId, err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, errors.Errorf("failed to get id of stored document: %w", err)
+	}
+	return int(id), nil
+}
+
+func (repository *EmployeeDocumentRepository) Destroy(ctx context.Context, id int) (int64, error) {
+	query := `DELETE FROM employee_documents WHERE id = ?`
+	result, err := repository.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return 0, errors.Errorf("failed to delete document id=%d: %w", id, err)
+	}
+	return result.RowsAffected()
+}
+
+func scanEmployeeDocuments(rows *sql.Rows) (*[]models.EmployeeDocument, error) {
+	defer rows.Close()
+
+	documents := []models.EmployeeDocument{}
+	for rows.Next() {
+		var document models.EmployeeDocument
+		err := rows.Scan(
+			&document.Id,
+			&document.EmployeeId,
+			&document.EmployeeName,
+			&document.DocumentType,
+			&document.Description,
+			&document.FilePath,
+			&document.FileName,
+			&document.MimeType,
+			&document.FileSize,
+			&document.ExpiredAt,
+			&document.CreatedBy,
+			&document.CreatedByName,
+			&document.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.Errorf("failed to get document rows: %w", err)
+		}
+		documents = append(documents, document)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Errorf("failed to get document rows: %w", err)
+	}
+	return &documents, nil
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type EmployeeStatusHistoryRepository struct {
+	db database.Transaction
+}
+
+func NewEmployeeStatusHistoryRepository(db *sql.DB) *EmployeeStatusHistoryRepository {
+	return &EmployeeStatusHistoryRepository{db: db}
+}
+
+func (r *EmployeeStatusHistoryRepository) WithTx(tx *sql.Tx) *EmployeeStatusHistoryRepository {
+	return &EmployeeStatusHistoryRepository{
+		db: tx,
+	}
+}
+
+// GetByEmployeeId returns status changes of the employee, latest first
+func (repository *EmployeeStatusHistoryRepository) GetByEmployeeId(ctx context.Context, employeeId int) (*[]models.EmployeeStatusHistory, error) {
+	query := `
+		SELECT
+			employee_status_histories.id, employee_id, from_status, to_status, reason, effective_date,
+			created_by, users.name AS created_by_name, employee_status_histories.created_at
+		FROM employee_status_histories
+		LEFT JOIN users ON users.id = employee_status_histories.created_by
+		WHERE employee_id = ?
+		ORDER BY employee_status_histories.id DESC
+	`
+	rows, err := repository.db.QueryContext(ctx, query, employeeId)
+	if err != nil {
+		return nil, errors.Errorf("failed to query status history of employee id=%d: %w", employeeId, err)
+	}
+	defer rows.Close()
+
+	histories := []models.EmployeeStatusHistory{}
+	for rows.Next() {
+		var history models.EmployeeStatusHistory
+		err = rows.Scan(
+			&history.Id,
+			&history.EmployeeId,
+			&history.FromStatus,
+			&history.ToStatus,
+			&history.Reason,
+			&history.EffectiveDate,
+			&history.CreatedBy,
+			&history.CreatedByName,
+			&history.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.Errorf("failed to get status history rows: %w", err)
+		}
+		histories = append(histories, history)
+	}
+	return &histories, nil
+}
+
+func (repository *EmployeeStatusHistoryRepository) Store(ctx context.Context, history *models.EmployeeStatusHistory) error {
+	query := `
+		INSERT INTO employee_status_histories(employee_id, from_status, to_status, reason, effective_date, created_by)
+		VALUES(?, ?, ?, ?, ?, ?)
+	`
+	_, err := repository.db.ExecContext(
+		ctx,
+		query,
+		history.EmployeeId,
+		history.FromStatus,
+		history.ToStatus,
+		history.Reason,
+		history.EffectiveDate.Format("2006-01-02"),
+		history.CreatedBy,
+	)
+	if err != nil {
+		return errors.Errorf("failed to store status history of employee id=%d: %w", history.EmployeeId, err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+// FailedLoginStatistics counts failed attempts of a username or IP address within a window
+type FailedLoginStatistics struct {
+	Attempts      int
+	LastAttemptAt sql.NullTime
+}
+
+// FailedLoginSummary groups failed attempts by username and IP address for review
+type FailedLoginSummary struct {
+	Username       string
+	IpAddress      string
+	Attempts       int
+	FirstAttemptAt time.Time
+	LastAttemptAt  time.Time
+}
+
+type FailedLoginRepository struct {
+	db database.Transaction
+}
+
+func NewFailedLoginRepository(db *sql.DB) *FailedLoginRepository {
+	return &FailedLoginRepository{db: db}
+}
+
+func (r *FailedLoginRepository) WithTx(tx *sql.Tx) *FailedLoginRepository {
+	return &FailedLoginRepository{
+		db: tx,
+	}
+}
+
+func (repository *FailedLoginRepository) Create(ctx context.Context, failedLogin *models.FailedLogin) error {
+	query := `
+		INSERT INTO failed_logins(username, ip_address, factor, user_agent, attempted_at)
+		VALUES(?, ?, ?, ?, ?)
+	`
+	_, err := repository.db.ExecContext(
+		ctx,
+		query,
+		failedLogin.Username,
+		failedLogin.IpAddress,
+		failedLogin.Factor,
+		failedLogin.UserAgent,
+		failedLogin.AttemptedAt,
+	)
+	if err != nil {
+		return errors.Errorf("failed to store failed login: %w", err)
+	}
+	return nil
+}
+
+func (repository *FailedLoginRepository) statistics(ctx context.Context, where string, since time.Time, args ...any) (*FailedLoginStatistics, error) {
+	query := `
+		SELECT COUNT(*), MAX(attempted_at)
+		FROM failed_logins
+		WHERE ` + where + ` AND attempted_at >= ?
+	`
+	var stats FailedLoginStatistics
+	err := repository.db.QueryRowContext(ctx, query, append(args, since)...).Scan(&stats.Attempts, &stats.LastAttemptAt)
+	if err != nil {
+		return nil, errors.Errorf("failed to query failed login statistic: %w", err)
+	}
+	return &stats, nil
+}
+
+// GetStatisticsByUsername counts failed attempts of the username on the factor (password or two factor code)
+func (repository *FailedLoginRepository) GetStatisticsByUsername(ctx context.Context, username string, factor string, since time.Time) (*FailedLoginStatistics, error) {
+	return repository.statistics(ctx, "username = ? AND factor = ?", since, username, factor)
+}
+
+// GetStatisticsByIp counts failed attempts of every factor from the IP address
+func (repository *FailedLoginRepository) GetStatisticsByIp(ctx context.Context, ipAddress string, since time.Time) (*FailedLoginStatistics, error) {
+	return repository.statistics(ctx, "ip_address = ?", since, ipAddress)
+}
+
+func (repository *FailedLoginRepository) GetSummaries(ctx context.Context) (*[]FailedLoginSummary, error) {
+	query := `
+		SELECT username, ip_address, COUNT(*), MIN(attempted_at), MAX(attempted_at)
+		FROM failed_logins
+		GROUP BY username, ip_address
+		ORDER BY MAX(attempted_at) DESC
+	`
+	rows, err := repository.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Errorf("failed to query failed logins: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []FailedLoginSummary{}
+	for rows.Next() {
+		var summary FailedLoginSummary
+		err := rows.Scan(
+			&summary.Username,
+			&summary.IpAddress,
+			&summary.Attempts,
+			&summary.FirstAttemptAt,
+			&summary.LastAttemptAt,
+		)
+		if err != nil {
+			return nil, errors.Errorf("failed to get failed login rows: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	return &summaries, nil
+}
+
+func (repository *FailedLoginRepository) DeleteByUsernameAndIp(ctx context.Context, username string, ipAddress string) error {
+	query := `DELETE FROM failed_logins WHERE username = ? AND ip_address = ?`
+	_, err := repository.db.ExecContext(ctx, query, username, ipAddress)
+	if err != nil {
+		return errors.Errorf("failed to delete failed logins of %s from %s: %w", username, ipAddress, err)
+	}
+	return nil
+}
+
+// DeleteBefore deletes attempts made before the time, returns number of deleted rows
+func (repository *FailedLoginRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := repository.db.ExecContext(ctx, `DELETE FROM failed_logins WHERE attempted_at < ?`, before)
+	if err != nil {
+		return 0, errors.Errorf("failed to delete failed logins before %s: %w", before.Format(time.DateTime), err)
+	}
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowAffected, nil
+}
+
+func (repository *FailedLoginRepository) DeleteAll(ctx context.Context) error {
+	_, err := repository.db.ExecContext(ctx, `DELETE FROM failed_logins`)
+	if err != nil {
+		return errors.Errorf("failed to delete failed logins: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type PasswordResetRepository struct {
+	db database.Transaction
+}
+
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+func (r *PasswordResetRepository) WithTx(tx *sql.Tx) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		db: tx,
+	}
+}
+
+func (repository *PasswordResetRepository) Create(ctx context.Context, passwordReset *models.PasswordReset) error {
+	query := `
+		INSERT INTO password_resets(user_id, token, expired_at)
+		VALUES(?, ?, ?)
+	`
+	_, err := repository.db.ExecContext(ctx, query, passwordReset.UserId, passwordReset.Token, passwordReset.ExpiredAt)
+	if err != nil {
+		return errors.Errorf("failed to store password reset: %w", err)
+	}
+	return nil
+}
+
+// GetValidByToken finds unused and unexpired password reset by the hashed token
+func (repository *PasswordResetRepository) GetValidByToken(ctx context.Context, token string, now time.Time) (*models.PasswordReset, error) {
+	query := `
+		SELECT id, user_id, token, expired_at, used_at, created_at
+		FROM password_resets
+		WHERE token = ? AND used_at IS NULL AND expired_at > ?
+	`
+	var passwordReset models.PasswordReset
+	err := repository.db.QueryRowContext(ctx, query, token, now).Scan(
+		&passwordReset.Id,
+		&passwordReset.UserId,
+		&passwordReset.Token,
+		&passwordReset.ExpiredAt,
+		&passwordReset.UsedAt,
+		&passwordReset.CreatedAt,
+	)
+	if err != nil {
+		return nil, errors.Errorf("password reset not found: %w", err)
+	}
+	return &passwordReset, nil
+}
+
+// MarkUsed consumes the token, returns sql.ErrNoRows when it was already used by another request
+func (repository *PasswordResetRepository) MarkUsed(ctx context.Context, id int, usedAt time.Time) error {
+	query := `UPDATE password_resets SET used_at = ? WHERE id = ? AND used_at IS NULL`
+	result, err := repository.db.ExecContext(ctx, query, usedAt, id)
+	if err != nil {
+		return errors.Errorf("failed to update password reset id=%d: %w", id, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return errors.Errorf("password reset id=%d already used: %w", id, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// DeleteByUserId removes previous tokens so only the latest reset link is valid
+func (repository *PasswordResetRepository) DeleteByUserId(ctx context.Context, userId int) error {
+	query := `DELETE FROM password_resets WHERE user_id = ?`
+	_, err := repository.db.ExecContext(ctx, query, userId)
+	if err != nil {
+		return errors.Errorf("failed to delete password resets of user id=%d: %w", userId, err)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type PayrollRunRepository struct {
+	db database.Transaction
+}
+
+func NewPayrollRunRepository(db *sql.DB) *PayrollRunRepository {
+	return &PayrollRunRepository{db: db}
+}
+
+func (r *PayrollRunRepository) WithTx(tx *sql.Tx) *PayrollRunRepository {
+	return &PayrollRunRepository{
+		db: tx,
+	}
+}
+
+const payrollRunColumns = `
+	payroll_runs.id, payroll_runs.period, payroll_runs.status, payroll_runs.corrects_run_id, payroll_runs.notes,
+	payroll_runs.total_employee, payroll_runs.total_gross, payroll_runs.total_deduction, payroll_runs.total_net,
+	payroll_runs.created_by, creators.name, payroll_runs.reviewed_by, reviewers.name, payroll_runs.reviewed_at,
+	payroll_runs.locked_by, lockers.name, payroll_runs.locked_at, payroll_runs.created_at, payroll_runs.updated_at
+`
+
+const payrollRunJoins = `
+	LEFT JOIN users AS creators ON creators.id = payroll_runs.created_by
+	LEFT JOIN users AS reviewers ON reviewers.id = payroll_runs.reviewed_by
+	LEFT JOIN users AS lockers ON lockers.id = payroll_runs.locked_by
+`
+
+func scanPayrollRun(row interface{ Scan(...any) error }) (*models.PayrollRun, error) {
+	var run models.PayrollRun
+	err := row.Scan(
+		&run.Id,
+		&run.Period,
+		&run.Status,
+		&run.CorrectsRunId,
+		&run.Notes,
+		&run.TotalEmployee,
+		&run.TotalGross,
+		&run.TotalDeduction,
+		&run.TotalNet,
+		&run.CreatedBy,
+		&run.CreatedByName,
+		&run.ReviewedBy,
+		&run.ReviewedByName,
+		&run.ReviewedAt,
+		&run.LockedBy,
+		&run.LockedByName,
+		&run.LockedAt,
+		&run.CreatedAt,
+		&run.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (repository *PayrollRunRepository) Paginate(ctx context.Context, filter *dto.PayrollRunFilter) (*[]models.PayrollRun, int, error) {
+	conditions := []string{"1 = 1"}
+	args := []any{}
+	if filter.Status != "" {
+		conditions = append(conditions, "payroll_runs.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Year > 0 {
+		conditions = append(conditions, "YEAR(payroll_runs.period) = ?")
+		args = append(args, filter.Year)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	err := repository.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM payroll_runs WHERE "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, errors.Errorf("failed to count payroll runs: %w", err)
+	}
+
+	query := `SELECT ` + payrollRunColumns + ` FROM payroll_runs ` + payrollRunJoins + `
+		WHERE ` + where + `
+		ORDER BY payroll_runs.period DESC, payroll_runs.id DESC
+		LIMIT ? OFFSET ?
+	`
+	rows, err := repository.db.QueryContext(ctx, query, append(args, filter.PerPage, filter.Offset())...)
+	if err != nil {
+		return nil, 0, errors.Errorf("failed to query payroll runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.PayrollRun{}
+	for rows.Next() {
+		run, err := scanPayrollRun(rows)
+		if err != nil {
+			return nil, 0, errors.Errorf("failed to get payroll run rows: %w", err)
+		}
+		runs = append(runs, *run)
+	}
+	return &runs, total, nil
+}
+
+func (repository *PayrollRunRepository) GetById(ctx context.Context, id int) (*models.PayrollRun, error) {
+	query := `SELECT ` + payrollRunColumns + ` FROM payroll_runs ` + payrollRunJoins + ` WHERE payroll_runs.id = ?`
+	run, err := scanPayrollRun(repository.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, errors.Errorf("payroll run not found id=%d: %w", id, err)
+	}
+	return run, nil
+}
+
+// GetByIdForUpdate must run in a transaction, the run row stays locked until it commits
+// so status checked by the caller can't be changed by another request meanwhile
+func (repository *PayrollRunRepository) GetByIdForUpdate(ctx context.Context, id int) (*models.PayrollRun, error) {
+	var locked int
+	err := repository.db.QueryRowContext(ctx, `SELECT id FROM payroll_runs WHERE id = ? FOR UPDATE`, id).Scan(&locked)
+	if err != nil {
+		return nil, errors.Errorf("payroll run not found id=%d: %w", id, err)
+	}
+	return repository.GetById(ctx, id)
+}
+
+// GetLatestByPeriod returns the last run of the month, corrections are created after the run they replace
+func (repository *PayrollRunRepository) GetLatestByPeriod(ctx context.Context, period time.Time) (*models.PayrollRun, error) {
+	query := `SELECT ` + payrollRunColumns + ` FROM payroll_runs ` + payrollRunJoins + `
+		WHERE payroll_runs.period = ?
+		ORDER BY payroll_runs.id DESC
+		LIMIT 1
+	`
+	run, err := scanPayrollRun(repository.db.QueryRowContext(ctx, query, period.Format("2006-01-02")))
+	if err != nil {
+		return nil, errors.Errorf("payroll run not found period=%s: %w", period.Format("2006-01"), err)
+	}
+	return run, nil
+}
+
+// LockPeriod must run in a transaction, runs of the month are created one at a time until it commits
+// so the latest run checked before the new run is stored stays the latest
+func (repository *PayrollRunRepository) LockPeriod(ctx context.Context, period time.Time) error {
+	value := period.Format("2006-01-02")
+	if _, err := repository.db.ExecContext(ctx, `INSERT IGNORE INTO payroll_periods(period) VALUES(?)`, value); err != nil {
+		return errors.Errorf("failed to create lock of payroll period=%s: %w", period.Format("2006-01"), err)
+	}
+	var locked time.Time
+	err := repository.db.QueryRowContext(ctx, `SELECT period FROM payroll_periods WHERE period = ? FOR UPDATE`, value).Scan(&locked)
+	if err != nil {
+		return errors.Errorf("failed to lock payroll period=%s: %w", period.Format("2006-01"), err)
+	}
+	return nil
+}
+
+func (repository *PayrollRunRepository) Store(ctx context.Context, run *models.PayrollRun) (*models.PayrollRun, error) {
+	query := `
+		INSERT INTO payroll_runs(period, status, corrects_run_id, notes, created_by)
+		VALUES(?, ?, ?, ?, ?)
+	`
+	result, err := repository.db.ExecContext(
+		ctx,
+		query,
+		run.Period.Format("2006-01-02"),
+		run.Status,
+		run.CorrectsRunId,
+		run.Notes,
+		run.CreatedBy,
+	)
+	if err != nil {
+		return nil, errors.Errorf("failed to store payroll run: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, errors.Errorf("failed to get last id: %w", err)
+	}
+	return repository.GetById(ctx, int(id))
+}
+
+// UpdateTotals stores the summary of the payslips, only draft run is recalculated
+func (repository *PayrollRunRepository) UpdateTotals(ctx context.Context, run *models.PayrollRun) (int64, error) {
+	query := `
+		UPDATE payroll_runs
+		SET total_employee = ?, total_gross = ?, total_deduction = ?, total_net = ?
+		WHERE id = ? AND status = ?
+	`
+	result, err := repository.db.ExecContext(
+		ctx,
+		query,
+		run.TotalEmployee,
+		run.TotalGross,
+		run.TotalDeduction,
+		run.TotalNet,
+		run.Id,
+		models.PayrollStatusDraft,
+	)
+	if err != nil {
+		return 0, errors.Errorf("failed to update totals of payroll run id=%d: %w", run.Id, err)
+	}
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowAffected, nil
+}
+
+// UpdateStatus moves the run from one status to another, nothing is changed
+// when the run is no longer in the expected status (e.g. locked by another request)
+func (repository *PayrollRunRepository) UpdateStatus(ctx context.Context, run *models.PayrollRun, from string) (int64, error) {
+	query := `
+		UPDATE payroll_runs
+		SET status = ?, reviewed_by = ?, reviewed_at = ?, locked_by = ?, locked_at = ?
+		WHERE id = ? AND status = ?
+	`
+	result, err := repository.db.ExecContext(
+		ctx,
+		query,
+		run.Status,
+		run.ReviewedBy,
+		run.ReviewedAt,
+		run.LockedBy,
+		run.LockedAt,
+		run.Id,
+		from,
+	)
+	if err != nil {
+		return 0, errors.Errorf("failed to update status of payroll run id=%d: %w", run.Id, err)
+	}
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowAffected, nil
+}
+
+// Destroy deletes draft run with its payslips
+func (repository *PayrollRunRepository) Destroy(ctx context.Context, id int) (int64, error) {
+	result, err := repository.db.ExecContext(ctx, `DELETE FROM payroll_runs WHERE id = ? AND status = ?`, id, models.PayrollStatusDraft)
+	if err != nil {
+		return 0, errors.Errorf("failed to delete payroll run id=%d: %w", id, err)
+	}
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowAffected, nil
+}
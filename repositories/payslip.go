@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type PayslipRepository struct {
+	db database.Transaction
+}
+
+func NewPayslipRepository(db *sql.DB) *PayslipRepository {
+	return &PayslipRepository{db: db}
+}
+
+func (r *PayslipRepository) WithTx(tx *sql.Tx) *PayslipRepository {
+	return &PayslipRepository{
+		db: tx,
+	}
+}
+
+const payslipColumns = `
+	payslips.id, payslips.payroll_run_id, payslips.employee_id, payslips.employee_name, payslips.employee_email,
+	payslips.employee_tax_number, payslips.base_salary, payslips.total_allowance, payslips.total_deduction,
+	payslips.gross_pay, payslips.net_pay, payslips.created_at
+`
+
+func scanPayslip(row interface{ Scan(...any) error }, extra ...any) (*models.Payslip, error) {
+	var payslip models.Payslip
+	err := row.Scan(append([]any{
+		&payslip.Id,
+		&payslip.PayrollRunId,
+		&payslip.EmployeeId,
+		&payslip.EmployeeName,
+		&payslip.EmployeeEmail,
+		&payslip.EmployeeTaxNumber,
+		&payslip.BaseSalary,
+		&payslip.TotalAllowance,
+		&payslip.TotalDeduction,
+		&payslip.GrossPay,
+		&payslip.NetPay,
+		&payslip.CreatedAt,
+	}, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &payslip, nil
+}
+
+// GetByPayrollRunId returns payslips of the run ordered by employee name, items are not loaded
+func (repository *PayslipRepository) GetByPayrollRunId(ctx context.Context, payrollRunId int) (*[]models.Payslip, error) {
+	query := `SELECT ` + payslipColumns + ` FROM payslips WHERE payroll_run_id = ? ORDER BY employee_name, id`
+	rows, err := repository.db.QueryContext(ctx, query, payrollRunId)
+	if err != nil {
+		return nil, errors.Errorf("failed to query payslips of payroll run id=%d: %w", payrollRunId, err)
+	}
+	defer rows.Close()
+
+	payslips := []models.Payslip{}
+	for rows.Next() {
+		payslip, err := scanPayslip(rows)
+		if err != nil {
+			return nil, errors.Errorf("failed to get payslip rows: %w", err)
+		}
+		payslips = append(payslips, *payslip)
+	}
+	return &payslips, nil
+}
+
+// GetByEmployeeId returns payslips of the employee, latest period first
+func (repository *PayslipRepository) GetByEmployeeId(ctx context.Context, employeeId int) (*[]models.Payslip, error) {
+	query := `
+		SELECT ` + payslipColumns + `, payroll_runs.period, payroll_runs.status, payroll_runs.corrects_run_id IS NOT NULL
+		FROM payslips
+		INNER JOIN payroll_runs ON payroll_runs.id = payslips.payroll_run_id
+		WHERE payslips.employee_id = ?
+		ORDER BY payroll_runs.period DESC, payroll_runs.id DESC
+	`
+	rows, err := repository.db.QueryContext(ctx, query, employeeId)
+	if err != nil {
+		return nil, errors.Errorf("failed to query payslips of employee id=%d: %w", employeeId, err)
+	}
+	defer rows.Close()
+
+	payslips := []models.Payslip{}
+	for rows.Next() {
+		var period time.Time
+		var runStatus string
+		var isCorrection bool
+		payslip, err := scanPayslip(rows, &period, &runStatus, &isCorrection)
+		if err != nil {
+			return nil, errors.Errorf("failed to get payslip rows: %w", err)
+		}
+		payslip.Period = period
+		payslip.RunStatus = runStatus
+		payslip.IsCorrection = isCorrection
+		payslips = append(payslips, *payslip)
+	}
+	return &payslips, nil
+}
+
+// GetById returns payslip with its allowance and deduction items
+func (repository *PayslipRepository) GetById(ctx context.Context, id int) (*models.Payslip, error) {
+	query := `SELECT ` + payslipColumns + ` FROM payslips WHERE id = ?`
+	payslip, err := scanPayslip(repository.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, errors.Errorf("payslip not found id=%d: %w", id, err)
+	}
+
+	rows, err := repository.db.QueryContext(ctx, `
+		SELECT id, payslip_id, type, code, name, amount, is_taxable
+		FROM payslip_items WHERE payslip_id = ? ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, errors.Errorf("failed to query items of payslip id=%d: %w", id, err)
+	}
+	defer rows.Close()
+
+	payslip.Items = []models.PayslipItem{}
+	for rows.Next() {
+		var item models.PayslipItem
+		err = rows.Scan(&item.Id, &item.PayslipId, &item.Type, &item.Code, &item.Name, &item.Amount, &item.IsTaxable)
+		if err != nil {
+			return nil, errors.Errorf("failed to get payslip item rows: %w", err)
+		}
+		payslip.Items = append(payslip.Items, item)
+	}
+	return payslip, nil
+}
+
+// Store inserts the payslip and its items
+func (repository *PayslipRepository) Store(ctx context.Context, payslip *models.Payslip) (*models.Payslip, error) {
+	query := `
+		INSERT INTO payslips(
+			payroll_run_id, employee_id, employee_name, employee_email, employee_tax_number,
+			base_salary, total_allowance, total_deduction, gross_pay, net_pay
+		)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := repository.db.ExecContext(
+		ctx,
+		query,
+		payslip.PayrollRunId,
+		payslip.EmployeeId,
+		payslip.EmployeeName,
+		payslip.EmployeeEmail,
+		payslip.EmployeeTaxNumber,
+		payslip.BaseSalary,
+		payslip.TotalAllowance,
+		payslip.TotalDeduction,
+		payslip.GrossPay,
+		payslip.NetPay,
+	)
+	if err != nil {
+		return nil, errors.Errorf("failed to store payslip of employee %s: %w", payslip.EmployeeName, err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, errors.Errorf("failed to get last id: %w", err)
+	}
+	payslip.Id = int(id)
+
+	for i := range payslip.Items {
+		item := &payslip.Items[i]
+		item.PayslipId = payslip.Id
+		_, err := repository.db.ExecContext(
+			ctx,
+			`INSERT INTO payslip_items(payslip_id, type, code, name, amount, is_taxable) VALUES(?, ?, ?, ?, ?, ?)`,
+			item.PayslipId,
+			item.Type,
+			item.Code,
+			item.Name,
+			item.Amount,
+			item.IsTaxable,
+		)
+		if err != nil {
+			return nil, errors.Errorf("failed to store item %s of payslip id=%d: %w", item.Code, payslip.Id, err)
+		}
+	}
+	return payslip, nil
+}
+
+func (repository *PayslipRepository) DestroyByPayrollRunId(ctx context.Context, payrollRunId int) (int64, error) {
+	result, err := repository.db.ExecContext(ctx, `DELETE FROM payslips WHERE payroll_run_id = ?`, payrollRunId)
+	if err != nil {
+		return 0, errors.Errorf("failed to delete payslips of payroll run id=%d: %w", payrollRunId, err)
+	}
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowAffected, nil
+}
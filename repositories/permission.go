@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type PermissionRepository struct {
+	db database.Transaction
+}
+
+func NewPermissionRepository(db *sql.DB) *PermissionRepository {
+	return &PermissionRepository{db: db}
+}
+
+func (repository *PermissionRepository) GetAll(ctx context.Context) (*[]models.Permission, error) {
+	query := `SELECT id, name, description FROM permissions ORDER BY name`
+	rows, err := repository.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Errorf("failed to query permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var permissions []models.Permission
+	for rows.Next() {
+		var permission models.Permission
+		err = rows.Scan(&permission.Id, &permission.Name, &permission.Description)
+		if err != nil {
+			return nil, errors.Errorf("failed to get permission rows: %w", err)
+		}
+		permissions = append(permissions, permission)
+	}
+	return &permissions, nil
+}
+
+// GetNamesByRole returns permission names granted to role, role name is the user type
+func (repository *PermissionRepository) GetNamesByRole(ctx context.Context, roleName string) ([]string, error) {
+	query := `
+		SELECT permissions.name
+		FROM permissions
+		INNER JOIN role_permissions ON role_permissions.permission_id = permissions.id
+		INNER JOIN roles ON roles.id = role_permissions.role_id
+		WHERE roles.name = ?
+	`
+	rows, err := repository.db.QueryContext(ctx, query, roleName)
+	if err != nil {
+		return nil, errors.Errorf("failed to query permissions of role %s: %w", roleName, err)
+	}
+	defer rows.Close()
+
+	permissions := []string{}
+	for rows.Next() {
+		var permission string
+		if err = rows.Scan(&permission); err != nil {
+			return nil, errors.Errorf("failed to get permission rows: %w", err)
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, nil
+}
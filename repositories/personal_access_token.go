@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type PersonalAccessTokenRepository struct {
+	db database.Transaction
+}
+
+func NewPersonalAccessTokenRepository(db *sql.DB) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{db: db}
+}
+
+func (r *PersonalAccessTokenRepository) WithTx(tx *sql.Tx) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{
+		db: tx,
+	}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPersonalAccessToken(row rowScanner) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	var scopes string
+	err := row.Scan(
+		&token.Id,
+		&token.UserId,
+		&token.Name,
+		&token.Token,
+		&scopes,
+		&token.ExpiredAt,
+		&token.LastUsedAt,
+		&token.LastUsedIp,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	token.Scopes = []string{}
+	if scopes != "" {
+		token.Scopes = strings.Split(scopes, ",")
+	}
+	return &token, nil
+}
+
+func (repository *PersonalAccessTokenRepository) GetByUserId(ctx context.Context, userId int) (*[]models.PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, name, token, scopes, expired_at, last_used_at, last_used_ip, created_at
+		FROM personal_access_tokens
+		WHERE user_id = ?
+		ORDER BY id DESC
+	`
+	rows, err := repository.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, errors.Errorf("failed to query personal access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []models.PersonalAccessToken{}
+	for rows.Next() {
+		token, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, errors.Errorf("failed to get personal access token rows: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+
+	return &tokens, nil
+}
+
+func (repository *PersonalAccessTokenRepository) GetById(ctx context.Context, id int) (*models.PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, name, token, scopes, expired_at, last_used_at, last_used_ip, created_at
+		FROM personal_access_tokens WHERE id = ?
+	`
+	token, err := scanPersonalAccessToken(repository.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, errors.Errorf("personal access token not found: %w", err)
+	}
+	return token, nil
+}
+
+// GetByToken finds personal access token by the hashed token
+func (repository *PersonalAccessTokenRepository) GetByToken(ctx context.Context, hashedToken string) (*models.PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, name, token, scopes, expired_at, last_used_at, last_used_ip, created_at
+		FROM personal_access_tokens WHERE token = ?
+	`
+	token, err := scanPersonalAccessToken(repository.db.QueryRowContext(ctx, query, hashedToken))
+	if err != nil {
+		return nil, errors.Errorf("personal access token not found: %w", err)
+	}
+	return token, nil
+}
+
+func (repository *PersonalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) (*models.PersonalAccessToken, error) {
+	query := `
+		INSERT INTO personal_access_tokens(user_id, name, token, scopes, expired_at)
+		VALUES(?, ?, ?, ?, ?)
+	`
+	result, err := repository.db.ExecContext(
+		ctx,
+		query,
+		token.UserId,
+		token.Name,
+		token.Token,
+		strings.Join(token.Scopes, ","),
+		token.ExpiredAt,
+	)
+	if err != nil {
+		return nil, errors.Errorf("failed to store personal access token: %w", err)
+	}
+
+	tokenId, err := result.LastInsertId()
+	if err != nil {
+		return nil, errors.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return repository.GetById(ctx, int(tokenId))
+}
+
+// Touch records when and from where the token was used
+func (repository *PersonalAccessTokenRepository) Touch(ctx context.Context, id int, usedAt time.Time, ip string) error {
+	query := `UPDATE personal_access_tokens SET last_used_at = ?, last_used_ip = ? WHERE id = ?`
+	_, err := repository.db.ExecContext(ctx, query, usedAt, ip, id)
+	if err != nil {
+		return errors.Errorf("failed to update last used of personal access token id=%d: %w", id, err)
+	}
+	return nil
+}
+
+// Delete removes token owned by the user, returns sql.ErrNoRows when it does not exist
+func (repository *PersonalAccessTokenRepository) Delete(ctx context.Context, userId int, id int) error {
+	query := `DELETE FROM personal_access_tokens WHERE id = ? AND user_id = ?`
+	result, err := repository.db.ExecContext(ctx, query, id, userId)
+	if err != nil {
+		return errors.Errorf("failed to delete personal access token id=%d: %w", id, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return errors.Errorf("personal access token id=%d not found: %w", id, sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (repository *PersonalAccessTokenRepository) DeleteByUserId(ctx context.Context, userId int) error {
+	query := `DELETE FROM personal_access_tokens WHERE user_id = ?`
+	_, err := repository.db.ExecContext(ctx, query, userId)
+	if err != nil {
+		return errors.Errorf("failed to delete personal access tokens of user id=%d: %w", userId, err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type PositionRepository struct {
+	db database.Transaction
+}
+
+func NewPositionRepository(db *sql.DB) *PositionRepository {
+	return &PositionRepository{db: db}
+}
+
+func (r *PositionRepository) WithTx(tx *sql.Tx) *PositionRepository {
+	return &PositionRepository{
+		db: tx,
+	}
+}
+
+// GetAll returns positions ordered by name with number of active employees (trash excluded)
+func (repository *PositionRepository) GetAll(ctx context.Context) (*[]models.Position, error) {
+	query := `
+		SELECT
+			positions.id, name, description, created_at, updated_at,
+			COALESCE(employees.total, 0) AS total_employee
+		FROM positions
+		LEFT JOIN (
+			SELECT position_id, COUNT(*) AS total FROM employees
+			WHERE deleted_at IS NULL
+			GROUP BY position_id
+		) AS employees ON employees.position_id = positions.id
+		ORDER BY name
+	`
+	rows, err := repository.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Errorf("failed to query positions: %w", err)
+	}
+	defer rows.Close()
+
+	positions := []models.Position{}
+	for rows.Next() {
+		var position models.Position
+		err = rows.Scan(
+			&position.Id,
+			&position.Name,
+			&position.Description,
+			&position.CreatedAt,
+			&position.UpdatedAt,
+			&position.TotalEmployee,
+		)
+		if err != nil {
+			return nil, errors.Errorf("failed to get position rows: %w", err)
+		}
+		positions = append(positions, position)
+	}
+	return &positions, nil
+}
+
+func (repository *PositionRepository) GetById(ctx context.Context, id int) (*models.Position, error) {
+	return repository.findBy(ctx, "id", id)
+}
+
+func (repository *PositionRepository) GetByName(ctx context.Context, name string) (*models.Position, error) {
+	return repository.findBy(ctx, "name", name)
+}
+
+func (repository *PositionRepository) findBy(ctx context.Context, column string, value any) (*models.Position, error) {
+	query := `SELECT id, name, description, created_at, updated_at FROM positions WHERE ` + column + ` = ?`
+	var position models.Position
+	err := repository.db.QueryRowContext(ctx, query, value).Scan(
+		&position.Id,
+		&position.Name,
+		&position.Description,
+		&position.CreatedAt,
+		&position.UpdatedAt,
+	)
+	if err != nil {
+		return nil, errors.Errorf("position not found %s=%v: %w", column, value, err)
+	}
+	return &position, nil
+}
+
+func (repository *PositionRepository) Store(ctx context.Context, position *models.Position) (*models.Position, error) {
+	result, err := repository.db.ExecContext(ctx, `INSERT INTO positions(name, description) VALUES(?, ?)`, position.Name, position.Description)
+	if err != nil {
+		return nil, errors.Errorf("failed to store position: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, errors.Errorf("failed to get last id: %w", err)
+	}
+	return repository.GetById(ctx, int(id))
+}
+
+func (repository *PositionRepository) Update(ctx context.Context, position *models.Position) (*models.Position, error) {
+	_, err := repository.db.ExecContext(ctx, `UPDATE positions SET name = ?, description = ? WHERE id = ?`, position.Name, position.Description, position.Id)
+	if err != nil {
+		return nil, errors.Errorf("failed to update position id=%d: %w", position.Id, err)
+	}
+	return repository.GetById(ctx, position.Id)
+}
+
+// CountEmployees returns number of employees holding the position, including employees in trash
+func (repository *PositionRepository) CountEmployees(ctx context.Context, id int) (int, error) {
+	var total int
+	err := repository.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM employees WHERE position_id = ?`, id).Scan(&total)
+	if err != nil {
+		return 0, errors.Errorf("failed to count employees of position id=%d: %w", id, err)
+	}
+	return total, nil
+}
+
+func (repository *PositionRepository) Destroy(ctx context.Context, id int) (int64, error) {
+	result, err := repository.db.ExecContext(ctx, `DELETE FROM positions WHERE id = ?`, id)
+	if err != nil {
+		return 0, errors.Errorf("failed to delete position id=%d: %w", id, err)
+	}
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowAffected, nil
+}
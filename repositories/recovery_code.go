@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"gitlab.com/tozd/go/errors"
+)
+
+// RecoveryCodeRepository stores hashed two factor recovery codes
+type RecoveryCodeRepository struct {
+	db database.Transaction
+}
+
+func NewRecoveryCodeRepository(db *sql.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+func (r *RecoveryCodeRepository) WithTx(tx *sql.Tx) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		db: tx,
+	}
+}
+
+// Replace removes existing codes of the user and stores the new hashed codes
+func (repository *RecoveryCodeRepository) Replace(ctx context.Context, userId int, hashedCodes []string) error {
+	if err := repository.DeleteByUserId(ctx, userId); err != nil {
+		return err
+	}
+
+	statement, err := repository.db.PrepareContext(ctx, `INSERT INTO two_factor_recovery_codes(user_id, code) VALUES(?, ?)`)
+	if err != nil {
+		return errors.Errorf("failed to prepare statement: %w", err)
+	}
+	defer statement.Close()
+
+	for _, code := range hashedCodes {
+		if _, err := statement.ExecContext(ctx, userId, code); err != nil {
+			return errors.Errorf("failed to store recovery code of user id=%d: %w", userId, err)
+		}
+	}
+	return nil
+}
+
+// Use consumes the recovery code, returns sql.ErrNoRows when it does not exist or is already used
+func (repository *RecoveryCodeRepository) Use(ctx context.Context, userId int, hashedCode string, usedAt time.Time) error {
+	query := `
+		UPDATE two_factor_recovery_codes SET used_at = ?
+		WHERE user_id = ? AND code = ? AND used_at IS NULL
+	`
+	result, err := repository.db.ExecContext(ctx, query, usedAt, userId, hashedCode)
+	if err != nil {
+		return errors.Errorf("failed to use recovery code of user id=%d: %w", userId, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return errors.Errorf("recovery code of user id=%d not found: %w", userId, sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (repository *RecoveryCodeRepository) CountUnused(ctx context.Context, userId int) (int, error) {
+	query := `SELECT COUNT(*) FROM two_factor_recovery_codes WHERE user_id = ? AND used_at IS NULL`
+	var total int
+	if err := repository.db.QueryRowContext(ctx, query, userId).Scan(&total); err != nil {
+		return 0, errors.Errorf("failed to count recovery codes of user id=%d: %w", userId, err)
+	}
+	return total, nil
+}
+
+func (repository *RecoveryCodeRepository) DeleteByUserId(ctx context.Context, userId int) error {
+	_, err := repository.db.ExecContext(ctx, `DELETE FROM two_factor_recovery_codes WHERE user_id = ?`, userId)
+	if err != nil {
+		return errors.Errorf("failed to delete recovery codes of user id=%d: %w", userId, err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"gitlab.com/tozd/go/errors"
+)
+
+// RevokedTokenRepository stores jti of JWTs revoked before their expiration
+type RevokedTokenRepository struct {
+	db database.Transaction
+}
+
+func NewRevokedTokenRepository(db *sql.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db}
+}
+
+func (r *RevokedTokenRepository) WithTx(tx *sql.Tx) *RevokedTokenRepository {
+	return &RevokedTokenRepository{
+		db: tx,
+	}
+}
+
+func (repository *RevokedTokenRepository) Create(ctx context.Context, jti string, userId int, expiredAt time.Time) error {
+	query := `
+		INSERT INTO revoked_tokens(jti, user_id, expired_at)
+		VALUES(?, ?, ?)
+		ON DUPLICATE KEY UPDATE jti = jti
+	`
+	_, err := repository.db.ExecContext(ctx, query, jti, userId, expiredAt)
+	if err != nil {
+		return errors.Errorf("failed to store revoked token: %w", err)
+	}
+	return nil
+}
+
+func (repository *RevokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	query := `SELECT COUNT(*) FROM revoked_tokens WHERE jti = ?`
+	var total int
+	err := repository.db.QueryRowContext(ctx, query, jti).Scan(&total)
+	if err != nil {
+		return false, errors.Errorf("failed to query revoked token: %w", err)
+	}
+	return total > 0, nil
+}
+
+// DeleteExpired prunes tokens that are rejected by their exp claim anyway
+func (repository *RevokedTokenRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	query := `DELETE FROM revoked_tokens WHERE expired_at < ?`
+	_, err := repository.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return errors.Errorf("failed to delete expired revoked tokens: %w", err)
+	}
+	return nil
+}
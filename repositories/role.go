@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anggadarkprince/crud-employee-go/database"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"gitlab.com/tozd/go/errors"
+)
+
+type RoleRepository struct {
+	db database.Transaction
+}
+
+func NewRoleRepository(db *sql.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r *RoleRepository) WithTx(tx *sql.Tx) *RoleRepository {
+	return &RoleRepository{
+		db: tx,
+	}
+}
+
+func (repository *RoleRepository) GetAll(ctx context.Context) (*[]models.Role, error) {
+	query := `SELECT id, name, description, require_two_factor FROM roles ORDER BY id`
+	rows, err := repository.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Errorf("failed to query roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []models.Role
+	for rows.Next() {
+		var role models.Role
+		err = rows.Scan(&role.Id, &role.Name, &role.Description, &role.RequireTwoFactor)
+		if err != nil {
+			return nil, errors.Errorf("failed to get role rows: %w", err)
+		}
+		roles = append(roles, role)
+	}
+	rows.Close()
+
+	permissionRepository := &PermissionRepository{db: repository.db}
+	for i := range roles {
+		roles[i].Permissions, err = permissionRepository.GetNamesByRole(ctx, roles[i].Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &roles, nil
+}
+
+func (repository *RoleRepository) GetById(ctx context.Context, roleId int) (*models.Role, error) {
+	query := `SELECT id, name, description, require_two_factor FROM roles WHERE id = ?`
+	var role models.Role
+	err := repository.db.QueryRowContext(ctx, query, roleId).Scan(&role.Id, &role.Name, &role.Description, &role.RequireTwoFactor)
+	if err != nil {
+		return nil, errors.Errorf("role not found id=%d: %w", roleId, err)
+	}
+	return &role, nil
+}
+
+func (repository *RoleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	query := `SELECT id, name, description, require_two_factor FROM roles WHERE name = ?`
+	var role models.Role
+	err := repository.db.QueryRowContext(ctx, query, name).Scan(&role.Id, &role.Name, &role.Description, &role.RequireTwoFactor)
+	if err != nil {
+		return nil, errors.Errorf("role not found name=%s: %w", name, err)
+	}
+	return &role, nil
+}
+
+// SyncPermissions replaces permissions of the role with the given permission names
+func (repository *RoleRepository) SyncPermissions(ctx context.Context, roleId int, permissions []string) error {
+	_, err := repository.db.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = ?`, roleId)
+	if err != nil {
+		return errors.Errorf("failed to delete permissions of role id=%d: %w", roleId, err)
+	}
+
+	query := `
+		INSERT INTO role_permissions(role_id, permission_id)
+		SELECT ?, id FROM permissions WHERE name = ?
+	`
+	statement, err := repository.db.PrepareContext(ctx, query)
+	if err != nil {
+		return errors.Errorf("failed to prepare statement: %w", err)
+	}
+	defer statement.Close()
+
+	for _, permission := range permissions {
+		if _, err := statement.ExecContext(ctx, roleId, permission); err != nil {
+			return errors.Errorf("failed to store permission %s of role id=%d: %w", permission, roleId, err)
+		}
+	}
+	return nil
+}
+
+func (repository *RoleRepository) UpdateRequireTwoFactor(ctx context.Context, roleId int, required bool) error {
+	query := `UPDATE roles SET require_two_factor = ? WHERE id = ?`
+	_, err := repository.db.ExecContext(ctx, query, required, roleId)
+	if err != nil {
+		return errors.Errorf("failed to update two factor requirement of role id=%d: %w", roleId, err)
+	}
+	return nil
+}
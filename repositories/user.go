@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/anggadarkprince/crud-employee-go/database"
 	"github.com/anggadarkprince/crud-employee-go/models"
@@ -25,7 +26,8 @@ func (r *UserRepository) WithTx(tx *sql.Tx) *UserRepository {
 
 func (repository *UserRepository) GetAll(ctx context.Context) (*[]models.User, error) {
 	query := `
-		SELECT id, name, username, email, password, user_type, status, avatar
+		SELECT id, name, username, email, password, user_type, status, avatar, sessions_revoked_at,
+			two_factor_secret, two_factor_confirmed_at, two_factor_last_step
 		FROM users
 		ORDER BY id DESC
 	`
@@ -49,6 +51,10 @@ func (repository *UserRepository) GetAll(ctx context.Context) (*[]models.User, e
 			&user.UserType,
 			&user.Status,
 			&user.Avatar,
+			&user.SessionsRevokedAt,
+			&user.TwoFactorSecret,
+			&user.TwoFactorConfirmedAt,
+			&user.TwoFactorLastStep,
 		)
 		if err != nil {
 			return nil, errors.Errorf("failed to get user rows: %w", err)
@@ -75,6 +81,10 @@ func (repository *UserRepository) mapResultToUser(row *sql.Row) (*models.User, e
 		&user.UserType,
 		&user.Status,
 		&user.Avatar,
+		&user.SessionsRevokedAt,
+		&user.TwoFactorSecret,
+		&user.TwoFactorConfirmedAt,
+		&user.TwoFactorLastStep,
 	)
 	if err != nil {
 		return nil, errors.Errorf("user not found: %w", err)
@@ -85,7 +95,8 @@ func (repository *UserRepository) mapResultToUser(row *sql.Row) (*models.User, e
 
 func (repository *UserRepository) GetById(ctx context.Context, userId int) (*models.User, error) {
 	query := `
-		SELECT id, name, username, email, password, user_type, status, avatar
+		SELECT id, name, username, email, password, user_type, status, avatar, sessions_revoked_at,
+			two_factor_secret, two_factor_confirmed_at, two_factor_last_step
 		FROM users WHERE id = ?
 	`;
 	row := repository.db.QueryRowContext(ctx, query, userId)
@@ -95,7 +106,8 @@ func (repository *UserRepository) GetById(ctx context.Context, userId int) (*mod
 
 func (repository *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, name, username, email, password, user_type, status, avatar
+		SELECT id, name, username, email, password, user_type, status, avatar, sessions_revoked_at,
+			two_factor_secret, two_factor_confirmed_at, two_factor_last_step
 		FROM users WHERE email = ?
 	`;
 	row := repository.db.QueryRowContext(ctx, query, email)
@@ -105,7 +117,8 @@ func (repository *UserRepository) GetByEmail(ctx context.Context, email string)
 
 func (repository *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	query := `
-		SELECT id, name, username, email, password, user_type, status, avatar
+		SELECT id, name, username, email, password, user_type, status, avatar, sessions_revoked_at,
+			two_factor_secret, two_factor_confirmed_at, two_factor_last_step
 		FROM users WHERE username = ?
 	`;
 	row := repository.db.QueryRowContext(ctx, query, username)
@@ -163,4 +176,87 @@ func (repository *UserRepository) UpdateAccount(ctx context.Context, user *model
 	}
 
 	return repository.GetById(ctx, int(user.Id))
-}
\ No newline at end of file
+}
+
+func (repository *UserRepository) UpdateUserType(ctx context.Context, userId int, userType string) (*models.User, error) {
+	query := `UPDATE users SET user_type = ? WHERE id = ?`
+	_, err := repository.db.ExecContext(ctx, query, userType, userId)
+	if err != nil {
+		return nil, errors.Errorf("failed to update user type of user id=%d: %w", userId, err)
+	}
+
+	return repository.GetById(ctx, userId)
+}
+
+func (repository *UserRepository) UpdatePassword(ctx context.Context, userId int, password string) error {
+	query := `UPDATE users SET password = ? WHERE id = ?`
+	_, err := repository.db.ExecContext(ctx, query, password, userId)
+	if err != nil {
+		return errors.Errorf("failed to update password of user id=%d: %w", userId, err)
+	}
+	return nil
+}
+
+// RevokeSessions invalidates every auth token of the user issued before the given time
+func (repository *UserRepository) RevokeSessions(ctx context.Context, userId int, revokedAt time.Time) error {
+	// Truncated like the issued time of sessions, the database would round fractional seconds up
+	// and reject the session reissued in the same second
+	query := `UPDATE users SET sessions_revoked_at = ? WHERE id = ?`
+	_, err := repository.db.ExecContext(ctx, query, revokedAt.Truncate(time.Second), userId)
+	if err != nil {
+		return errors.Errorf("failed to revoke sessions of user id=%d: %w", userId, err)
+	}
+	return nil
+}
+
+func (repository *UserRepository) UpdateStatus(ctx context.Context, userId int, status string) (*models.User, error) {
+	query := `UPDATE users SET status = ? WHERE id = ?`
+	_, err := repository.db.ExecContext(ctx, query, status, userId)
+	if err != nil {
+		return nil, errors.Errorf("failed to update status of user id=%d: %w", userId, err)
+	}
+
+	return repository.GetById(ctx, userId)
+}
+
+// UpdateTwoFactorSecret stores a new unconfirmed secret, null secret disables two factor authentication
+func (repository *UserRepository) UpdateTwoFactorSecret(ctx context.Context, userId int, secret sql.NullString) error {
+	query := `
+		UPDATE users SET two_factor_secret = ?, two_factor_confirmed_at = NULL, two_factor_last_step = NULL
+		WHERE id = ?
+	`
+	_, err := repository.db.ExecContext(ctx, query, secret, userId)
+	if err != nil {
+		return errors.Errorf("failed to update two factor secret of user id=%d: %w", userId, err)
+	}
+	return nil
+}
+
+func (repository *UserRepository) ConfirmTwoFactor(ctx context.Context, userId int, confirmedAt time.Time, step int64) error {
+	query := `UPDATE users SET two_factor_confirmed_at = ?, two_factor_last_step = ? WHERE id = ?`
+	_, err := repository.db.ExecContext(ctx, query, confirmedAt, step, userId)
+	if err != nil {
+		return errors.Errorf("failed to confirm two factor of user id=%d: %w", userId, err)
+	}
+	return nil
+}
+
+// UseTwoFactorStep marks the time step as used, returns sql.ErrNoRows when the code was used already
+func (repository *UserRepository) UseTwoFactorStep(ctx context.Context, userId int, step int64) error {
+	query := `
+		UPDATE users SET two_factor_last_step = ?
+		WHERE id = ? AND (two_factor_last_step IS NULL OR two_factor_last_step < ?)
+	`
+	result, err := repository.db.ExecContext(ctx, query, step, userId, step)
+	if err != nil {
+		return errors.Errorf("failed to update two factor step of user id=%d: %w", userId, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return errors.Errorf("two factor code of user id=%d already used: %w", userId, sql.ErrNoRows)
+	}
+	return nil
+}
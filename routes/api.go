@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/middlewares"
+	"github.com/anggadarkprince/crud-employee-go/pkg/logger"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+	"github.com/go-playground/validator/v10"
+)
+
+// ApiHandlerFunc is like HandlerFunc but always reports errors as JSON
+type ApiHandlerFunc func(w http.ResponseWriter, r *http.Request) error
+func (h ApiHandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := h(w, r)
+	if err != nil {
+		renderJSONError(w, r, err)
+	}
+}
+
+func renderJSONError(w http.ResponseWriter, r *http.Request, err error) {
+	var appErr *exceptions.AppError
+
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		utilities.JSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"message": "Please check the data you provided.",
+			"errors": validation.FormatValidationErrors(validationErrors),
+		})
+	} else if validationErrors, ok := err.(*exceptions.ValidationError); ok {
+		utilities.JSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"message": validationErrors.Message,
+			"errors": validationErrors.Errors,
+		})
+	} else if errors.As(err, &appErr) {
+		utilities.JSON(w, appErr.Code, map[string]any{
+			"message": appErr.Message,
+		})
+	} else if errors.Is(err, sql.ErrNoRows) {
+		utilities.JSON(w, http.StatusNotFound, map[string]any{
+			"message": "Resource not found",
+		})
+	} else {
+		logger.LogError("Uncaught exception", err, r)
+
+		errorMessage := "Something went wrong"
+		if configs.Get().App.Environment != "production" {
+			errorMessage = err.Error()
+		}
+		utilities.JSON(w, http.StatusInternalServerError, map[string]any{
+			"message": errorMessage,
+		})
+	}
+}
+
+func apiGroup(auth *middlewares.Auth, routes map[string]http.Handler) map[string]http.Handler {
+	grouped := make(map[string]http.Handler)
+	for pattern, handler := range routes {
+		grouped[pattern] = auth.ApiMiddleware(handler)
+	}
+	return grouped
+}
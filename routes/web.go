@@ -11,7 +11,9 @@ import (
 	"github.com/anggadarkprince/crud-employee-go/exceptions"
 	"github.com/anggadarkprince/crud-employee-go/middlewares"
 	"github.com/anggadarkprince/crud-employee-go/pkg/logger"
+	"github.com/anggadarkprince/crud-employee-go/pkg/mail"
 	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/storage"
 	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
 	"github.com/anggadarkprince/crud-employee-go/repositories"
 	"github.com/anggadarkprince/crud-employee-go/services"
@@ -94,15 +96,45 @@ func registerRoutes(mux *http.ServeMux, routes map[string]http.Handler) {
     }
 }
 
-func MapRoutes(server *http.ServeMux, db *sql.DB) {
+// formOverhead leaves room for the other fields and multipart headers of upload forms
+const formOverhead = 1 << 20
+
+// BodyLimits caps request body of upload routes before the form is parsed, uploaded files are validated after
+var BodyLimits = map[string]int64{
+	"POST /account": validation.MaxAvatarSize + formOverhead,
+	"POST /employees/import": services.EmployeeImportMaxSize + formOverhead,
+	"POST /employees/{id}/documents": validation.MaxDocumentSize * validation.MaxDocumentFiles + formOverhead,
+}
+
+func MapRoutes(server *http.ServeMux, db *sql.DB, fileStorage storage.Storage, sessionStore session.Store) {
 	userRepository := repositories.NewUserRepository(db)
-	authService := services.NewAuthService(userRepository)
-	authController := controllers.NewAuthController(authService)
+	roleRepository := repositories.NewRoleRepository(db)
+	permissionRepository := repositories.NewPermissionRepository(db)
+	passwordResetRepository := repositories.NewPasswordResetRepository(db)
+	personalAccessTokenRepository := repositories.NewPersonalAccessTokenRepository(db)
+	revokedTokenRepository := repositories.NewRevokedTokenRepository(db)
+	mailer, err := mail.New(configs.Get().Mail)
+	if err != nil {
+		panic(err)
+	}
+	authService := services.NewAuthService(userRepository, passwordResetRepository, revokedTokenRepository, personalAccessTokenRepository, mailer, sessionStore, db)
+	failedLoginRepository := repositories.NewFailedLoginRepository(db)
+	loginThrottleService := services.NewLoginThrottleService(failedLoginRepository)
+	authController := controllers.NewAuthController(authService, loginThrottleService)
+	errorController := controllers.NewErrorController()
 
 	auth := &middlewares.Auth{
 		UserRepository: userRepository,
+		PermissionRepository: permissionRepository,
+		PersonalAccessTokenRepository: personalAccessTokenRepository,
+		RevokedTokenRepository: revokedTokenRepository,
+		RoleRepository: roleRepository,
+		SessionStore: sessionStore,
 		SecretKey: configs.Get().Auth.JwtSecret,
+		ForbiddenHandler: HandlerFunc(errorController.Forbidden),
 	}
+	can := auth.PermissionMiddleware
+	sessionOnly := auth.SessionMiddleware
 
 	// Guest routes
 	registerRoutes(server, guestGroup(auth, map[string]http.Handler{
@@ -110,41 +142,171 @@ func MapRoutes(server *http.ServeMux, db *sql.DB) {
         "POST /login": HandlerFunc(authController.Authenticate),
         "GET /register": HandlerFunc(authController.Register),
         "POST /register": HandlerFunc(authController.RegisterUser),
+		"GET /forgot-password": HandlerFunc(authController.ForgotPassword),
+		"POST /forgot-password": HandlerFunc(authController.SendResetLink),
+		"GET /reset-password/{token}": HandlerFunc(authController.ResetPassword),
+		"POST /reset-password": HandlerFunc(authController.UpdatePassword),
+		"POST /verify-email/resend": HandlerFunc(authController.ResendVerification),
 	}))
-	server.Handle("GET /logout", auth.AuthMiddleware(HandlerFunc(authController.Logout)))
+	server.Handle("GET /verify-email/{token}", HandlerFunc(authController.VerifyEmail))
+	server.Handle("POST /logout", auth.AuthMiddleware(HandlerFunc(authController.Logout)))
 
 	dashboardRepository := repositories.NewDashboardRepository(db)
 	dashboardService := services.NewDashboardService(dashboardRepository)
-	dashboardController := controllers.NewDashboardController(dashboardService)
+	auditLogRepository := repositories.NewAuditLogRepository(db)
+	employeeRepository := repositories.NewEmployeeRepository(db)
+	employeeDocumentRepository := repositories.NewEmployeeDocumentRepository(db)
+	employeeDocumentService := services.NewEmployeeDocumentService(employeeDocumentRepository, employeeRepository, auditLogRepository, fileStorage, db)
+	dashboardController := controllers.NewDashboardController(dashboardService, employeeDocumentService)
 	server.Handle("GET /{$}", auth.AuthMiddleware(HandlerFunc(dashboardController.Index)))
 	server.Handle("GET /dashboard", auth.AuthMiddleware(HandlerFunc(dashboardController.Index)))
+	fileController := controllers.NewFileController(fileStorage)
+	server.Handle("GET /files/{path...}", auth.SignedOrAuthMiddleware(HandlerFunc(fileController.Show)))
+	server.Handle("GET /dashboard/analytics", auth.AuthMiddleware(can("employees.view", ApiHandlerFunc(dashboardController.Analytics))))
 
-	employeeRepository := repositories.NewEmployeeRepository(db)
 	employeeAllowanceRepository := repositories.NewEmployeeAllowanceRepository(db)
+	allowanceTypeRepository := repositories.NewAllowanceTypeRepository(db)
+	departmentRepository := repositories.NewDepartmentRepository(db)
+	positionRepository := repositories.NewPositionRepository(db)
 	employeeService := services.NewEmployeeService(
 		employeeRepository,
 		employeeAllowanceRepository,
+		allowanceTypeRepository,
+		departmentRepository,
+		positionRepository,
+		repositories.NewEmployeeStatusHistoryRepository(db),
+		employeeDocumentRepository,
+		auditLogRepository,
+		fileStorage,
 		db,
 	)
 	employeeAllowanceService := services.NewEmployeeAllowanceService(
 		employeeAllowanceRepository,
 	)
-	employeeController := controllers.NewEmployeeController(employeeService, employeeAllowanceService)
+	allowanceTypeService := services.NewAllowanceTypeService(allowanceTypeRepository)
+	allowanceTypeController := controllers.NewAllowanceTypeController(allowanceTypeService)
+	departmentService := services.NewDepartmentService(departmentRepository, db)
+	departmentController := controllers.NewDepartmentController(departmentService)
+	positionService := services.NewPositionService(positionRepository)
+	positionController := controllers.NewPositionController(positionService)
+	auditLogService := services.NewAuditLogService(auditLogRepository)
+	payrollService := services.NewPayrollService(
+		repositories.NewPayrollRunRepository(db),
+		repositories.NewPayslipRepository(db),
+		employeeRepository,
+		employeeAllowanceRepository,
+		auditLogRepository,
+		db,
+	)
+	payrollController := controllers.NewPayrollController(payrollService)
+	employeeDocumentController := controllers.NewEmployeeDocumentController(employeeDocumentService)
+	employeeController := controllers.NewEmployeeController(employeeService, employeeAllowanceService, allowanceTypeService, auditLogService, payrollService, departmentService, positionService, employeeDocumentService)
+	employeeApiController := controllers.NewEmployeeApiController(employeeService, employeeAllowanceService)
+	employeeImportService := services.NewEmployeeImportService(employeeService, allowanceTypeService, departmentService, positionService)
+	employeeImportController := controllers.NewEmployeeImportController(employeeImportService)
+	employeeExportService := services.NewEmployeeExportService(employeeRepository)
+	employeeExportController := controllers.NewEmployeeExportController(employeeExportService)
+
+	userService := services.NewUserService(userRepository, roleRepository, personalAccessTokenRepository, auditLogRepository, fileStorage, db)
+	roleService := services.NewRoleService(roleRepository, permissionRepository, db)
+	personalAccessTokenService := services.NewPersonalAccessTokenService(personalAccessTokenRepository)
+	recoveryCodeRepository := repositories.NewRecoveryCodeRepository(db)
+	twoFactorService := services.NewTwoFactorService(userRepository, roleRepository, recoveryCodeRepository, db)
+	accountController := controllers.NewAccountController(userService, authService, personalAccessTokenService, twoFactorService)
+	twoFactorController := controllers.NewTwoFactorController(authService, userService, twoFactorService, loginThrottleService)
+	userController := controllers.NewUserController(userService, roleService)
+	roleController := controllers.NewRoleController(roleService)
+	failedLoginController := controllers.NewFailedLoginController(loginThrottleService)
+	auditLogController := controllers.NewAuditLogController(auditLogService, userService)
 
-	userService := services.NewUserService(userRepository, db)
-	accountController := controllers.NewAccountController(userService)
+	// Second step of login, user is still a guest until the code is verified
+	registerRoutes(server, guestGroup(auth, map[string]http.Handler{
+		"GET /two-factor-challenge": HandlerFunc(twoFactorController.Challenge),
+		"POST /two-factor-challenge": HandlerFunc(twoFactorController.VerifyChallenge),
+	}))
 
 	// Auth-protected routes
     registerRoutes(server, authGroup(auth, map[string]http.Handler{
-        "GET /employees": HandlerFunc(employeeController.Index),
-        "GET /employees/create": HandlerFunc(employeeController.Create),
-        "POST /employees": HandlerFunc(employeeController.Store),
-        "GET /employees/{id}": HandlerFunc(employeeController.View),
-        "GET /employees/{id}/edit": HandlerFunc(employeeController.Edit),
-        "PUT /employees/{id}": HandlerFunc(employeeController.Update),
-        "DELETE /employees/{id}": HandlerFunc(employeeController.Delete),
-
-		"GET /account": HandlerFunc(accountController.Index),
-		"PUT /account": HandlerFunc(accountController.Update),
+        "GET /employees": can("employees.view", HandlerFunc(employeeController.Index)),
+        "GET /employees/create": can("employees.create", HandlerFunc(employeeController.Create)),
+        "POST /employees": can("employees.create", HandlerFunc(employeeController.Store)),
+        "GET /employees/{id}": can("employees.view", HandlerFunc(employeeController.View)),
+        "GET /employees/{id}/edit": can("employees.edit", HandlerFunc(employeeController.Edit)),
+        "PUT /employees/{id}": can("employees.edit", HandlerFunc(employeeController.Update)),
+        "PUT /employees/{id}/status": can("employees.edit", HandlerFunc(employeeController.ChangeStatus)),
+        "DELETE /employees/{id}": can("employees.delete", HandlerFunc(employeeController.Delete)),
+        "POST /employees/{id}/documents": can("employees.edit", HandlerFunc(employeeDocumentController.Store)),
+        "GET /employees/{id}/documents/{documentId}": can("employees.view", HandlerFunc(employeeDocumentController.Download)),
+        "DELETE /employees/{id}/documents/{documentId}": can("employees.edit", HandlerFunc(employeeDocumentController.Delete)),
+        "GET /employees/org-chart": can("employees.view", HandlerFunc(employeeController.OrgChart)),
+        "GET /employees/trash": can("employees.delete", HandlerFunc(employeeController.Trash)),
+        "PUT /employees/{id}/restore": can("employees.delete", HandlerFunc(employeeController.Restore)),
+        "DELETE /employees/{id}/purge": can("employees.delete", HandlerFunc(employeeController.Purge)),
+        "GET /allowance-types": can("allowances.manage", HandlerFunc(allowanceTypeController.Index)),
+        "GET /allowance-types/create": can("allowances.manage", HandlerFunc(allowanceTypeController.Create)),
+        "POST /allowance-types": can("allowances.manage", HandlerFunc(allowanceTypeController.Store)),
+        "GET /allowance-types/{id}/edit": can("allowances.manage", HandlerFunc(allowanceTypeController.Edit)),
+        "PUT /allowance-types/{id}": can("allowances.manage", HandlerFunc(allowanceTypeController.Update)),
+        "DELETE /allowance-types/{id}": can("allowances.manage", HandlerFunc(allowanceTypeController.Delete)),
+        "GET /departments": can("organization.manage", HandlerFunc(departmentController.Index)),
+        "GET /departments/create": can("organization.manage", HandlerFunc(departmentController.Create)),
+        "POST /departments": can("organization.manage", HandlerFunc(departmentController.Store)),
+        "GET /departments/{id}/edit": can("organization.manage", HandlerFunc(departmentController.Edit)),
+        "PUT /departments/{id}": can("organization.manage", HandlerFunc(departmentController.Update)),
+        "DELETE /departments/{id}": can("organization.manage", HandlerFunc(departmentController.Delete)),
+        "GET /positions": can("organization.manage", HandlerFunc(positionController.Index)),
+        "GET /positions/create": can("organization.manage", HandlerFunc(positionController.Create)),
+        "POST /positions": can("organization.manage", HandlerFunc(positionController.Store)),
+        "GET /positions/{id}/edit": can("organization.manage", HandlerFunc(positionController.Edit)),
+        "PUT /positions/{id}": can("organization.manage", HandlerFunc(positionController.Update)),
+        "DELETE /positions/{id}": can("organization.manage", HandlerFunc(positionController.Delete)),
+        "GET /employees/export": can("employees.view", HandlerFunc(employeeExportController.Export)),
+        "GET /employees/import": can("employees.create", HandlerFunc(employeeImportController.Index)),
+        "POST /employees/import": can("employees.create", HandlerFunc(employeeImportController.Preview)),
+        "POST /employees/import/confirm": can("employees.create", HandlerFunc(employeeImportController.Confirm)),
+        "GET /employees/import/template": can("employees.create", HandlerFunc(employeeImportController.Template)),
+        "GET /payroll": can("payroll.view", HandlerFunc(payrollController.Index)),
+        "POST /payroll": can("payroll.manage", HandlerFunc(payrollController.Store)),
+        "GET /payroll/{id}": can("payroll.view", HandlerFunc(payrollController.View)),
+        "PUT /payroll/{id}/recalculate": can("payroll.manage", HandlerFunc(payrollController.Recalculate)),
+        "PUT /payroll/{id}/review": can("payroll.manage", HandlerFunc(payrollController.Review)),
+        "PUT /payroll/{id}/reopen": can("payroll.manage", HandlerFunc(payrollController.Reopen)),
+        "PUT /payroll/{id}/lock": can("payroll.manage", HandlerFunc(payrollController.Lock)),
+        "POST /payroll/{id}/correction": can("payroll.manage", HandlerFunc(payrollController.Correct)),
+        "DELETE /payroll/{id}": can("payroll.manage", HandlerFunc(payrollController.Delete)),
+        "GET /payroll/{id}/payslips/{payslipId}": can("payroll.view", HandlerFunc(payrollController.Payslip)),
+        "GET /payroll/{id}/payslips/{payslipId}/pdf": can("payroll.view", HandlerFunc(payrollController.PayslipPdf)),
+
+		"GET /account": sessionOnly(HandlerFunc(accountController.Index)),
+		"PUT /account": sessionOnly(HandlerFunc(accountController.Update)),
+		"POST /account/logout-everywhere": sessionOnly(HandlerFunc(authController.LogoutEverywhere)),
+		"POST /account/tokens": sessionOnly(HandlerFunc(accountController.StoreToken)),
+		"DELETE /account/tokens/{id}": sessionOnly(HandlerFunc(accountController.DeleteToken)),
+		"DELETE /account/sessions/{id}": sessionOnly(HandlerFunc(accountController.DeleteSession)),
+		"POST /account/two-factor": sessionOnly(HandlerFunc(twoFactorController.Enable)),
+		"POST /account/two-factor/confirm": sessionOnly(HandlerFunc(twoFactorController.Confirm)),
+		"POST /account/two-factor/recovery-codes": sessionOnly(HandlerFunc(twoFactorController.RegenerateRecoveryCodes)),
+		"DELETE /account/two-factor": sessionOnly(HandlerFunc(twoFactorController.Disable)),
+
+		"GET /users": can("users.manage", HandlerFunc(userController.Index)),
+		"PUT /users/{id}/role": can("users.manage", HandlerFunc(userController.UpdateRole)),
+		"PUT /users/{id}/status": can("users.manage", HandlerFunc(userController.UpdateStatus)),
+		"GET /roles": can("users.manage", HandlerFunc(roleController.Index)),
+		"PUT /roles/{id}": can("users.manage", HandlerFunc(roleController.Update)),
+		"GET /failed-logins": can("users.manage", HandlerFunc(failedLoginController.Index)),
+		"DELETE /failed-logins": can("users.manage", HandlerFunc(failedLoginController.Delete)),
+		"GET /audit": can("audit.view", HandlerFunc(auditLogController.Index)),
     }))
+
+	// API v1 routes, authenticated by bearer token
+	registerRoutes(server, apiGroup(auth, map[string]http.Handler{
+		"GET /api/v1/employees": can("employees.view", ApiHandlerFunc(employeeApiController.Index)),
+		"POST /api/v1/employees": can("employees.create", ApiHandlerFunc(employeeApiController.Store)),
+		"GET /api/v1/employees/{id}": can("employees.view", ApiHandlerFunc(employeeApiController.View)),
+		"PUT /api/v1/employees/{id}": can("employees.edit", ApiHandlerFunc(employeeApiController.Update)),
+		"PUT /api/v1/employees/{id}/status": can("employees.edit", ApiHandlerFunc(employeeApiController.ChangeStatus)),
+		"DELETE /api/v1/employees/{id}": can("employees.delete", ApiHandlerFunc(employeeApiController.Delete)),
+		"GET /api/v1/employees/{id}/allowances": can("employees.view", ApiHandlerFunc(employeeApiController.Allowances)),
+		"GET /api/v1/dashboard": can("employees.view", ApiHandlerFunc(dashboardController.Analytics)),
+	}))
 }
\ No newline at end of file
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"gitlab.com/tozd/go/errors"
+)
+
+type AllowanceTypeService struct {
+	allowanceTypeRepository *repositories.AllowanceTypeRepository
+}
+
+func NewAllowanceTypeService(allowanceTypeRepository *repositories.AllowanceTypeRepository) *AllowanceTypeService {
+	return &AllowanceTypeService{allowanceTypeRepository: allowanceTypeRepository}
+}
+
+func (service *AllowanceTypeService) GetAll(ctx context.Context, activeOnly bool) (*[]models.AllowanceType, error) {
+	return service.allowanceTypeRepository.GetAll(ctx, activeOnly)
+}
+
+func (service *AllowanceTypeService) GetById(ctx context.Context, id int) (*models.AllowanceType, error) {
+	return service.allowanceTypeRepository.GetById(ctx, id)
+}
+
+func (service *AllowanceTypeService) Store(ctx context.Context, data *dto.CreateAllowanceTypeRequest) (*models.AllowanceType, error) {
+	if err := service.ensureUniqueCode(ctx, data.Code, 0); err != nil {
+		return nil, err
+	}
+	return service.allowanceTypeRepository.Store(ctx, &models.AllowanceType{
+		Code: data.Code,
+		Name: data.Name,
+		DefaultAmount: data.DefaultAmount,
+		IsTaxable: data.IsTaxable,
+		IsActive: data.IsActive,
+	})
+}
+
+func (service *AllowanceTypeService) Update(ctx context.Context, data *dto.UpdateAllowanceTypeRequest) (*models.AllowanceType, error) {
+	if _, err := service.allowanceTypeRepository.GetById(ctx, data.Id); err != nil {
+		return nil, err
+	}
+	if err := service.ensureUniqueCode(ctx, data.Code, data.Id); err != nil {
+		return nil, err
+	}
+	return service.allowanceTypeRepository.Update(ctx, &models.AllowanceType{
+		Id: data.Id,
+		Code: data.Code,
+		Name: data.Name,
+		DefaultAmount: data.DefaultAmount,
+		IsTaxable: data.IsTaxable,
+		IsActive: data.IsActive,
+	})
+}
+
+// Destroy deletes allowance type that is not assigned to any employee,
+// used one should be deactivated so the employee history is kept
+func (service *AllowanceTypeService) Destroy(ctx context.Context, id int) (*models.AllowanceType, error) {
+	allowanceType, err := service.allowanceTypeRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	total, err := service.allowanceTypeRepository.CountUsage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if total > 0 {
+		return nil, &exceptions.AppError{
+			Code: http.StatusConflict,
+			Message: fmt.Sprintf("Allowance %s is assigned to %d employees, deactivate it instead", allowanceType.Name, total),
+		}
+	}
+	if _, err := service.allowanceTypeRepository.Destroy(ctx, id); err != nil {
+		return nil, err
+	}
+	return allowanceType, nil
+}
+
+func (service *AllowanceTypeService) ensureUniqueCode(ctx context.Context, code string, exceptId int) error {
+	existing, err := service.allowanceTypeRepository.GetByCode(ctx, code)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if existing != nil && existing.Id != exceptId {
+		return &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{"code": fmt.Sprintf("Code %s is already used by %s", code, existing.Name)},
+		}
+	}
+	return nil
+}
+
+// allowanceTypeLookup finds allowance type by code or by name case-insensitively,
+// so forms (code) and imported files (name) resolve the same way
+type allowanceTypeLookup map[string]*models.AllowanceType
+
+func newAllowanceTypeLookup(allowanceTypes []models.AllowanceType) allowanceTypeLookup {
+	lookup := allowanceTypeLookup{}
+	for i := range allowanceTypes {
+		lookup[strings.ToUpper(allowanceTypes[i].Code)] = &allowanceTypes[i]
+		lookup[strings.ToUpper(allowanceTypes[i].Name)] = &allowanceTypes[i]
+	}
+	return lookup
+}
+
+func (lookup allowanceTypeLookup) find(value string) (*models.AllowanceType, bool) {
+	allowanceType, ok := lookup[strings.ToUpper(strings.TrimSpace(value))]
+	return allowanceType, ok
+}
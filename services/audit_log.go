@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/audit"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type AuditLogService struct {
+	auditLogRepository *repositories.AuditLogRepository
+}
+
+func NewAuditLogService(auditLogRepository *repositories.AuditLogRepository) *AuditLogService {
+	return &AuditLogService{auditLogRepository: auditLogRepository}
+}
+
+func (service *AuditLogService) Paginate(ctx context.Context, filter *dto.AuditLogFilter) (*[]models.AuditLog, int, error) {
+	return service.auditLogRepository.Paginate(ctx, filter)
+}
+
+// recordAuditLog stores changed values of the entity with the actor from context,
+// pass repository bound to the transaction of the change so both are committed together.
+// Nothing is stored when an update does not change any value.
+func recordAuditLog(
+	ctx context.Context,
+	auditLogRepository *repositories.AuditLogRepository,
+	action string,
+	entityType string,
+	entityId int,
+	before audit.Values,
+	after audit.Values,
+) error {
+	oldValues, newValues := audit.Diff(before, after)
+	if action == models.AuditActionUpdated && len(newValues) == 0 {
+		return nil
+	}
+
+	encodedOldValues, err := encodeAuditValues(oldValues)
+	if err != nil {
+		return err
+	}
+	encodedNewValues, err := encodeAuditValues(newValues)
+	if err != nil {
+		return err
+	}
+
+	actor := audit.ActorFromContext(ctx)
+	userAgent := utilities.TruncateString(actor.UserAgent, 255)
+	return auditLogRepository.Create(ctx, &models.AuditLog{
+		UserId: sql.NullInt64{Int64: int64(actor.UserId), Valid: actor.UserId > 0},
+		Action: action,
+		EntityType: entityType,
+		EntityId: entityId,
+		OldValues: encodedOldValues,
+		NewValues: encodedNewValues,
+		IpAddress: sql.NullString{String: actor.IpAddress, Valid: actor.IpAddress != ""},
+		UserAgent: sql.NullString{String: userAgent, Valid: userAgent != ""},
+	})
+}
+
+func encodeAuditValues(values audit.Values) (sql.NullString, error) {
+	if values == nil {
+		return sql.NullString{}, nil
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
+// employeeAuditValues is snapshot of the audited employee fields including its allowances,
+// allowance with overridden amount is recorded as "Housing (1,500.00)"
+func employeeAuditValues(employee *models.Employee, employeeAllowances []models.EmployeeAllowance) audit.Values {
+	resource := dto.NewEmployeeResource(employee)
+	allowances := make([]string, 0, len(employeeAllowances))
+	for _, employeeAllowance := range employeeAllowances {
+		if employeeAllowance.Amount.Valid {
+			allowances = append(allowances, fmt.Sprintf("%s (%s)", employeeAllowance.Allowance, utilities.FormatMoney(employeeAllowance.Amount.Money)))
+		} else {
+			allowances = append(allowances, employeeAllowance.Allowance)
+		}
+	}
+	// Sorted so reordering the same allowances is not a change
+	slices.Sort(allowances)
+	return audit.Values{
+		"name": resource.Name,
+		"email": resource.Email,
+		"tax_number": resource.TaxNumber,
+		"gender": resource.Gender,
+		"hired_date": resource.HiredDate,
+		"address": resource.Address,
+		"status": resource.Status,
+		"base_salary": utilities.FormatMoney(employee.BaseSalary),
+		"department": resource.Department,
+		"position": resource.Position,
+		"manager": resource.Manager,
+		"allowances": allowances,
+	}
+}
+
+// userAuditValues is snapshot of the audited user fields, secrets are never stored
+func userAuditValues(user *models.User) audit.Values {
+	return audit.Values{
+		"name": user.Name,
+		"username": user.Username,
+		"email": user.Email,
+		"user_type": user.UserType,
+		"status": user.Status,
+		"avatar": user.Avatar.String,
+	}
+}
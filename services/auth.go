@@ -4,30 +4,61 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"net/mail"
+	"fmt"
+	"net/http"
+	netmail "net/mail"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/anggadarkprince/crud-employee-go/configs"
 	"github.com/anggadarkprince/crud-employee-go/dto"
 	"github.com/anggadarkprince/crud-employee-go/exceptions"
 	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/mail"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/signature"
 	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
 	userRepository *repositories.UserRepository
+	passwordResetRepository *repositories.PasswordResetRepository
+	revokedTokenRepository *repositories.RevokedTokenRepository
+	personalAccessTokenRepository *repositories.PersonalAccessTokenRepository
+	mailer mail.Mailer
+	// sessionStore is nil when the session cookie is a stateless JWT
+	sessionStore session.Store
+	db *sql.DB
 }
 
-func NewAuthService(userRepository *repositories.UserRepository) *AuthService {
-	return &AuthService{userRepository: userRepository}
+func NewAuthService(
+	userRepository *repositories.UserRepository,
+	passwordResetRepository *repositories.PasswordResetRepository,
+	revokedTokenRepository *repositories.RevokedTokenRepository,
+	personalAccessTokenRepository *repositories.PersonalAccessTokenRepository,
+	mailer mail.Mailer,
+	sessionStore session.Store,
+	db *sql.DB,
+) *AuthService {
+	return &AuthService{
+		userRepository: userRepository,
+		passwordResetRepository: passwordResetRepository,
+		revokedTokenRepository: revokedTokenRepository,
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		mailer: mailer,
+		sessionStore: sessionStore,
+		db: db,
+	}
 }
 
 func (service *AuthService) Authenticate(ctx context.Context, username string, password string) (*models.User, error) {
 	isEmail := true
-	if _, err := mail.ParseAddress(username); err != nil {
+	if _, err := netmail.ParseAddress(username); err != nil {
         isEmail = false
     }
 	
@@ -46,15 +77,17 @@ func (service *AuthService) Authenticate(ctx context.Context, username string, p
 		return nil, err
 	}
 
-	if user.Status != "ACTIVATED" {
-		return nil, exceptions.ErrUserInactive
-	} 
-
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
     if err != nil {
 		return nil, exceptions.ErrWrongPassword
 	}
 
+	// Password is checked first so account status is only disclosed to its owner,
+	// the user is returned along with the error to tell PENDING from SUSPENDED
+	if user.Status != "ACTIVATED" {
+		return user, exceptions.ErrUserInactive
+	}
+
 	return user, nil	
 }
 
@@ -71,22 +104,385 @@ func (service *AuthService) Register(ctx context.Context, data *dto.RegisterUser
         Username: data.Username,
         Password: string(hashedPassword),
         UserType: "EXTERNAL",
-        Status: "ACTIVATED",
+        Status: "PENDING",
     }
 	user, err := service.userRepository.Create(ctx, userModel)
 	if err != nil {
 		return nil, err
 	}
+
+	// Account is activated once the email address is verified
+	if err := service.SendVerificationEmail(ctx, user); err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
+// signUserToken creates "{user id}.{expiration}.{signature}" token for the purpose,
+// the email is signed too so the token is void once the email changes
+func signUserToken(purpose string, user *models.User, exp time.Time) string {
+	payload := fmt.Sprintf("%d.%d", user.Id, exp.Unix())
+	return payload + "." + signature.Sign(purpose + "|" + payload + "|" + user.Email)
+}
+
+// parseUserToken verifies the token signed by signUserToken and returns its user
+func (service *AuthService) parseUserToken(ctx context.Context, purpose string, token string) (*models.User, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, exceptions.ErrInvalidSignedToken
+	}
+	userId, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, exceptions.ErrInvalidSignedToken
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return nil, exceptions.ErrInvalidSignedToken
+	}
+
+	user, err := service.userRepository.GetById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, exceptions.ErrInvalidSignedToken
+		}
+		return nil, err
+	}
+	if !signature.Verify(purpose + "|" + parts[0] + "." + parts[1] + "|" + user.Email, parts[2]) {
+		return nil, exceptions.ErrInvalidSignedToken
+	}
 	return user, nil
 }
 
+func (service *AuthService) SendVerificationEmail(ctx context.Context, user *models.User) error {
+	expired := time.Duration(configs.Get().Auth.VerifyExpired) * time.Second
+	token := signUserToken("verify-email", user, time.Now().Add(expired))
+
+	body, err := utilities.RenderMail("mails/verify_email.html", map[string]any{
+		"app": configs.Get().App,
+		"user": user,
+		"url": fmt.Sprintf("%s/verify-email/%s", configs.Get().App.Url, token),
+		"expiredHours": int(expired.Hours()),
+	})
+	if err != nil {
+		return err
+	}
+
+	return service.mailer.Send(ctx, mail.Message{
+		To: user.Email,
+		Subject: "Verify Email Address",
+		Body: body,
+	})
+}
+
+// VerifyEmail activates pending user of the signed verification token
+func (service *AuthService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	user, err := service.parseUserToken(ctx, "verify-email", token)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != "PENDING" {
+		return user, nil
+	}
+	return service.userRepository.UpdateStatus(ctx, user.Id, "ACTIVATED")
+}
+
+// ResendToken allows a pending user who just entered the right password to request another verification mail
+func (service *AuthService) ResendToken(user *models.User) string {
+	return signUserToken("resend-verification", user, time.Now().Add(10 * time.Minute))
+}
+
+func (service *AuthService) ResendVerificationEmail(ctx context.Context, resendToken string) error {
+	user, err := service.parseUserToken(ctx, "resend-verification", resendToken)
+	if err != nil {
+		return err
+	}
+	if user.Status != "PENDING" {
+		return nil
+	}
+	return service.SendVerificationEmail(ctx, user)
+}
+
 func (service *AuthService) GenerateAuthToken(userId int, exp int64) (string, error)  {
+    jti, err := utilities.RandomToken(16)
+    if err != nil {
+        return "", err
+    }
+
     claims := jwt.MapClaims{
         "sub": strconv.Itoa(userId), // subject: user id
         "exp": exp,
         "iat": time.Now().Unix(), // issued at
+        "jti": jti, // token id, used to revoke the token before it expires
     }
 
     token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
     return token.SignedString([]byte(configs.Get().Auth.JwtSecret))
-}
\ No newline at end of file
+}
+
+// parseAuthToken validates signature and expiration of the token and returns its claims
+func (service *AuthService) parseAuthToken(authToken string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(authToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(configs.Get().Auth.JwtSecret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// StartSession issues the auth token of a new login, it is a JWT unless sessions are kept in the session store
+func (service *AuthService) StartSession(ctx context.Context, userId int, expiresAt time.Time, ipAddress string, userAgent string) (string, error) {
+	if service.sessionStore == nil {
+		return service.GenerateAuthToken(userId, expiresAt.Unix())
+	}
+	token, err := session.NewToken()
+	if err != nil {
+		return "", err
+	}
+	// Seconds precision like "iat" of JWT, creation time is compared with sessions_revoked_at of the user
+	now := time.Now().Truncate(time.Second)
+	userAgent = utilities.TruncateString(userAgent, 500)
+	err = service.sessionStore.Create(ctx, &session.Session{
+		Id: session.SessionId(token),
+		UserId: userId,
+		IpAddress: ipAddress,
+		UserAgent: userAgent,
+		CreatedAt: now,
+		LastSeenAt: now,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := service.sessionStore.DeleteExpired(ctx, now); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// SessionsEnabled tells whether sessions are kept in the store so they can be listed and revoked
+func (service *AuthService) SessionsEnabled() bool {
+	return service.sessionStore != nil
+}
+
+// GetSessions returns active sessions of the user, sessions created before the sessions of the user
+// were revoked (e.g. password change) are rejected by the auth middleware so they are not listed
+func (service *AuthService) GetSessions(ctx context.Context, user *models.User) ([]session.Session, error) {
+	if service.sessionStore == nil {
+		return []session.Session{}, nil
+	}
+	sessions, err := service.sessionStore.GetByUserId(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+	if user.SessionsRevokedAt.Valid {
+		revokedAt := user.SessionsRevokedAt.Time.Truncate(time.Second)
+		sessions = slices.DeleteFunc(sessions, func(s session.Session) bool {
+			return s.CreatedAt.Before(revokedAt)
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends a session of the user, e.g. a device the user no longer uses
+func (service *AuthService) RevokeSession(ctx context.Context, userId int, sessionId string) error {
+	if service.sessionStore == nil {
+		return &exceptions.AppError{Code: http.StatusNotFound, Message: "Session is not found"}
+	}
+	sessions, err := service.sessionStore.GetByUserId(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(sessions, func(s session.Session) bool { return s.Id == sessionId }) {
+		return &exceptions.AppError{Code: http.StatusNotFound, Message: "Session is not found or already ended"}
+	}
+	return service.sessionStore.Delete(ctx, sessionId)
+}
+
+// RevokeAuthToken ends the session of the token, JWT is rejected by its jti until it expires
+func (service *AuthService) RevokeAuthToken(ctx context.Context, authToken string) error {
+	if service.sessionStore != nil {
+		return service.sessionStore.Delete(ctx, session.SessionId(authToken))
+	}
+	claims, err := service.parseAuthToken(authToken)
+	if err != nil {
+		// Invalid or expired token cannot be used anyway
+		return nil
+	}
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil
+	}
+	subject, _ := claims.GetSubject()
+	userId, _ := strconv.Atoi(subject)
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return errors.New("invalid token expiration")
+	}
+
+	if err := service.revokedTokenRepository.Create(ctx, jti, userId, exp.Time); err != nil {
+		return err
+	}
+	return service.revokedTokenRepository.DeleteExpired(ctx, time.Now())
+}
+
+// RevokeAllSessions logs the user out everywhere by rejecting tokens issued before now
+func (service *AuthService) RevokeAllSessions(ctx context.Context, userId int) error {
+	if err := service.userRepository.RevokeSessions(ctx, userId, time.Now()); err != nil {
+		return err
+	}
+	if service.sessionStore != nil {
+		return service.sessionStore.DeleteByUserId(ctx, userId)
+	}
+	return nil
+}
+
+// ReissueAuthToken revokes the token and issues a new one with the same expiration,
+// used to keep the current session after the other sessions are revoked
+func (service *AuthService) ReissueAuthToken(ctx context.Context, authToken string) (string, time.Time, error) {
+	if service.sessionStore != nil {
+		current, err := service.sessionStore.Get(ctx, session.SessionId(authToken))
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if err := service.sessionStore.Delete(ctx, current.Id); err != nil {
+			return "", time.Time{}, err
+		}
+		newToken, err := service.StartSession(ctx, current.UserId, current.ExpiresAt, current.IpAddress, current.UserAgent)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		return newToken, current.ExpiresAt, nil
+	}
+
+	claims, err := service.parseAuthToken(authToken)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	subject, err := claims.GetSubject()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	userId, err := strconv.Atoi(subject)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return "", time.Time{}, errors.New("invalid token expiration")
+	}
+
+	if err := service.RevokeAuthToken(ctx, authToken); err != nil {
+		return "", time.Time{}, err
+	}
+	newToken, err := service.GenerateAuthToken(userId, exp.Unix())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return newToken, exp.Time, nil
+}
+
+// SendPasswordResetLink mails single use reset link, unknown email is ignored so registered emails are not disclosed
+func (service *AuthService) SendPasswordResetLink(ctx context.Context, email string) error {
+	user, err := service.userRepository.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := utilities.RandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	// Previous links are no longer valid once a new one is requested
+	if err := service.passwordResetRepository.DeleteByUserId(ctx, user.Id); err != nil {
+		return err
+	}
+	expired := time.Duration(configs.Get().Auth.ResetExpired) * time.Second
+	err = service.passwordResetRepository.Create(ctx, &models.PasswordReset{
+		UserId: user.Id,
+		Token: utilities.HashToken(token),
+		ExpiredAt: time.Now().Add(expired),
+	})
+	if err != nil {
+		return err
+	}
+
+	body, err := utilities.RenderMail("mails/reset_password.html", map[string]any{
+		"app": configs.Get().App,
+		"user": user,
+		"url": fmt.Sprintf("%s/reset-password/%s", configs.Get().App.Url, token),
+		"expiredMinutes": int(expired.Minutes()),
+	})
+	if err != nil {
+		return err
+	}
+
+	return service.mailer.Send(ctx, mail.Message{
+		To: user.Email,
+		Subject: "Reset Password Notification",
+		Body: body,
+	})
+}
+
+func (service *AuthService) ValidateResetToken(ctx context.Context, token string) error {
+	_, err := service.passwordResetRepository.GetValidByToken(ctx, utilities.HashToken(token), time.Now())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return exceptions.ErrInvalidResetToken
+		}
+		return err
+	}
+	return nil
+}
+
+// ResetPassword consumes the reset token, updates the password and logs out all sessions of the user
+func (service *AuthService) ResetPassword(ctx context.Context, data *dto.ResetPasswordRequest) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	tx, err := service.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	passwordResetRepository := service.passwordResetRepository.WithTx(tx)
+	passwordReset, err := passwordResetRepository.GetValidByToken(ctx, utilities.HashToken(data.Token), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return exceptions.ErrInvalidResetToken
+		}
+		return err
+	}
+	if err := passwordResetRepository.MarkUsed(ctx, passwordReset.Id, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return exceptions.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	userRepository := service.userRepository.WithTx(tx)
+	if err := userRepository.UpdatePassword(ctx, passwordReset.UserId, string(hashedPassword)); err != nil {
+		return err
+	}
+	// Like changing the password from the account page, sessions and access tokens of the old password end
+	if err := userRepository.RevokeSessions(ctx, passwordReset.UserId, now); err != nil {
+		return err
+	}
+	if err := service.personalAccessTokenRepository.WithTx(tx).DeleteByUserId(ctx, passwordReset.UserId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
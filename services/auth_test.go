@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+)
+
+func TestRevokeSession(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := session.NewMemoryStore()
+	for _, s := range []session.Session{
+		{Id: "own", UserId: 1, CreatedAt: now, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)},
+		{Id: "other", UserId: 2, CreatedAt: now, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)},
+	} {
+		if err := store.Create(ctx, &s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	service := NewAuthService(nil, nil, nil, nil, nil, store, nil)
+
+	err := service.RevokeSession(ctx, 1, "other")
+	var appErr *exceptions.AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusNotFound {
+		t.Fatalf("revoking session of another user: got %v, want not found", err)
+	}
+	if _, err := store.Get(ctx, "other"); err != nil {
+		t.Fatalf("session of another user is deleted: %v", err)
+	}
+
+	if err := service.RevokeSession(ctx, 1, "own"); err != nil {
+		t.Fatalf("revoking own session: %v", err)
+	}
+	if _, err := store.Get(ctx, "own"); !errors.Is(err, session.ErrSessionNotFound) {
+		t.Fatalf("own session is not deleted: %v", err)
+	}
+}
+
+func TestStartSessionUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      string
+	}{
+		{name: "invalid utf-8", userAgent: "Mozilla\xff/5.0", want: "Mozilla/5.0"},
+		{name: "long", userAgent: strings.Repeat("é", 300), want: strings.Repeat("é", 250)},
+		{name: "long with odd byte", userAgent: "x" + strings.Repeat("é", 300), want: "x" + strings.Repeat("é", 249)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := session.NewMemoryStore()
+			service := NewAuthService(nil, nil, nil, nil, nil, store, nil)
+
+			token, err := service.StartSession(ctx, 1, time.Now().Add(time.Hour), "10.0.0.1", test.userAgent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			userSession, err := store.Get(ctx, session.SessionId(token))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !utf8.ValidString(userSession.UserAgent) || userSession.UserAgent != test.want {
+				t.Errorf("user agent = %q, want %q", userSession.UserAgent, test.want)
+			}
+		})
+	}
+}
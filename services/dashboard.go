@@ -2,10 +2,24 @@ package services
 
 import (
 	"context"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/anggadarkprince/crud-employee-go/models"
 	"github.com/anggadarkprince/crud-employee-go/repositories"
 )
 
+// DashboardRangeMonths lists selectable ranges of the dashboard trends, in months
+var DashboardRangeMonths = []int{3, 6, 12, 24}
+
+const (
+	defaultDashboardRangeMonths = 12
+	// anniversaryWindowDays is how far ahead upcoming work anniversaries are listed
+	anniversaryWindowDays = 30
+	maxAnniversaries = 10
+)
+
 type DashboardService struct {
 	dashboardRepository *repositories.DashboardRepository
 }
@@ -14,6 +28,115 @@ func NewDashboardService(dashboardRepository *repositories.DashboardRepository)
 	return &DashboardService{dashboardRepository: dashboardRepository}
 }
 
-func (service *DashboardService) GetStatistics(ctx context.Context) (*repositories.DashboardStatistics, error) {
+func (service *DashboardService) GetStatistics(ctx context.Context) (*models.DashboardStatistics, error) {
 	return service.dashboardRepository.GetStatistics(ctx)
 }
+
+// GetAnalytics collects trends of the last months (including the current month) and breakdowns
+// of the current workforce, unsupported range falls back to 12 months
+func (service *DashboardService) GetAnalytics(ctx context.Context, months int) (*models.DashboardAnalytics, error) {
+	if !slices.Contains(DashboardRangeMonths, months) {
+		months = defaultDashboardRangeMonths
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	from := to.AddDate(0, -months, 0)
+
+	hires, err := service.dashboardRepository.GetMonthlyHires(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	exits, err := service.dashboardRepository.GetMonthlyExits(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	headcount, err := service.dashboardRepository.GetHeadcountAt(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	trends := []models.DashboardMonthlyTrend{}
+	for month := from; month.Before(to); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		headcount += hires[key] - exits[key]
+		trends = append(trends, models.DashboardMonthlyTrend{
+			Month: month,
+			Hires: hires[key],
+			Exits: exits[key],
+			Headcount: headcount,
+		})
+	}
+
+	genders, err := service.dashboardRepository.GetGenderDistribution(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowanceTypes, err := service.dashboardRepository.GetAllowanceTypeDistribution(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenureDays, err := service.dashboardRepository.GetAverageTenureDays(ctx, today)
+	if err != nil {
+		return nil, err
+	}
+	anniversaries, err := service.getUpcomingAnniversaries(ctx, today)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DashboardAnalytics{
+		Months: months,
+		From: from,
+		To: to.AddDate(0, 0, -1),
+		Trends: trends,
+		Genders: genders,
+		AllowanceTypes: allowanceTypes,
+		AverageTenureYears: tenureDays / 365.25,
+		Anniversaries: anniversaries,
+	}, nil
+}
+
+// getUpcomingAnniversaries lists employees completing another year of service within the window,
+// employee hired on 29 February celebrates on 1 March of non leap years
+func (service *DashboardService) getUpcomingAnniversaries(ctx context.Context, today time.Time) ([]models.DashboardAnniversary, error) {
+	employees, err := service.dashboardRepository.GetHiredEmployees(ctx, today)
+	if err != nil {
+		return nil, err
+	}
+
+	anniversaries := []models.DashboardAnniversary{}
+	for _, employee := range employees {
+		hiredDate := employee.HiredDate.Time
+		years := today.Year() - hiredDate.Year()
+		date := time.Date(today.Year(), hiredDate.Month(), hiredDate.Day(), 0, 0, 0, 0, time.UTC)
+		if date.Before(today) {
+			years++
+			date = time.Date(today.Year() + 1, hiredDate.Month(), hiredDate.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		daysLeft := int(date.Sub(today).Hours() / 24)
+		if years < 1 || daysLeft > anniversaryWindowDays {
+			continue
+		}
+		anniversaries = append(anniversaries, models.DashboardAnniversary{
+			EmployeeId: employee.Id,
+			Name: employee.Name,
+			HiredDate: hiredDate,
+			Date: date,
+			Years: years,
+			DaysLeft: daysLeft,
+		})
+	}
+
+	slices.SortFunc(anniversaries, func(a, b models.DashboardAnniversary) int {
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Compare(b.Date)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	if len(anniversaries) > maxAnniversaries {
+		anniversaries = anniversaries[:maxAnniversaries]
+	}
+
+	return anniversaries, nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"gitlab.com/tozd/go/errors"
+)
+
+type DepartmentService struct {
+	departmentRepository *repositories.DepartmentRepository
+	db *sql.DB
+}
+
+func NewDepartmentService(departmentRepository *repositories.DepartmentRepository, db *sql.DB) *DepartmentService {
+	return &DepartmentService{departmentRepository: departmentRepository, db: db}
+}
+
+// GetTree returns departments ordered as a tree, children follow their parent with Depth set
+func (service *DepartmentService) GetTree(ctx context.Context) (*[]models.Department, error) {
+	departments, err := service.departmentRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := map[int64][]models.Department{}
+	for _, department := range *departments {
+		children[department.ParentId.Int64] = append(children[department.ParentId.Int64], department)
+	}
+	tree := []models.Department{}
+	var walk func(parentId int64, depth int)
+	walk = func(parentId int64, depth int) {
+		for _, department := range children[parentId] {
+			department.Depth = depth
+			tree = append(tree, department)
+			walk(int64(department.Id), depth+1)
+		}
+	}
+	walk(0, 0)
+	return &tree, nil
+}
+
+func (service *DepartmentService) GetById(ctx context.Context, id int) (*models.Department, error) {
+	return service.departmentRepository.GetById(ctx, id)
+}
+
+func (service *DepartmentService) Store(ctx context.Context, data *dto.CreateDepartmentRequest) (*models.Department, error) {
+	if err := service.ensureUniqueName(ctx, data.Name, 0); err != nil {
+		return nil, err
+	}
+	parentId, err := resolveParent(ctx, service.departmentRepository, 0, data.ParentId)
+	if err != nil {
+		return nil, err
+	}
+	return service.departmentRepository.Store(ctx, &models.Department{
+		ParentId: parentId,
+		Name: data.Name,
+		Description: sql.NullString{String: data.Description, Valid: data.Description != ""},
+	})
+}
+
+// Update checks the new parent and moves the department in one transaction, so two departments
+// moved under each other at the same time can't make a cycle
+func (service *DepartmentService) Update(ctx context.Context, data *dto.UpdateDepartmentRequest) (*models.Department, error) {
+	if err := service.ensureUniqueName(ctx, data.Name, data.Id); err != nil {
+		return nil, err
+	}
+
+	tx, err := service.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	departmentRepository := service.departmentRepository.WithTx(tx)
+	if _, err := departmentRepository.GetParentIdForUpdate(ctx, data.Id); err != nil {
+		return nil, err
+	}
+	parentId, err := resolveParent(ctx, departmentRepository, data.Id, data.ParentId)
+	if err != nil {
+		return nil, err
+	}
+	department, err := departmentRepository.Update(ctx, &models.Department{
+		Id: data.Id,
+		ParentId: parentId,
+		Name: data.Name,
+		Description: sql.NullString{String: data.Description, Valid: data.Description != ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// Destroy deletes department without sub departments and employees (including the ones in trash)
+func (service *DepartmentService) Destroy(ctx context.Context, id int) (*models.Department, error) {
+	department, err := service.departmentRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	totalChildren, err := service.departmentRepository.CountChildren(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if totalChildren > 0 {
+		return nil, &exceptions.AppError{
+			Code: http.StatusConflict,
+			Message: fmt.Sprintf("Department %s has %d sub departments, move or delete them first", department.Name, totalChildren),
+		}
+	}
+	totalEmployee, err := service.departmentRepository.CountEmployees(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if totalEmployee > 0 {
+		return nil, &exceptions.AppError{
+			Code: http.StatusConflict,
+			Message: fmt.Sprintf("Department %s still has %d employees, move them first", department.Name, totalEmployee),
+		}
+	}
+	if _, err := service.departmentRepository.Destroy(ctx, id); err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+func (service *DepartmentService) ensureUniqueName(ctx context.Context, name string, exceptId int) error {
+	existing, err := service.departmentRepository.GetByName(ctx, name)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if existing != nil && existing.Id != exceptId {
+		return &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{"name": fmt.Sprintf("Department %s already exists", name)},
+		}
+	}
+	return nil
+}
+
+// resolveParent checks the parent exists and is not the department itself or one of its sub departments,
+// for an existing department the repository must be in a transaction because the ancestors are locked
+func resolveParent(ctx context.Context, departmentRepository *repositories.DepartmentRepository, id int, parentId int) (sql.NullInt64, error) {
+	if parentId == 0 {
+		return sql.NullInt64{}, nil
+	}
+	invalid := func(message string) error {
+		return &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{"parent_id": message},
+		}
+	}
+
+	parent, err := departmentRepository.GetById(ctx, parentId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sql.NullInt64{}, invalid("Parent department is not found")
+		}
+		return sql.NullInt64{}, err
+	}
+	if id > 0 {
+		// Each ancestor stays locked until the move is committed, a concurrent move
+		// of one of them waits and then sees the new parent
+		visited := map[int]bool{}
+		current := sql.NullInt64{Int64: int64(parent.Id), Valid: true}
+		for current.Valid && !visited[int(current.Int64)] {
+			if int(current.Int64) == id {
+				return sql.NullInt64{}, invalid(fmt.Sprintf("%s is under the department and can't be its parent", parent.Name))
+			}
+			visited[int(current.Int64)] = true
+			if current, err = departmentRepository.GetParentIdForUpdate(ctx, int(current.Int64)); err != nil {
+				return sql.NullInt64{}, err
+			}
+		}
+	}
+	return sql.NullInt64{Int64: int64(parent.Id), Valid: true}, nil
+}
@@ -3,52 +3,157 @@ package services
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
 	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/audit"
+	"github.com/anggadarkprince/crud-employee-go/pkg/storage"
 	"github.com/anggadarkprince/crud-employee-go/repositories"
 	"github.com/anggadarkprince/crud-employee-go/utilities"
+	"gitlab.com/tozd/go/errors"
 )
 
 type EmployeeService struct {
 	employeeRepository *repositories.EmployeeRepository
 	employeeAllowanceRepository *repositories.EmployeeAllowanceRepository
+	allowanceTypeRepository *repositories.AllowanceTypeRepository
+	departmentRepository *repositories.DepartmentRepository
+	positionRepository *repositories.PositionRepository
+	employeeStatusHistoryRepository *repositories.EmployeeStatusHistoryRepository
+	employeeDocumentRepository *repositories.EmployeeDocumentRepository
+	auditLogRepository *repositories.AuditLogRepository
+	storage storage.Storage
 	db *sql.DB
 }
 
 func NewEmployeeService(
 	employeeRepository *repositories.EmployeeRepository,
 	employeeAllowanceRepository *repositories.EmployeeAllowanceRepository,
+	allowanceTypeRepository *repositories.AllowanceTypeRepository,
+	departmentRepository *repositories.DepartmentRepository,
+	positionRepository *repositories.PositionRepository,
+	employeeStatusHistoryRepository *repositories.EmployeeStatusHistoryRepository,
+	employeeDocumentRepository *repositories.EmployeeDocumentRepository,
+	auditLogRepository *repositories.AuditLogRepository,
+	storage storage.Storage,
 	db *sql.DB,
 ) *EmployeeService {
 	return &EmployeeService{
 		employeeRepository: employeeRepository,
 		employeeAllowanceRepository: employeeAllowanceRepository,
+		allowanceTypeRepository: allowanceTypeRepository,
+		departmentRepository: departmentRepository,
+		positionRepository: positionRepository,
+		employeeStatusHistoryRepository: employeeStatusHistoryRepository,
+		employeeDocumentRepository: employeeDocumentRepository,
+		auditLogRepository: auditLogRepository,
+		storage: storage,
 		db: db,
 	}
 }
 
-func (service *EmployeeService) GetAll(ctx context.Context) (*[]models.Employee, error) {
-	return service.employeeRepository.GetAll(ctx)
+func (service *EmployeeService) Paginate(ctx context.Context, filter *dto.EmployeeFilter) (*[]models.Employee, int, error) {
+	return service.employeeRepository.Paginate(ctx, filter)
 }
 
 func (service *EmployeeService) GetById(ctx context.Context, id int) (*models.Employee, error) {
 	return service.employeeRepository.GetById(ctx, id)
 }
 
-func (service *EmployeeService) Store(ctx context.Context, data *dto.CreateEmployeeRequest) (*models.Employee, error) {
-	hiredDate, err := utilities.StringToDate(data.HiredDate)
-	
+// GetOrgChart builds reporting tree of the employees matching the filter, employee without manager
+// or whose manager is outside the filter is a root of the chart
+func (service *EmployeeService) GetOrgChart(ctx context.Context, filter *dto.EmployeeFilter) ([]*models.OrgChartNode, error) {
+	employees, err := service.employeeRepository.GetReportingLines(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
+	nodes := make(map[int64]*models.OrgChartNode, len(*employees))
+	for _, employee := range *employees {
+		nodes[int64(employee.Id)] = &models.OrgChartNode{Employee: employee}
+	}
+	roots := []*models.OrgChartNode{}
+	for _, employee := range *employees {
+		node := nodes[int64(employee.Id)]
+		manager, ok := nodes[employee.ManagerId.Int64]
+		if !employee.ManagerId.Valid || !ok || manager == node {
+			roots = append(roots, node)
+			continue
+		}
+		manager.Reports = append(manager.Reports, node)
+	}
+	return roots, nil
+}
+
+// GetOptions returns employees for manager select inputs and import lookup, only id, name and email are filled
+func (service *EmployeeService) GetOptions(ctx context.Context) (*[]models.Employee, error) {
+	return service.employeeRepository.GetOptions(ctx)
+}
+
+func (service *EmployeeService) Store(ctx context.Context, data *dto.CreateEmployeeRequest) (*models.Employee, error) {
 	tx, err := service.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
+	employee, err := service.store(ctx, tx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return employee, nil
+}
+
+// Import stores the rows in one transaction, error of a row tells its line so the file can be fixed
+func (service *EmployeeService) Import(ctx context.Context, rows []dto.EmployeeImportRow) (int, error) {
+	tx, err := service.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for i := range rows {
+		if _, err := service.store(ctx, tx, &rows[i].Data); err != nil {
+			if validationErr, ok := err.(*exceptions.ValidationError); ok {
+				messages := []string{}
+				for _, message := range validationErr.Errors {
+					messages = append(messages, message)
+				}
+				slices.Sort(messages)
+				return 0, &exceptions.ValidationError{
+					Message: fmt.Sprintf("Row at line %d is invalid: %s", rows[i].Line, strings.Join(messages, ", ")),
+					Errors: validationErr.Errors,
+				}
+			}
+			return 0, errors.Errorf("failed to import row at line %d: %w", rows[i].Line, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(rows), nil
+}
+
+func (service *EmployeeService) store(ctx context.Context, tx *sql.Tx, data *dto.CreateEmployeeRequest) (*models.Employee, error) {
+	hiredDate, err := utilities.StringToDate(data.HiredDate)
+	
+	if err != nil {
+		return nil, err
+	}
+
 	employeeModel := &models.Employee{
         Name: data.Name,
         Email: sql.NullString{String: data.Email, Valid: data.Email != ""},
@@ -57,8 +162,24 @@ func (service *EmployeeService) Store(ctx context.Context, data *dto.CreateEmplo
         Address: sql.NullString{String: data.Address, Valid: data.Address != ""},
         Status: sql.NullString{String: data.Status, Valid: data.Status != ""},
 		HiredDate: hiredDate,
+		BaseSalary: data.BaseSalary,
     }
 
+	if message := initialStatusError(data.Status); message != "" {
+		return nil, &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{"status": message},
+		}
+	}
+	if err := service.resolveOrganization(ctx, tx, employeeModel, data.DepartmentId, data.PositionId, data.ManagerId); err != nil {
+		return nil, err
+	}
+
+	allowances, err := service.resolveAllowances(ctx, tx, data.Allowances, data.AllowanceAmounts, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	employeeRepository := service.employeeRepository.WithTx(tx)
 	employee, err := employeeRepository.Store(ctx, employeeModel)
 	if err != nil {
@@ -66,12 +187,37 @@ func (service *EmployeeService) Store(ctx context.Context, data *dto.CreateEmplo
 	}
 	
 	employeeAllowanceRepository := service.employeeAllowanceRepository.WithTx(tx)
-	_, err = employeeAllowanceRepository.StoreMany(ctx, employee.Id, data.Allowances)
+	_, err = employeeAllowanceRepository.StoreMany(ctx, employee.Id, allowances)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := tx.Commit(); err != nil {
+	// Initial status is the first entry of the history, it's effective since the employee is hired
+	effectiveDate := time.Now()
+	if employee.HiredDate.Valid {
+		effectiveDate = employee.HiredDate.Time
+	}
+	actor := audit.ActorFromContext(ctx)
+	err = service.employeeStatusHistoryRepository.WithTx(tx).Store(ctx, &models.EmployeeStatusHistory{
+		EmployeeId: employee.Id,
+		ToStatus: employee.Status.String,
+		EffectiveDate: effectiveDate,
+		CreatedBy: sql.NullInt64{Int64: int64(actor.UserId), Valid: actor.UserId > 0},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionCreated,
+		models.AuditEntityEmployee,
+		employee.Id,
+		nil,
+		employeeAuditValues(employee, allowances),
+	)
+	if err != nil {
 		return nil, err
 	}
 
@@ -98,22 +244,163 @@ func (service *EmployeeService) Update(ctx context.Context, data *dto.UpdateEmpl
         TaxNumber: sql.NullString{String: data.TaxNumber, Valid: data.TaxNumber != ""},
         Gender: sql.NullString{String: data.Gender, Valid: data.Gender != ""},
         Address: sql.NullString{String: data.Address, Valid: data.Address != ""},
-        Status: sql.NullString{String: data.Status, Valid: data.Status != ""},
 		HiredDate: hiredDate,
     }
 
 	employeeRepository := service.employeeRepository.WithTx(tx)
+	employeeAllowanceRepository := service.employeeAllowanceRepository.WithTx(tx)
+	current, err := employeeRepository.GetById(ctx, data.Id)
+	if err != nil {
+		return nil, err
+	}
+	currentAllowances, err := employeeAllowanceRepository.GetByEmployeeId(ctx, current.Id)
+	if err != nil {
+		return nil, err
+	}
+	before := employeeAuditValues(current, *currentAllowances)
+
+	if data.Status != "" && data.Status != current.Status.String {
+		return nil, &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{"status": "Status can only be changed through status transition"},
+		}
+	}
+	employeeModel.Status = current.Status
+	employeeModel.BaseSalary = current.BaseSalary
+	if data.BaseSalary != nil {
+		employeeModel.BaseSalary = *data.BaseSalary
+	}
+
+	if err := service.resolveOrganization(ctx, tx, employeeModel, data.DepartmentId, data.PositionId, data.ManagerId); err != nil {
+		return nil, err
+	}
+
+	allowances, err := service.resolveAllowances(ctx, tx, data.Allowances, data.AllowanceAmounts, *currentAllowances)
+	if err != nil {
+		return nil, err
+	}
+
 	employee, err := employeeRepository.Update(ctx, employeeModel)
 	if err != nil {
 		return nil, err
 	}
 	
-	employeeAllowanceRepository := service.employeeAllowanceRepository.WithTx(tx)
 	_, err = employeeAllowanceRepository.DestroyByEmployeeId(ctx, employee.Id)
 	if err != nil {
 		return nil, err
 	}
-	_, err = employeeAllowanceRepository.StoreMany(ctx, employee.Id, data.Allowances)
+	_, err = employeeAllowanceRepository.StoreMany(ctx, employee.Id, allowances)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionUpdated,
+		models.AuditEntityEmployee,
+		employee.Id,
+		before,
+		employeeAuditValues(employee, allowances),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return employee, nil
+}
+
+// GetStatusHistories returns status changes of the employee, latest first
+func (service *EmployeeService) GetStatusHistories(ctx context.Context, id int) (*[]models.EmployeeStatusHistory, error) {
+	return service.employeeStatusHistoryRepository.GetByEmployeeId(ctx, id)
+}
+
+// ChangeStatus moves the employee to the next status of the lifecycle, the reason and
+// effective date are kept in status history
+func (service *EmployeeService) ChangeStatus(ctx context.Context, data *dto.ChangeEmployeeStatusRequest) (*models.Employee, error) {
+	effectiveDate, err := utilities.StringToDate(data.EffectiveDate)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := service.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	employeeRepository := service.employeeRepository.WithTx(tx)
+	employee, err := employeeRepository.GetById(ctx, data.Id)
+	if err != nil {
+		return nil, err
+	}
+	before, err := service.auditValues(ctx, service.employeeAllowanceRepository.WithTx(tx), employee)
+	if err != nil {
+		return nil, err
+	}
+
+	// Status that is not in the lifecycle (legacy data) can be moved to any status
+	current := employee.StatusConfig()
+	_, known := models.GetEmployeeStatus(current.Code)
+	next, _ := models.GetEmployeeStatus(data.Status)
+	if (known && !current.CanTransitionTo(next.Code)) || current.Code == next.Code {
+		return nil, &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{"status": fmt.Sprintf("%s employee can't be changed to %s", current.Label, next.Label)},
+		}
+	}
+	if employee.HiredDate.Valid && effectiveDate.Time.Before(employee.HiredDate.Time) {
+		return nil, &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{"effective_date": "Effective date can't be before the hired date"},
+		}
+	}
+
+	updated, err := employeeRepository.UpdateStatus(ctx, employee.Id, current.Code, next.Code)
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		return nil, &exceptions.AppError{
+			Code: http.StatusConflict,
+			Message: fmt.Sprintf("Status of %s is changed by someone else, please reload the page", employee.Name),
+		}
+	}
+
+	actor := audit.ActorFromContext(ctx)
+	err = service.employeeStatusHistoryRepository.WithTx(tx).Store(ctx, &models.EmployeeStatusHistory{
+		EmployeeId: employee.Id,
+		FromStatus: employee.Status,
+		ToStatus: next.Code,
+		Reason: sql.NullString{String: data.Reason, Valid: true},
+		EffectiveDate: effectiveDate.Time,
+		CreatedBy: sql.NullInt64{Int64: int64(actor.UserId), Valid: actor.UserId > 0},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err = employeeRepository.GetById(ctx, employee.Id)
+	if err != nil {
+		return nil, err
+	}
+	after, err := service.auditValues(ctx, service.employeeAllowanceRepository.WithTx(tx), employee)
+	if err != nil {
+		return nil, err
+	}
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionUpdated,
+		models.AuditEntityEmployee,
+		employee.Id,
+		before,
+		after,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -125,6 +412,7 @@ func (service *EmployeeService) Update(ctx context.Context, data *dto.UpdateEmpl
 	return employee, nil
 }
 
+// Destroy moves the employee to trash, allowances are kept so it can be restored
 func (service *EmployeeService) Destroy(ctx context.Context, id int) error {
 	tx, err := service.db.Begin()
 	if err != nil {
@@ -133,19 +421,299 @@ func (service *EmployeeService) Destroy(ctx context.Context, id int) error {
 	defer tx.Rollback()
 
 	employeeRepository := service.employeeRepository.WithTx(tx)
-	_, err = employeeRepository.Destroy(ctx, id)
+	employee, err := employeeRepository.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	before, err := service.auditValues(ctx, service.employeeAllowanceRepository.WithTx(tx), employee)
+	if err != nil {
+		return err
+	}
+
+	_, err = employeeRepository.SoftDelete(ctx, id, time.Now())
+	if err != nil {
+		return err
+	}
+
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionDeleted,
+		models.AuditEntityEmployee,
+		id,
+		before,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (service *EmployeeService) GetTrashedById(ctx context.Context, id int) (*models.Employee, error) {
+	return service.employeeRepository.GetTrashedById(ctx, id)
+}
+
+// Restore brings the employee back from trash
+func (service *EmployeeService) Restore(ctx context.Context, id int) (*models.Employee, error) {
+	tx, err := service.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	employeeRepository := service.employeeRepository.WithTx(tx)
+	_, err = employeeRepository.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	employee, err := employeeRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	after, err := service.auditValues(ctx, service.employeeAllowanceRepository.WithTx(tx), employee)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionRestored,
+		models.AuditEntityEmployee,
+		id,
+		nil,
+		after,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return employee, nil
+}
+
+// Purge permanently deletes the employee in trash and its allowances, files of the documents are deleted after commit
+func (service *EmployeeService) Purge(ctx context.Context, id int) error {
+	tx, err := service.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	employeeRepository := service.employeeRepository.WithTx(tx)
 	employeeAllowanceRepository := service.employeeAllowanceRepository.WithTx(tx)
+	employee, err := employeeRepository.GetTrashedById(ctx, id)
+	if err != nil {
+		return err
+	}
+	before, err := service.auditValues(ctx, employeeAllowanceRepository, employee)
+	if err != nil {
+		return err
+	}
+	// Document records are deleted by the foreign key cascade
+	documents, err := service.employeeDocumentRepository.WithTx(tx).GetByEmployeeId(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	_, err = employeeAllowanceRepository.DestroyByEmployeeId(ctx, id)
 	if err != nil {
 		return err
 	}
+	_, err = employeeRepository.Destroy(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionPurged,
+		models.AuditEntityEmployee,
+		id,
+		before,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
 
 	if err := tx.Commit(); err != nil {
 		return err
 	}
+	for _, document := range *documents {
+		deleteFile(ctx, service.storage, document.FilePath)
+	}
+	return nil
+}
+
+// PurgeTrashed permanently deletes employees that are in trash since before the time, returns number of purged employees
+func (service *EmployeeService) PurgeTrashed(ctx context.Context, before time.Time) (int, error) {
+	ids, err := service.employeeRepository.GetTrashedIdsBefore(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	for i, id := range ids {
+		if err := service.Purge(ctx, id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// auditValues loads allowances of the employee and returns them as audit snapshot
+func (service *EmployeeService) auditValues(
+	ctx context.Context,
+	employeeAllowanceRepository *repositories.EmployeeAllowanceRepository,
+	employee *models.Employee,
+) (audit.Values, error) {
+	employeeAllowances, err := employeeAllowanceRepository.GetByEmployeeId(ctx, employee.Id)
+	if err != nil {
+		return nil, err
+	}
+	return employeeAuditValues(employee, *employeeAllowances), nil
+}
+
+// resolveOrganization checks the submitted department, position and manager exist and sets them to the employee,
+// the manager can't be the employee itself nor someone reporting (directly or not) to the employee
+func (service *EmployeeService) resolveOrganization(
+	ctx context.Context,
+	tx *sql.Tx,
+	employee *models.Employee,
+	departmentId int,
+	positionId int,
+	managerId int,
+) error {
+	invalid := func(field string, message string) error {
+		return &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{field: message},
+		}
+	}
 
+	if departmentId > 0 {
+		if _, err := service.departmentRepository.WithTx(tx).GetById(ctx, departmentId); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return invalid("department_id", "Department is not found")
+			}
+			return err
+		}
+		employee.DepartmentId = sql.NullInt64{Int64: int64(departmentId), Valid: true}
+	}
+	if positionId > 0 {
+		if _, err := service.positionRepository.WithTx(tx).GetById(ctx, positionId); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return invalid("position_id", "Position is not found")
+			}
+			return err
+		}
+		employee.PositionId = sql.NullInt64{Int64: int64(positionId), Valid: true}
+	}
+	if managerId == 0 {
+		return nil
+	}
+
+	employeeRepository := service.employeeRepository.WithTx(tx)
+	manager, err := employeeRepository.GetById(ctx, managerId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return invalid("manager_id", "Manager is not found")
+		}
+		return err
+	}
+	if employee.Id > 0 {
+		if manager.Id == employee.Id {
+			return invalid("manager_id", "Employee can't be their own manager")
+		}
+		// Walk up the reporting line of the manager, meeting the employee means it would be a cycle
+		visited := map[int64]bool{}
+		next := manager.ManagerId
+		for next.Valid && !visited[next.Int64] {
+			if next.Int64 == int64(employee.Id) {
+				return invalid("manager_id", fmt.Sprintf("%s reports to %s and can't be their manager", manager.Name, employee.Name))
+			}
+			visited[next.Int64] = true
+			next, err = employeeRepository.GetManagerId(ctx, int(next.Int64))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	employee.ManagerId = sql.NullInt64{Int64: int64(manager.Id), Valid: true}
 	return nil
-}
\ No newline at end of file
+}
+
+// resolveAllowances maps submitted allowance codes (or names) to the catalog, unknown types are rejected
+// and inactive types are only accepted when the employee already has them
+func (service *EmployeeService) resolveAllowances(
+	ctx context.Context,
+	tx *sql.Tx,
+	values []string,
+	amounts map[string]models.Money,
+	current []models.EmployeeAllowance,
+) ([]models.EmployeeAllowance, error) {
+	allowanceTypes, err := service.allowanceTypeRepository.WithTx(tx).GetAll(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	lookup := newAllowanceTypeLookup(*allowanceTypes)
+
+	allowances := []models.EmployeeAllowance{}
+	for _, value := range values {
+		allowanceType, ok := lookup.find(value)
+		if !ok {
+			return nil, &exceptions.ValidationError{
+				Message: "Please check the data you provided.",
+				Errors: map[string]string{"allowances": fmt.Sprintf("Allowance %s is not found", value)},
+			}
+		}
+		if slices.ContainsFunc(allowances, func(allowance models.EmployeeAllowance) bool {
+			return allowance.AllowanceTypeId == allowanceType.Id
+		}) {
+			continue
+		}
+		assigned := slices.ContainsFunc(current, func(allowance models.EmployeeAllowance) bool {
+			return allowance.AllowanceTypeId == allowanceType.Id
+		})
+		if !allowanceType.IsActive && !assigned {
+			return nil, &exceptions.ValidationError{
+				Message: "Please check the data you provided.",
+				Errors: map[string]string{"allowances": fmt.Sprintf("Allowance %s is no longer available", allowanceType.Name)},
+			}
+		}
+
+		allowance := models.EmployeeAllowance{
+			AllowanceTypeId: allowanceType.Id,
+			Code: allowanceType.Code,
+			Allowance: allowanceType.Name,
+			DefaultAmount: allowanceType.DefaultAmount,
+			IsTaxable: allowanceType.IsTaxable,
+		}
+		if amount, ok := amounts[allowanceType.Code]; ok {
+			allowance.Amount = models.NullMoney{Money: amount, Valid: true}
+		}
+		allowances = append(allowances, allowance)
+	}
+	return allowances, nil
+}
+
+// initialStatusError returns the error message when a new employee cannot start with the status, empty otherwise
+func initialStatusError(code string) string {
+	if status, ok := models.GetEmployeeStatus(code); ok && status.Initial {
+		return ""
+	}
+	labels := []string{}
+	for _, initial := range models.InitialEmployeeStatuses() {
+		labels = append(labels, initial.Label)
+	}
+	return fmt.Sprintf("New employee status must be %s", strings.Join(labels, " or "))
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/audit"
+	"github.com/anggadarkprince/crud-employee-go/pkg/storage"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+	"gitlab.com/tozd/go/errors"
+)
+
+type EmployeeDocumentService struct {
+	employeeDocumentRepository *repositories.EmployeeDocumentRepository
+	employeeRepository *repositories.EmployeeRepository
+	auditLogRepository *repositories.AuditLogRepository
+	storage storage.Storage
+	db *sql.DB
+}
+
+func NewEmployeeDocumentService(
+	employeeDocumentRepository *repositories.EmployeeDocumentRepository,
+	employeeRepository *repositories.EmployeeRepository,
+	auditLogRepository *repositories.AuditLogRepository,
+	storage storage.Storage,
+	db *sql.DB,
+) *EmployeeDocumentService {
+	return &EmployeeDocumentService{
+		employeeDocumentRepository: employeeDocumentRepository,
+		employeeRepository: employeeRepository,
+		auditLogRepository: auditLogRepository,
+		storage: storage,
+		db: db,
+	}
+}
+
+func (service *EmployeeDocumentService) GetByEmployeeId(ctx context.Context, employeeId int) (*[]models.EmployeeDocument, error) {
+	return service.employeeDocumentRepository.GetByEmployeeId(ctx, employeeId)
+}
+
+// GetExpiring returns documents that are expired or expire within models.EmployeeDocumentExpiringDays
+func (service *EmployeeDocumentService) GetExpiring(ctx context.Context, limit int) (*[]models.EmployeeDocument, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return service.employeeDocumentRepository.GetExpiringBefore(ctx, today.AddDate(0, 0, models.EmployeeDocumentExpiringDays + 1), limit)
+}
+
+// GetById returns the document of the employee, document of another employee is not found
+func (service *EmployeeDocumentService) GetById(ctx context.Context, employeeId int, id int) (*models.EmployeeDocument, error) {
+	document, err := service.employeeDocumentRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if document.EmployeeId != employeeId {
+		return nil, errors.Errorf("document id=%d of employee id=%d is not found: %w", id, employeeId, sql.ErrNoRows)
+	}
+	return document, nil
+}
+
+// Open returns content of the document, the caller must close it
+func (service *EmployeeDocumentService) Open(ctx context.Context, document *models.EmployeeDocument) (io.ReadCloser, error) {
+	return service.storage.Get(ctx, document.FilePath)
+}
+
+// Store saves the uploaded files as documents of the employee, all files share the type, description and expiry date
+func (service *EmployeeDocumentService) Store(ctx context.Context, data *dto.StoreEmployeeDocumentsRequest) (*models.Employee, []models.EmployeeDocument, error) {
+	expiredAt, err := utilities.StringToDate(data.ExpiredAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	employee, err := service.employeeRepository.GetById(ctx, data.EmployeeId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	actor := audit.ActorFromContext(ctx)
+	documents := make([]models.EmployeeDocument, 0, len(data.Files))
+	committed := false
+	// Stored files are not referenced when the documents fail to save
+	defer func() {
+		if !committed {
+			for _, document := range documents {
+				deleteFile(ctx, service.storage, document.FilePath)
+			}
+		}
+	}()
+	for _, fileHeader := range data.Files {
+		document, err := service.storeFile(ctx, fileHeader)
+		if err != nil {
+			return nil, nil, err
+		}
+		document.EmployeeId = employee.Id
+		document.EmployeeName = employee.Name
+		document.DocumentType = data.DocumentType
+		document.Description = sql.NullString{String: data.Description, Valid: data.Description != ""}
+		document.ExpiredAt = expiredAt
+		document.CreatedBy = sql.NullInt64{Int64: int64(actor.UserId), Valid: actor.UserId > 0}
+		documents = append(documents, *document)
+	}
+
+	tx, err := service.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	employeeDocumentRepository := service.employeeDocumentRepository.WithTx(tx)
+	current, err := employeeDocumentRepository.GetByEmployeeId(ctx, employee.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+	before := documentAuditValues(*current)
+	// Snapshot lists documents latest first like GetByEmployeeId
+	after := *current
+	for i := range documents {
+		documents[i].Id, err = employeeDocumentRepository.Store(ctx, &documents[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		after = append([]models.EmployeeDocument{documents[i]}, after...)
+	}
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionUpdated,
+		models.AuditEntityEmployee,
+		employee.Id,
+		before,
+		documentAuditValues(after),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	committed = true
+	return employee, documents, nil
+}
+
+// storeFile puts the uploaded file into the storage under random name, the client filename is kept for download only
+func (service *EmployeeDocumentService) storeFile(ctx context.Context, fileHeader *multipart.FileHeader) (*models.EmployeeDocument, error) {
+	mimeType, err := validation.DocumentMimeType(fileHeader)
+	if err != nil {
+		return nil, &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{"files": err.Error()},
+		}
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	path, err := storage.RandomName("documents", "document" + validation.DocumentMimeTypes[mimeType])
+	if err != nil {
+		return nil, err
+	}
+	if err := service.storage.Put(ctx, path, file, mimeType); err != nil {
+		return nil, err
+	}
+	return &models.EmployeeDocument{
+		FilePath: path,
+		FileName: documentFileName(fileHeader.Filename, validation.DocumentMimeTypes[mimeType]),
+		MimeType: mimeType,
+		FileSize: fileHeader.Size,
+	}, nil
+}
+
+// Destroy deletes the document, the file is removed after the record is deleted
+func (service *EmployeeDocumentService) Destroy(ctx context.Context, employeeId int, id int) (*models.EmployeeDocument, error) {
+	tx, err := service.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	employeeDocumentRepository := service.employeeDocumentRepository.WithTx(tx)
+	current, err := employeeDocumentRepository.GetByEmployeeId(ctx, employeeId)
+	if err != nil {
+		return nil, err
+	}
+	var document *models.EmployeeDocument
+	remaining := []models.EmployeeDocument{}
+	for i := range *current {
+		if (*current)[i].Id == id {
+			document = &(*current)[i]
+		} else {
+			remaining = append(remaining, (*current)[i])
+		}
+	}
+	if document == nil {
+		return nil, &exceptions.AppError{Code: http.StatusNotFound, Message: "Document is not found"}
+	}
+
+	if _, err := employeeDocumentRepository.Destroy(ctx, document.Id); err != nil {
+		return nil, err
+	}
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionUpdated,
+		models.AuditEntityEmployee,
+		employeeId,
+		documentAuditValues(*current),
+		documentAuditValues(remaining),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	deleteFile(ctx, service.storage, document.FilePath)
+	return document, nil
+}
+
+// documentAuditValues snapshots documents of the employee, only the list of documents is audited
+func documentAuditValues(documents []models.EmployeeDocument) audit.Values {
+	labels := make([]string, 0, len(documents))
+	for _, document := range documents {
+		labels = append(labels, document.AuditLabel())
+	}
+	return audit.Values{"documents": labels}
+}
+
+// documentFileName makes the client filename safe for Content-Disposition, the extension matches the sniffed type
+func documentFileName(filename string, ext string) string {
+	name := strings.TrimSuffix(filepath.Base(strings.ReplaceAll(filename, `\`, "/")), filepath.Ext(filename))
+	name = strings.Map(func(r rune) rune {
+		if r < 32 || r == 127 || strings.ContainsRune(`"/\`, r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." {
+		name = "document"
+	}
+	for utf8.RuneCountInString(name) > 200 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name) - size]
+	}
+	return fmt.Sprintf("%s%s", name, ext)
+}
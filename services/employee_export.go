@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/pdf"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+	"github.com/xuri/excelize/v2"
+	"gitlab.com/tozd/go/errors"
+)
+
+var EmployeeExportFormats = map[string]string{
+	"csv":  "text/csv",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"pdf":  "application/pdf",
+}
+
+var employeeExportHeaders = []string{"ID", "Name", "Email", "Tax Number", "Gender", "Hired Date", "Address", "Status", "Department", "Position", "Manager", "Base Salary", "Allowances"}
+
+type EmployeeExportService struct {
+	employeeRepository *repositories.EmployeeRepository
+}
+
+func NewEmployeeExportService(employeeRepository *repositories.EmployeeRepository) *EmployeeExportService {
+	return &EmployeeExportService{employeeRepository: employeeRepository}
+}
+
+// Filename returns download name of the export, e.g. employees-20240131-150405.xlsx
+func (service *EmployeeExportService) Filename(format string, now time.Time) string {
+	return fmt.Sprintf("employees-%s.%s", now.Format("20060102-150405"), format)
+}
+
+// Export writes employees matching the filter (paging is ignored) to w,
+// rows are written while they are read from database
+func (service *EmployeeExportService) Export(ctx context.Context, format string, filter *dto.EmployeeFilter, w io.Writer) error {
+	switch format {
+	case "csv":
+		return service.exportCsv(ctx, filter, w)
+	case "xlsx":
+		return service.exportXlsx(ctx, filter, w)
+	case "pdf":
+		return service.exportPdf(ctx, filter, w)
+	}
+	return errors.Errorf("unsupported export format %q", format)
+}
+
+func (service *EmployeeExportService) exportCsv(ctx context.Context, filter *dto.EmployeeFilter, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(employeeExportHeaders); err != nil {
+		return err
+	}
+	err := service.employeeRepository.Each(ctx, filter, func(employee *models.Employee, allowances []string) error {
+		hiredDate := ""
+		if employee.HiredDate.Valid {
+			hiredDate = employee.HiredDate.Time.Format("2006-01-02")
+		}
+		return writer.Write(escapeCsvFormulas([]string{
+			strconv.Itoa(employee.Id),
+			employee.Name,
+			employee.Email.String,
+			employee.TaxNumber.String,
+			employee.Gender.String,
+			hiredDate,
+			employee.Address.String,
+			employee.Status.String,
+			employee.DepartmentName.String,
+			employee.PositionName.String,
+			employee.ManagerName.String,
+			employee.BaseSalary.String(),
+			strings.Join(allowances, ", "),
+		}))
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeCsvFormulas prefixes cells that a spreadsheet would evaluate as formula with a quote,
+// so free text like name or address can't run a formula when the export is opened
+func escapeCsvFormulas(record []string) []string {
+	for i, value := range record {
+		if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			record[i] = "'" + value
+		}
+	}
+	return record
+}
+
+// exportXlsx uses stream writer, it keeps rows in a temporary file instead of memory
+func (service *EmployeeExportService) exportXlsx(ctx context.Context, filter *dto.EmployeeFilter, w io.Writer) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := "Employees"
+	if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	headerStyle, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	dateFormat := "yyyy-mm-dd"
+	dateStyle, err := file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		return err
+	}
+
+	moneyFormat := "#,##0.00"
+	moneyStyle, err := file.NewStyle(&excelize.Style{CustomNumFmt: &moneyFormat})
+	if err != nil {
+		return err
+	}
+
+	widths := []float64{8, 25, 30, 16, 10, 12, 35, 12, 20, 20, 25, 16, 35}
+	for i, width := range widths {
+		if err := stream.SetColWidth(i+1, i+1, width); err != nil {
+			return err
+		}
+	}
+
+	header := make([]any, len(employeeExportHeaders))
+	for i, title := range employeeExportHeaders {
+		header[i] = excelize.Cell{StyleID: headerStyle, Value: title}
+	}
+	if err := stream.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	rowNumber := 1
+	err = service.employeeRepository.Each(ctx, filter, func(employee *models.Employee, allowances []string) error {
+		rowNumber++
+		var hiredDate any
+		if employee.HiredDate.Valid {
+			hiredDate = excelize.Cell{StyleID: dateStyle, Value: employee.HiredDate.Time}
+		}
+		cell, err := excelize.CoordinatesToCellName(1, rowNumber)
+		if err != nil {
+			return err
+		}
+		return stream.SetRow(cell, []any{
+			employee.Id,
+			employee.Name,
+			employee.Email.String,
+			employee.TaxNumber.String,
+			employee.Gender.String,
+			hiredDate,
+			employee.Address.String,
+			employee.Status.String,
+			employee.DepartmentName.String,
+			employee.PositionName.String,
+			employee.ManagerName.String,
+			// Spreadsheet numbers are floating point, cents are converted only for the cell
+			excelize.Cell{StyleID: moneyStyle, Value: float64(employee.BaseSalary) / 100},
+			strings.Join(allowances, ", "),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	return file.Write(w)
+}
+
+type pdfColumn struct {
+	title string
+	width float64
+}
+
+var employeePdfColumns = []pdfColumn{
+	{"ID", 25}, {"Name", 85}, {"Email", 100}, {"Tax Number", 55}, {"Gender", 35}, {"Hired Date", 50},
+	{"Address", 70}, {"Status", 45}, {"Department", 60}, {"Position", 60}, {"Manager", 70},
+	{"Base Salary", 55}, {"Allowances", 55},
+}
+
+// exportPdf writes landscape report, each page is flushed to a temporary file once it's full
+// so text that can't be written to PDF fails the export before anything is sent to w
+func (service *EmployeeExportService) exportPdf(ctx context.Context, filter *dto.EmployeeFilter, w io.Writer) error {
+	const margin = 36.0
+	const rowHeight = 16.0
+	const fontSize = 8.0
+
+	file, err := os.CreateTemp("", "employee-export-*.pdf")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	document := pdf.New(file, pdf.A4Landscape)
+	size := document.Size()
+	y := 0.0
+
+	drawHeader := func() {
+		document.AddPage()
+		y = margin
+		if document.PageCount() == 1 {
+			document.Text(margin, y+14, pdf.HelveticaBold, 14, "Employee Report")
+			document.Text(margin, y+30, pdf.Helvetica, 9, describeEmployeeFilter(filter))
+			document.TextRight(size.Width-margin, y+30, pdf.Helvetica, 9, "Generated at "+time.Now().Format("2006-01-02 15:04"))
+			y += 44
+		}
+		document.FillRect(margin, y, size.Width-margin*2, rowHeight, 0.9)
+		x := margin
+		for _, column := range employeePdfColumns {
+			document.Text(x+3, y+11, pdf.HelveticaBold, fontSize, column.title)
+			x += column.width
+		}
+		y += rowHeight
+		document.TextRight(size.Width-margin, size.Height-margin/2, pdf.Helvetica, 8, fmt.Sprintf("Page %d", document.PageCount()))
+	}
+
+	drawHeader()
+	total := 0
+	err = service.employeeRepository.Each(ctx, filter, func(employee *models.Employee, allowances []string) error {
+		if y+rowHeight > size.Height-margin {
+			drawHeader()
+		}
+		total++
+		hiredDate := ""
+		if employee.HiredDate.Valid {
+			hiredDate = employee.HiredDate.Time.Format("2006-01-02")
+		}
+		values := []string{
+			strconv.Itoa(employee.Id),
+			employee.Name,
+			employee.Email.String,
+			employee.TaxNumber.String,
+			employee.Gender.String,
+			hiredDate,
+			employee.Address.String,
+			employee.Status.String,
+			employee.DepartmentName.String,
+			employee.PositionName.String,
+			employee.ManagerName.String,
+			utilities.FormatMoney(employee.BaseSalary),
+			strings.Join(allowances, ", "),
+		}
+		x := margin
+		for i, column := range employeePdfColumns {
+			document.Text(x+3, y+11, pdf.Helvetica, fontSize, pdf.Truncate(pdf.Helvetica, fontSize, values[i], column.width-6))
+			x += column.width
+		}
+		y += rowHeight
+		document.Line(margin, y, size.Width-margin, y, 0.3)
+		return document.Err()
+	})
+	if err != nil {
+		return pdfError(err)
+	}
+
+	if y+rowHeight > size.Height-margin {
+		drawHeader()
+	}
+	document.Text(margin, y+12, pdf.HelveticaBold, fontSize, fmt.Sprintf("Total %d employees", total))
+
+	if err := document.Close(); err != nil {
+		return pdfError(err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// describeEmployeeFilter summarizes active filters for the report header
+func describeEmployeeFilter(filter *dto.EmployeeFilter) string {
+	filters := []string{}
+	if filter.Status != "" {
+		filters = append(filters, "Status: "+filter.Status)
+	}
+	if filter.Gender != "" {
+		filters = append(filters, "Gender: "+filter.Gender)
+	}
+	if filter.HiredFrom != "" || filter.HiredTo != "" {
+		filters = append(filters, fmt.Sprintf("Hired: %s - %s", filter.HiredFrom, filter.HiredTo))
+	}
+	if filter.Allowance != "" {
+		filters = append(filters, "Allowance: "+filter.Allowance)
+	}
+	if len(filters) == 0 {
+		return "All employees"
+	}
+	return strings.Join(filters, ", ")
+}
+
+// pdfError tells the user which text can't be written with the standard PDF fonts
+func pdfError(err error) error {
+	var characterErr *pdf.UnsupportedCharacterError
+	if errors.As(err, &characterErr) {
+		return &exceptions.AppError{
+			Code: http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("%q can't be written to PDF, the character %q is not supported, use CSV or XLSX instead", characterErr.Text, characterErr.Char),
+			Err: err,
+		}
+	}
+	return err
+}
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEscapeCsvFormulas(t *testing.T) {
+	record := []string{"12", "=HYPERLINK(\"http://x\")", "+1", "-2+3", "@SUM(A1)", "\tcmd", "\rcmd", "John = Doe", "", "1500000.00"}
+	want := []string{"12", "'=HYPERLINK(\"http://x\")", "'+1", "'-2+3", "'@SUM(A1)", "'\tcmd", "'\rcmd", "John = Doe", "", "1500000.00"}
+	if got := escapeCsvFormulas(record); !slices.Equal(got, want) {
+		t.Errorf("escapeCsvFormulas = %q, want %q", got, want)
+	}
+}
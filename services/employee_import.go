@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/xuri/excelize/v2"
+)
+
+// EmployeeImportColumns are header of the import file, the order in the file is free
+var EmployeeImportColumns = []string{
+	"name", "email", "tax_number", "gender", "hired_date", "address", "status",
+	"department", "position", "manager", "base_salary", "allowances",
+}
+
+// employeeImportOptionalColumns may be left out, files made before the columns were added are still accepted
+var employeeImportOptionalColumns = []string{"department", "position", "manager", "base_salary"}
+
+const EmployeeImportMaxRows = 1000
+
+// EmployeeImportMaxSize is the maximum size of the import file in bytes
+const EmployeeImportMaxSize = 10 << 20
+
+// XLSX is a zip archive, a small upload may unzip to gigabytes. A sheet of EmployeeImportMaxRows rows
+// with long addresses is a few MB of XML, the limits leave room for styles and shared strings
+const (
+	employeeImportUnzipSizeLimit = 64 << 20
+	employeeImportUnzipXMLSizeLimit = 32 << 20
+)
+
+type EmployeeImportService struct {
+	employeeService *EmployeeService
+	allowanceTypeService *AllowanceTypeService
+	departmentService *DepartmentService
+	positionService *PositionService
+}
+
+func NewEmployeeImportService(
+	employeeService *EmployeeService,
+	allowanceTypeService *AllowanceTypeService,
+	departmentService *DepartmentService,
+	positionService *PositionService,
+) *EmployeeImportService {
+	return &EmployeeImportService{
+		employeeService: employeeService,
+		allowanceTypeService: allowanceTypeService,
+		departmentService: departmentService,
+		positionService: positionService,
+	}
+}
+
+// Parse reads CSV or XLSX file (by extension) and validates every row,
+// allowances are matched against active allowance types by name or code,
+// department and position by name and manager by email or name
+func (service *EmployeeImportService) Parse(ctx context.Context, filename string, file io.Reader) ([]dto.EmployeeImportRow, error) {
+	var records [][]string
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = readCsvRecords(file)
+	case ".xlsx":
+		records, err = readXlsxRecords(file)
+	default:
+		return nil, &exceptions.ValidationError{
+			Message: "File must be CSV or XLSX",
+			Errors: map[string]string{"file": "File must be CSV or XLSX"},
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, &exceptions.ValidationError{Message: "File is empty, download the template for the expected columns"}
+	}
+	columns := map[string]int{}
+	for index, header := range records[0] {
+		columns[normalizeImportHeader(header)] = index
+	}
+	for _, column := range EmployeeImportColumns {
+		if _, ok := columns[column]; !ok && !slices.Contains(employeeImportOptionalColumns, column) {
+			return nil, &exceptions.ValidationError{Message: fmt.Sprintf("Column %s is missing, download the template for the expected columns", column)}
+		}
+	}
+	if len(records)-1 > EmployeeImportMaxRows {
+		return nil, &exceptions.ValidationError{Message: fmt.Sprintf("File contains more than %d rows, split it into smaller files", EmployeeImportMaxRows)}
+	}
+
+	allowanceTypes, err := service.allowanceTypeService.GetAll(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	lookup := newAllowanceTypeLookup(*allowanceTypes)
+	organization, err := service.newOrganizationLookup(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []dto.EmployeeImportRow{}
+	for index, record := range records[1:] {
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		baseSalary, baseSalaryErr := parseImportMoney(value("base_salary"))
+		row := dto.EmployeeImportRow{
+			Line: index + 2,
+			Data: dto.CreateEmployeeRequest{
+				Name: value("name"),
+				Email: value("email"),
+				TaxNumber: value("tax_number"),
+				Gender: normalizeImportGender(value("gender")),
+				HiredDate: normalizeImportDate(value("hired_date")),
+				Address: value("address"),
+				Status: strings.ToUpper(value("status")),
+				BaseSalary: baseSalary,
+				Allowances: splitImportAllowances(value("allowances")),
+			},
+			Department: value("department"),
+			Position: value("position"),
+			Manager: value("manager"),
+		}
+		row.Errors = validateImportRow(&row.Data)
+		if baseSalaryErr != nil {
+			if row.Errors == nil {
+				row.Errors = map[string]string{}
+			}
+			row.Errors["base_salary"] = "Base salary must be a number with up to 2 decimals"
+		}
+		for _, allowance := range row.Data.Allowances {
+			if _, ok := lookup.find(allowance); !ok {
+				if row.Errors == nil {
+					row.Errors = map[string]string{}
+				}
+				row.Errors["allowances"] = fmt.Sprintf("Allowance %s is not found or inactive", allowance)
+			}
+		}
+		for field, message := range organization.resolve(&row) {
+			if row.Errors == nil {
+				row.Errors = map[string]string{}
+			}
+			row.Errors[field] = message
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Import stores the rows in one transaction, rows are validated again because they come back
+// from the preview page, nothing is imported when any of them is no longer valid
+func (service *EmployeeImportService) Import(ctx context.Context, rows []dto.EmployeeImportRow) (int, error) {
+	if len(rows) == 0 {
+		return 0, &exceptions.ValidationError{Message: "There is no valid row to import"}
+	}
+	invalidLines := []string{}
+	for i := range rows {
+		if len(validateImportRow(&rows[i].Data)) > 0 {
+			invalidLines = append(invalidLines, strconv.Itoa(rows[i].Line))
+		}
+	}
+	if len(invalidLines) > 0 {
+		return 0, &exceptions.ValidationError{
+			Message: fmt.Sprintf("Row %s is not valid anymore, no employee is imported, please upload the file again", strings.Join(invalidLines, ", ")),
+		}
+	}
+	return service.employeeService.Import(ctx, rows)
+}
+
+// organizationLookup finds department and position by name and manager by email or name case-insensitively
+type organizationLookup struct {
+	departments map[string]int
+	positions map[string]int
+	// managers keeps every match, names are not unique
+	managers map[string][]int
+}
+
+func (service *EmployeeImportService) newOrganizationLookup(ctx context.Context) (*organizationLookup, error) {
+	departments, err := service.departmentService.GetTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := service.positionService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	employees, err := service.employeeService.GetOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := &organizationLookup{departments: map[string]int{}, positions: map[string]int{}, managers: map[string][]int{}}
+	for _, department := range *departments {
+		lookup.departments[strings.ToUpper(department.Name)] = department.Id
+	}
+	for _, position := range *positions {
+		lookup.positions[strings.ToUpper(position.Name)] = position.Id
+	}
+	for _, employee := range *employees {
+		keys := []string{strings.ToUpper(employee.Name)}
+		if employee.Email.Valid {
+			keys = append(keys, strings.ToUpper(employee.Email.String))
+		}
+		for _, key := range keys {
+			if !slices.Contains(lookup.managers[key], employee.Id) {
+				lookup.managers[key] = append(lookup.managers[key], employee.Id)
+			}
+		}
+	}
+	return lookup, nil
+}
+
+// resolve sets ids of the organization values of the row, empty value is left unset
+func (lookup *organizationLookup) resolve(row *dto.EmployeeImportRow) map[string]string {
+	errors := map[string]string{}
+	if row.Department != "" {
+		if id, ok := lookup.departments[strings.ToUpper(row.Department)]; ok {
+			row.Data.DepartmentId = id
+		} else {
+			errors["department"] = fmt.Sprintf("Department %s is not found", row.Department)
+		}
+	}
+	if row.Position != "" {
+		if id, ok := lookup.positions[strings.ToUpper(row.Position)]; ok {
+			row.Data.PositionId = id
+		} else {
+			errors["position"] = fmt.Sprintf("Position %s is not found", row.Position)
+		}
+	}
+	if row.Manager != "" {
+		switch ids := lookup.managers[strings.ToUpper(row.Manager)]; len(ids) {
+		case 0:
+			errors["manager"] = fmt.Sprintf("Manager %s is not found", row.Manager)
+		case 1:
+			row.Data.ManagerId = ids[0]
+		default:
+			errors["manager"] = fmt.Sprintf("Manager %s matches more than one employee, use the email instead", row.Manager)
+		}
+	}
+	return errors
+}
+
+// WriteTemplate writes empty import file with the expected header and an example row
+func (service *EmployeeImportService) WriteTemplate(w io.Writer, format string) error {
+	example := []string{
+		"John Doe", "john@example.com", "123456789", "Male", "2024-01-31", "Main Street 1", "ACTIVE",
+		"Engineering", "Software Engineer", "jane@example.com", "5000000.00", "Medical;Housing",
+	}
+	if format == "xlsx" {
+		file := excelize.NewFile()
+		defer file.Close()
+		sheet := file.GetSheetName(0)
+		if err := file.SetSheetRow(sheet, "A1", &EmployeeImportColumns); err != nil {
+			return err
+		}
+		if err := file.SetSheetRow(sheet, "A2", &example); err != nil {
+			return err
+		}
+		return file.Write(w)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write(EmployeeImportColumns)
+	writer.Write(example)
+	writer.Flush()
+	return writer.Error()
+}
+
+// validateImportRow validates the row like the create form, the status must be one a new employee can start with
+func validateImportRow(data *dto.CreateEmployeeRequest) map[string]string {
+	errors := map[string]string{}
+	if err := validation.Validator.Struct(data); err != nil {
+		errors = validation.FormatValidationErrors(err)
+		if len(errors) == 0 {
+			errors["row"] = err.Error()
+		}
+	}
+	if _, ok := errors["status"]; !ok {
+		if message := initialStatusError(data.Status); message != "" {
+			errors["status"] = message
+		}
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+func readCsvRecords(file io.Reader) ([][]string, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, &exceptions.ValidationError{Message: fmt.Sprintf("File is not a valid CSV: %s", err.Error())}
+	}
+	// Excel saves CSV with byte order mark
+	if len(records) > 0 && len(records[0]) > 0 {
+		records[0][0] = strings.TrimPrefix(records[0][0], "\uFEFF")
+	}
+	return records, nil
+}
+
+func readXlsxRecords(file io.Reader) ([][]string, error) {
+	workbook, err := excelize.OpenReader(file, excelize.Options{
+		UnzipSizeLimit: employeeImportUnzipSizeLimit,
+		UnzipXMLSizeLimit: employeeImportUnzipXMLSizeLimit,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "unzip size exceeds") {
+			return nil, &exceptions.ValidationError{Message: fmt.Sprintf("XLSX content is larger than %d MB when unzipped", employeeImportUnzipSizeLimit>>20)}
+		}
+		return nil, &exceptions.ValidationError{Message: "File is not a valid XLSX"}
+	}
+	defer workbook.Close()
+
+	// Raw value keeps date cells as serial number regardless of the display format
+	return workbook.GetRows(workbook.GetSheetName(0), excelize.Options{RawCellValue: true})
+}
+
+// normalizeImportHeader maps header like "Tax Number" to tax_number
+func normalizeImportHeader(header string) string {
+	header = strings.ToLower(strings.TrimSpace(header))
+	return strings.Join(strings.Fields(strings.ReplaceAll(header, "_", " ")), "_")
+}
+
+func normalizeImportGender(gender string) string {
+	switch strings.ToLower(gender) {
+	case "male", "m":
+		return "Male"
+	case "female", "f":
+		return "Female"
+	}
+	return gender
+}
+
+// normalizeImportDate accepts Y-m-d and spreadsheet serial date
+func normalizeImportDate(value string) string {
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		if date, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			return date.Format("2006-01-02")
+		}
+	}
+	for _, layout := range []string{"2006-01-02", "2006/01/02", "2006-01-02 15:04:05"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Format("2006-01-02")
+		}
+	}
+	return value
+}
+
+// parseImportMoney reads amount that may be written with thousand separator, empty value is zero
+func parseImportMoney(value string) (models.Money, error) {
+	value = strings.ReplaceAll(value, ",", "")
+	if value == "" {
+		return 0, nil
+	}
+	return models.ParseMoney(value)
+}
+
+// splitImportAllowances splits allowances delimited by semicolon, pipe or comma,
+// empty value stays nil so the required rule catches it
+func splitImportAllowances(value string) []string {
+	var allowances []string
+	for _, allowance := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == '|' || r == ','
+	}) {
+		if allowance = strings.TrimSpace(allowance); allowance != "" {
+			allowances = append(allowances, allowance)
+		}
+	}
+	return allowances
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestReadXlsxRecords(t *testing.T) {
+	file := excelize.NewFile()
+	file.SetSheetRow("Sheet1", "A1", &[]any{"name", "email"})
+	file.SetSheetRow("Sheet1", "A2", &[]any{"John Doe", "john@example.com"})
+	var buffer bytes.Buffer
+	if err := file.Write(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := readXlsxRecords(&buffer)
+	if err != nil || len(records) != 2 || records[1][0] != "John Doe" {
+		t.Fatalf("records = %q, %v, want header and one row", records, err)
+	}
+}
+
+func TestReadXlsxRecordsUnzipLimit(t *testing.T) {
+	// Zeros compress to a tiny upload but unzip beyond the limit
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := make([]byte, 1<<20)
+	for i := 0; i <= employeeImportUnzipSizeLimit>>20; i++ {
+		sheet.Write(chunk)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buffer.Len() > EmployeeImportMaxSize {
+		t.Fatalf("upload is %d bytes, want it below the upload limit", buffer.Len())
+	}
+
+	_, err = readXlsxRecords(&buffer)
+	var validationError *exceptions.ValidationError
+	if !errors.As(err, &validationError) || !strings.Contains(validationError.Message, "larger than 64 MB") {
+		t.Fatalf("got %v, want validation error of the unzip limit", err)
+	}
+}
+
+func TestImportRejectsInvalidRows(t *testing.T) {
+	validation.Init()
+	valid := dto.CreateEmployeeRequest{
+		Name: "John Doe", Email: "john@example.com", TaxNumber: "123456789", Gender: "Male",
+		HiredDate: "2024-01-31", Address: "Main Street 1", Status: "ACTIVE", Allowances: []string{"1"},
+	}
+	invalid := valid
+	invalid.Email = "not an email"
+	rows := []dto.EmployeeImportRow{{Line: 2, Data: valid}, {Line: 3, Data: invalid}, {Line: 5, Data: invalid}}
+
+	// Employee service is not set, the rows must be rejected before anything is stored
+	service := &EmployeeImportService{}
+	imported, err := service.Import(context.Background(), rows)
+	var validationError *exceptions.ValidationError
+	if imported != 0 || !errors.As(err, &validationError) || !strings.Contains(validationError.Message, "Row 3, 5 ") {
+		t.Fatalf("got %d, %v, want validation error of row 3 and 5", imported, err)
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+// LoginThrottleService slows down password guessing per username and per IP address,
+// after half of the max attempts each failure doubles the wait (1s, 2s, 4s, ...)
+// until the max attempts lock the login out
+type LoginThrottleService struct {
+	failedLoginRepository *repositories.FailedLoginRepository
+}
+
+func NewLoginThrottleService(failedLoginRepository *repositories.FailedLoginRepository) *LoginThrottleService {
+	return &LoginThrottleService{failedLoginRepository: failedLoginRepository}
+}
+
+func normalizeLoginUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func retryAfter(stats *repositories.FailedLoginStatistics, maxAttempts int, lockout time.Duration, now time.Time) time.Duration {
+	if stats.Attempts == 0 || !stats.LastAttemptAt.Valid {
+		return 0
+	}
+	grace := maxAttempts / 2
+	if stats.Attempts <= grace {
+		return 0
+	}
+	delay := lockout
+	if stats.Attempts < maxAttempts {
+		delay = min(time.Second << (stats.Attempts - grace - 1), lockout)
+	}
+	return max(0, stats.LastAttemptAt.Time.Add(delay).Sub(now))
+}
+
+// RetryAfter returns how long the username or IP address must wait before the next password attempt
+func (service *LoginThrottleService) RetryAfter(ctx context.Context, username string, ipAddress string) (time.Duration, error) {
+	return service.retryAfter(ctx, models.LoginFactorPassword, username, ipAddress)
+}
+
+// RetryAfterTwoFactor returns how long the username or IP address must wait before the next two factor code,
+// codes are counted apart from passwords so logging in with the right password does not reset them
+func (service *LoginThrottleService) RetryAfterTwoFactor(ctx context.Context, username string, ipAddress string) (time.Duration, error) {
+	return service.retryAfter(ctx, models.LoginFactorTwoFactor, username, ipAddress)
+}
+
+func (service *LoginThrottleService) retryAfter(ctx context.Context, factor string, username string, ipAddress string) (time.Duration, error) {
+	config := configs.Get().Auth
+	lockout := time.Duration(config.LoginLockout) * time.Second
+	now := time.Now()
+	since := now.Add(-time.Duration(config.LoginDecay) * time.Second)
+
+	byUsername, err := service.failedLoginRepository.GetStatisticsByUsername(ctx, normalizeLoginUsername(username), factor, since)
+	if err != nil {
+		return 0, err
+	}
+	byIp, err := service.failedLoginRepository.GetStatisticsByIp(ctx, ipAddress, since)
+	if err != nil {
+		return 0, err
+	}
+
+	return max(
+		retryAfter(byUsername, config.LoginMaxAttempts, lockout, now),
+		retryAfter(byIp, config.LoginMaxAttemptsPerIp, lockout, now),
+	), nil
+}
+
+func (service *LoginThrottleService) RecordFailure(ctx context.Context, username string, ipAddress string, userAgent string) error {
+	return service.recordFailure(ctx, models.LoginFactorPassword, username, ipAddress, userAgent)
+}
+
+func (service *LoginThrottleService) RecordTwoFactorFailure(ctx context.Context, username string, ipAddress string, userAgent string) error {
+	return service.recordFailure(ctx, models.LoginFactorTwoFactor, username, ipAddress, userAgent)
+}
+
+func (service *LoginThrottleService) recordFailure(ctx context.Context, factor string, username string, ipAddress string, userAgent string) error {
+	userAgent = utilities.TruncateString(userAgent, 255)
+	return service.failedLoginRepository.Create(ctx, &models.FailedLogin{
+		Username: normalizeLoginUsername(username),
+		IpAddress: ipAddress,
+		Factor: factor,
+		UserAgent: sql.NullString{String: userAgent, Valid: userAgent != ""},
+		AttemptedAt: time.Now(),
+	})
+}
+
+// Clear resets failed attempts of the username from the IP address once the login completes (second factor
+// included), failures from other addresses are kept so they cannot be reset by someone knowing the password
+func (service *LoginThrottleService) Clear(ctx context.Context, username string, ipAddress string) error {
+	return service.failedLoginRepository.DeleteByUsernameAndIp(ctx, normalizeLoginUsername(username), ipAddress)
+}
+
+// PurgeExpired deletes attempts that no longer count toward the throttle or a lockout
+func (service *LoginThrottleService) PurgeExpired(ctx context.Context) (int64, error) {
+	config := configs.Get().Auth
+	window := time.Duration(max(config.LoginDecay, config.LoginLockout)) * time.Second
+	return service.failedLoginRepository.DeleteBefore(ctx, time.Now().Add(-window))
+}
+
+func (service *LoginThrottleService) GetSummaries(ctx context.Context) (*[]repositories.FailedLoginSummary, error) {
+	return service.failedLoginRepository.GetSummaries(ctx)
+}
+
+// ClearEntries clears attempts of the username from the IP address, or everything when both are empty
+func (service *LoginThrottleService) ClearEntries(ctx context.Context, username string, ipAddress string) error {
+	if username == "" && ipAddress == "" {
+		return service.failedLoginRepository.DeleteAll(ctx)
+	}
+	return service.failedLoginRepository.DeleteByUsernameAndIp(ctx, normalizeLoginUsername(username), ipAddress)
+}
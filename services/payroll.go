@@ -0,0 +1,500 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/audit"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+	"gitlab.com/tozd/go/errors"
+)
+
+type PayrollService struct {
+	payrollRunRepository *repositories.PayrollRunRepository
+	payslipRepository *repositories.PayslipRepository
+	employeeRepository *repositories.EmployeeRepository
+	employeeAllowanceRepository *repositories.EmployeeAllowanceRepository
+	auditLogRepository *repositories.AuditLogRepository
+	db *sql.DB
+}
+
+func NewPayrollService(
+	payrollRunRepository *repositories.PayrollRunRepository,
+	payslipRepository *repositories.PayslipRepository,
+	employeeRepository *repositories.EmployeeRepository,
+	employeeAllowanceRepository *repositories.EmployeeAllowanceRepository,
+	auditLogRepository *repositories.AuditLogRepository,
+	db *sql.DB,
+) *PayrollService {
+	return &PayrollService{
+		payrollRunRepository: payrollRunRepository,
+		payslipRepository: payslipRepository,
+		employeeRepository: employeeRepository,
+		employeeAllowanceRepository: employeeAllowanceRepository,
+		auditLogRepository: auditLogRepository,
+		db: db,
+	}
+}
+
+func (service *PayrollService) Paginate(ctx context.Context, filter *dto.PayrollRunFilter) (*[]models.PayrollRun, int, error) {
+	return service.payrollRunRepository.Paginate(ctx, filter)
+}
+
+func (service *PayrollService) GetById(ctx context.Context, id int) (*models.PayrollRun, error) {
+	return service.payrollRunRepository.GetById(ctx, id)
+}
+
+func (service *PayrollService) GetPayslips(ctx context.Context, payrollRunId int) (*[]models.Payslip, error) {
+	return service.payslipRepository.GetByPayrollRunId(ctx, payrollRunId)
+}
+
+// GetPayslip returns payslip with its items, the payslip must belong to the run
+func (service *PayrollService) GetPayslip(ctx context.Context, payrollRunId int, id int) (*models.Payslip, error) {
+	payslip, err := service.payslipRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payslip.PayrollRunId != payrollRunId {
+		return nil, errors.Errorf("payslip not found id=%d in payroll run id=%d: %w", id, payrollRunId, sql.ErrNoRows)
+	}
+	return payslip, nil
+}
+
+func (service *PayrollService) GetPayslipsByEmployeeId(ctx context.Context, employeeId int) (*[]models.Payslip, error) {
+	return service.payslipRepository.GetByEmployeeId(ctx, employeeId)
+}
+
+// GetCorrectedPayslips returns payslips of the run replaced by the correction keyed by employee id,
+// it is empty for a regular run
+func (service *PayrollService) GetCorrectedPayslips(ctx context.Context, run *models.PayrollRun) (map[int64]*models.Payslip, error) {
+	corrected := map[int64]*models.Payslip{}
+	if !run.IsCorrection() {
+		return corrected, nil
+	}
+	payslips, err := service.payslipRepository.GetByPayrollRunId(ctx, int(run.CorrectsRunId.Int64))
+	if err != nil {
+		return nil, err
+	}
+	for i := range *payslips {
+		if payslip := &(*payslips)[i]; payslip.EmployeeId.Valid {
+			corrected[payslip.EmployeeId.Int64] = payslip
+		}
+	}
+	return corrected, nil
+}
+
+// Create starts payroll of the month with payslips of all active employees,
+// a month has one run until it is locked, after that it can only be corrected
+func (service *PayrollService) Create(ctx context.Context, data *dto.CreatePayrollRunRequest) (*models.PayrollRun, error) {
+	period, err := time.Parse("2006-01", data.Period)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.createRun(ctx, &models.PayrollRun{Period: period}, func(latest *models.PayrollRun) error {
+		if latest == nil {
+			return nil
+		}
+		message := fmt.Sprintf("Payroll of %s already exists, recalculate it instead", latest.PeriodLabel())
+		if latest.IsLocked() {
+			message = fmt.Sprintf("Payroll of %s is locked, create a correction instead", latest.PeriodLabel())
+		}
+		return &exceptions.AppError{Code: http.StatusConflict, Message: message}
+	})
+}
+
+// Correct reruns payroll of a locked month as a new run, the locked run is kept as it was paid
+func (service *PayrollService) Correct(ctx context.Context, data *dto.CreatePayrollCorrectionRequest) (*models.PayrollRun, error) {
+	run, err := service.payrollRunRepository.GetById(ctx, data.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !run.IsLocked() {
+		return nil, &exceptions.AppError{
+			Code: http.StatusConflict,
+			Message: fmt.Sprintf("Payroll of %s is not locked yet, recalculate it instead", run.PeriodLabel()),
+		}
+	}
+
+	correction := &models.PayrollRun{
+		Period: run.Period,
+		CorrectsRunId: sql.NullInt64{Int64: int64(run.Id), Valid: true},
+		Notes: sql.NullString{String: data.Notes, Valid: data.Notes != ""},
+	}
+	return service.createRun(ctx, correction, func(latest *models.PayrollRun) error {
+		if latest != nil && latest.Id != run.Id {
+			return &exceptions.AppError{
+				Code: http.StatusConflict,
+				Message: fmt.Sprintf("Payroll of %s is already corrected by run #%d", run.PeriodLabel(), latest.Id),
+			}
+		}
+		return nil
+	})
+}
+
+// createRun stores the run with its payslips, check gets the latest run of the month (nil when there is none)
+// while runs of the month are locked so concurrent requests cannot create the run twice
+func (service *PayrollService) createRun(ctx context.Context, run *models.PayrollRun, check func(latest *models.PayrollRun) error) (*models.PayrollRun, error) {
+	tx, err := service.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	payrollRunRepository := service.payrollRunRepository.WithTx(tx)
+	if err := payrollRunRepository.LockPeriod(ctx, run.Period); err != nil {
+		return nil, err
+	}
+	latest, err := payrollRunRepository.GetLatestByPeriod(ctx, run.Period)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err := check(latest); err != nil {
+		return nil, err
+	}
+
+	actor := audit.ActorFromContext(ctx)
+	run.Status = models.PayrollStatusDraft
+	run.CreatedBy = sql.NullInt64{Int64: int64(actor.UserId), Valid: actor.UserId > 0}
+
+	run, err = payrollRunRepository.Store(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.generatePayslips(ctx, tx, run); err != nil {
+		return nil, err
+	}
+
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionCreated,
+		models.AuditEntityPayrollRun,
+		run.Id,
+		nil,
+		payrollRunAuditValues(run),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Recalculate replaces payslips of draft run with the current employee data
+func (service *PayrollService) Recalculate(ctx context.Context, id int) (*models.PayrollRun, error) {
+	tx, err := service.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	run, err := service.payrollRunRepository.WithTx(tx).GetByIdForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != models.PayrollStatusDraft {
+		return nil, payrollStatusError(run, "recalculated")
+	}
+	before := payrollRunAuditValues(run)
+
+	if _, err := service.payslipRepository.WithTx(tx).DestroyByPayrollRunId(ctx, run.Id); err != nil {
+		return nil, err
+	}
+	if err := service.generatePayslips(ctx, tx, run); err != nil {
+		return nil, err
+	}
+
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionUpdated,
+		models.AuditEntityPayrollRun,
+		run.Id,
+		before,
+		payrollRunAuditValues(run),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Review marks draft run as checked, it can't be recalculated until it's reopened
+func (service *PayrollService) Review(ctx context.Context, id int) (*models.PayrollRun, error) {
+	return service.changeStatus(ctx, id, models.PayrollStatusDraft, models.PayrollStatusReviewed, func(run *models.PayrollRun, actor audit.Actor, now time.Time) error {
+		if run.TotalEmployee == 0 {
+			return &exceptions.AppError{
+				Code: http.StatusConflict,
+				Message: fmt.Sprintf("Payroll of %s has no payslip to review", run.PeriodLabel()),
+			}
+		}
+		run.ReviewedBy = sql.NullInt64{Int64: int64(actor.UserId), Valid: actor.UserId > 0}
+		run.ReviewedAt = sql.NullTime{Time: now, Valid: true}
+		return nil
+	})
+}
+
+// Reopen moves reviewed run back to draft so it can be recalculated
+func (service *PayrollService) Reopen(ctx context.Context, id int) (*models.PayrollRun, error) {
+	return service.changeStatus(ctx, id, models.PayrollStatusReviewed, models.PayrollStatusDraft, func(run *models.PayrollRun, actor audit.Actor, now time.Time) error {
+		run.ReviewedBy = sql.NullInt64{}
+		run.ReviewedAt = sql.NullTime{}
+		return nil
+	})
+}
+
+// Lock finalizes reviewed run, locked run and its payslips are never changed again
+func (service *PayrollService) Lock(ctx context.Context, id int) (*models.PayrollRun, error) {
+	return service.changeStatus(ctx, id, models.PayrollStatusReviewed, models.PayrollStatusLocked, func(run *models.PayrollRun, actor audit.Actor, now time.Time) error {
+		run.LockedBy = sql.NullInt64{Int64: int64(actor.UserId), Valid: actor.UserId > 0}
+		run.LockedAt = sql.NullTime{Time: now, Valid: true}
+		return nil
+	})
+}
+
+func (service *PayrollService) changeStatus(
+	ctx context.Context,
+	id int,
+	from string,
+	to string,
+	apply func(run *models.PayrollRun, actor audit.Actor, now time.Time) error,
+) (*models.PayrollRun, error) {
+	tx, err := service.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	payrollRunRepository := service.payrollRunRepository.WithTx(tx)
+	run, err := payrollRunRepository.GetByIdForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != from {
+		return nil, payrollStatusError(run, "changed to "+strings.ToLower(to))
+	}
+	before := payrollRunAuditValues(run)
+
+	run.Status = to
+	if err := apply(run, audit.ActorFromContext(ctx), time.Now()); err != nil {
+		return nil, err
+	}
+	updated, err := payrollRunRepository.UpdateStatus(ctx, run, from)
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		return nil, payrollStatusError(run, "changed to "+strings.ToLower(to))
+	}
+
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionUpdated,
+		models.AuditEntityPayrollRun,
+		run.Id,
+		before,
+		payrollRunAuditValues(run),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Destroy deletes draft run, reviewed run must be reopened first and locked run is kept
+func (service *PayrollService) Destroy(ctx context.Context, id int) (*models.PayrollRun, error) {
+	tx, err := service.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	payrollRunRepository := service.payrollRunRepository.WithTx(tx)
+	run, err := payrollRunRepository.GetByIdForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != models.PayrollStatusDraft {
+		return nil, payrollStatusError(run, "deleted")
+	}
+	deleted, err := payrollRunRepository.Destroy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, payrollStatusError(run, "deleted")
+	}
+
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionDeleted,
+		models.AuditEntityPayrollRun,
+		run.Id,
+		payrollRunAuditValues(run),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// generatePayslips snapshots salary, allowances and deductions of the payable employees
+// into payslips of the run and stores the totals on the run
+func (service *PayrollService) generatePayslips(ctx context.Context, tx *sql.Tx, run *models.PayrollRun) error {
+	periodEnd := run.Period.AddDate(0, 1, -1)
+	employees, err := service.employeeRepository.WithTx(tx).GetPayable(ctx, periodEnd)
+	if err != nil {
+		return err
+	}
+
+	employeeAllowanceRepository := service.employeeAllowanceRepository.WithTx(tx)
+	payslipRepository := service.payslipRepository.WithTx(tx)
+	run.TotalEmployee = 0
+	run.TotalGross = 0
+	run.TotalDeduction = 0
+	run.TotalNet = 0
+	for i := range *employees {
+		employee := &(*employees)[i]
+		allowances, err := employeeAllowanceRepository.GetByEmployeeId(ctx, employee.Id)
+		if err != nil {
+			return err
+		}
+		payslip := calculatePayslip(employee, *allowances, configs.Get().Payroll)
+		payslip.PayrollRunId = run.Id
+		if _, err := payslipRepository.Store(ctx, payslip); err != nil {
+			return err
+		}
+
+		run.TotalEmployee++
+		run.TotalGross += payslip.GrossPay
+		run.TotalDeduction += payslip.TotalDeduction
+		run.TotalNet += payslip.NetPay
+	}
+
+	payrollRunRepository := service.payrollRunRepository.WithTx(tx)
+	updated, err := payrollRunRepository.UpdateTotals(ctx, run)
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		// MySQL counts changed rows only, unchanged totals of a draft run are not a conflict
+		current, err := payrollRunRepository.GetById(ctx, run.Id)
+		if err != nil {
+			return err
+		}
+		if current.Status != models.PayrollStatusDraft {
+			return payrollStatusError(current, "recalculated")
+		}
+	}
+	return nil
+}
+
+// calculatePayslip computes pay of the employee, income tax applies to base salary
+// and taxable allowances while social security applies to base salary only
+func calculatePayslip(employee *models.Employee, allowances []models.EmployeeAllowance, config configs.PayrollConfig) *models.Payslip {
+	payslip := &models.Payslip{
+		EmployeeId: sql.NullInt64{Int64: int64(employee.Id), Valid: true},
+		EmployeeName: employee.Name,
+		EmployeeEmail: employee.Email,
+		EmployeeTaxNumber: employee.TaxNumber,
+		BaseSalary: employee.BaseSalary,
+		Items: []models.PayslipItem{},
+	}
+
+	taxable := payslip.BaseSalary
+	for _, allowance := range allowances {
+		amount := allowance.EffectiveAmount()
+		payslip.Items = append(payslip.Items, models.PayslipItem{
+			Type: models.PayslipItemAllowance,
+			Code: allowance.Code,
+			Name: allowance.Allowance,
+			Amount: amount,
+			IsTaxable: allowance.IsTaxable,
+		})
+		payslip.TotalAllowance += amount
+		if allowance.IsTaxable {
+			taxable += amount
+		}
+	}
+
+	deductions := []struct {
+		code string
+		name string
+		rate float64
+		base models.Money
+	}{
+		{"INCOME_TAX", "Income Tax", config.TaxRate, taxable},
+		{"SOCIAL_SECURITY", "Social Security", config.SocialSecurityRate, payslip.BaseSalary},
+	}
+	for _, deduction := range deductions {
+		if deduction.rate <= 0 {
+			continue
+		}
+		amount := deduction.base.Percent(deduction.rate)
+		payslip.Items = append(payslip.Items, models.PayslipItem{
+			Type: models.PayslipItemDeduction,
+			Code: deduction.code,
+			Name: fmt.Sprintf("%s (%s%%)", deduction.name, strconv.FormatFloat(deduction.rate, 'f', -1, 64)),
+			Amount: amount,
+		})
+		payslip.TotalDeduction += amount
+	}
+
+	payslip.GrossPay = payslip.BaseSalary + payslip.TotalAllowance
+	payslip.NetPay = payslip.GrossPay - payslip.TotalDeduction
+	return payslip
+}
+
+func payrollStatusError(run *models.PayrollRun, action string) error {
+	return &exceptions.AppError{
+		Code: http.StatusConflict,
+		Message: fmt.Sprintf("Payroll of %s is %s and can't be %s", run.PeriodLabel(), strings.ToLower(run.Status), action),
+	}
+}
+
+// payrollRunAuditValues is snapshot of the run status and totals, payslips are not audited
+// because they are regenerated as a whole
+func payrollRunAuditValues(run *models.PayrollRun) audit.Values {
+	values := audit.Values{
+		"period": run.Period.Format("2006-01"),
+		"status": run.Status,
+		"total_employee": run.TotalEmployee,
+		"total_gross": utilities.FormatMoney(run.TotalGross),
+		"total_deduction": utilities.FormatMoney(run.TotalDeduction),
+		"total_net": utilities.FormatMoney(run.TotalNet),
+	}
+	if run.IsCorrection() {
+		values["corrects_run_id"] = run.CorrectsRunId.Int64
+		values["notes"] = run.Notes.String
+	}
+	return values
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+	"github.com/anggadarkprince/crud-employee-go/models"
+)
+
+func TestCalculatePayslip(t *testing.T) {
+	employee := &models.Employee{Id: 7, Name: "Angga Ari", BaseSalary: 1000000000}
+	allowances := []models.EmployeeAllowance{
+		{Code: "MEAL", Allowance: "Meal", DefaultAmount: 50000000, Amount: models.NullMoney{Money: 150000050, Valid: true}, IsTaxable: true},
+		{Code: "TRANSPORT", Allowance: "Transport", DefaultAmount: 75000000},
+	}
+
+	tests := []struct {
+		name          string
+		config        configs.PayrollConfig
+		wantItems     []models.PayslipItem
+		wantDeduction models.Money
+		wantNetPay    models.Money
+	}{
+		{
+			name:   "taxable and non taxable allowances",
+			config: configs.PayrollConfig{TaxRate: 5, SocialSecurityRate: 2},
+			wantItems: []models.PayslipItem{
+				{Type: models.PayslipItemAllowance, Code: "MEAL", Name: "Meal", Amount: 150000050, IsTaxable: true},
+				{Type: models.PayslipItemAllowance, Code: "TRANSPORT", Name: "Transport", Amount: 75000000},
+				// 5% of base salary and the taxable meal only, 57500002.5 cents is rounded up
+				{Type: models.PayslipItemDeduction, Code: "INCOME_TAX", Name: "Income Tax (5%)", Amount: 57500003},
+				{Type: models.PayslipItemDeduction, Code: "SOCIAL_SECURITY", Name: "Social Security (2%)", Amount: 20000000},
+			},
+			wantDeduction: 77500003,
+			wantNetPay:    1147500047,
+		},
+		{
+			name:   "disabled deduction",
+			config: configs.PayrollConfig{TaxRate: 2.5},
+			wantItems: []models.PayslipItem{
+				{Type: models.PayslipItemAllowance, Code: "MEAL", Name: "Meal", Amount: 150000050, IsTaxable: true},
+				{Type: models.PayslipItemAllowance, Code: "TRANSPORT", Name: "Transport", Amount: 75000000},
+				{Type: models.PayslipItemDeduction, Code: "INCOME_TAX", Name: "Income Tax (2.5%)", Amount: 28750001},
+			},
+			wantDeduction: 28750001,
+			wantNetPay:    1196250049,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payslip := calculatePayslip(employee, allowances, test.config)
+
+			if !payslip.EmployeeId.Valid || payslip.EmployeeId.Int64 != 7 || payslip.EmployeeName != "Angga Ari" {
+				t.Errorf("employee = %v %s, want snapshot of employee 7", payslip.EmployeeId, payslip.EmployeeName)
+			}
+			if len(payslip.Items) != len(test.wantItems) {
+				t.Fatalf("items = %+v, want %+v", payslip.Items, test.wantItems)
+			}
+			for i, item := range payslip.Items {
+				if item != test.wantItems[i] {
+					t.Errorf("item %d = %+v, want %+v", i, item, test.wantItems[i])
+				}
+			}
+			if payslip.TotalAllowance != 225000050 || payslip.GrossPay != 1225000050 {
+				t.Errorf("allowance = %s, gross = %s, want 2250000.50 and 12250000.50", payslip.TotalAllowance, payslip.GrossPay)
+			}
+			if payslip.TotalDeduction != test.wantDeduction || payslip.NetPay != test.wantNetPay {
+				t.Errorf("deduction = %s, net = %s, want %s and %s", payslip.TotalDeduction, payslip.NetPay, test.wantDeduction, test.wantNetPay)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/pdf"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+var payslipFilenamePattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// PayslipFilename returns download name of the payslip, e.g. payslip-2024-01-john-doe.pdf
+func (service *PayrollService) PayslipFilename(run *models.PayrollRun, payslip *models.Payslip) string {
+	name := strings.Trim(payslipFilenamePattern.ReplaceAllString(strings.ToLower(payslip.EmployeeName), "-"), "-")
+	return fmt.Sprintf("payslip-%s-%s.pdf", run.Period.Format("2006-01"), name)
+}
+
+// WritePayslipPdf writes single page A4 payslip, payslip of a run that is not locked
+// is marked so it is not mistaken for the final one
+func (service *PayrollService) WritePayslipPdf(w io.Writer, run *models.PayrollRun, payslip *models.Payslip) error {
+	const margin = 50.0
+	const rowHeight = 18.0
+
+	document := pdf.New(w, pdf.A4)
+	size := document.Size()
+	right := size.Width - margin
+	y := margin
+
+	document.Text(margin, y+16, pdf.HelveticaBold, 18, "PAYSLIP")
+	document.TextRight(right, y+16, pdf.HelveticaBold, 12, configs.Get().App.Name)
+	y += 30
+	document.Text(margin, y+10, pdf.Helvetica, 10, "Period: "+run.PeriodLabel())
+	reference := fmt.Sprintf("Payroll run #%d", run.Id)
+	if run.IsCorrection() {
+		reference += fmt.Sprintf(" (correction of #%d)", run.CorrectsRunId.Int64)
+	}
+	document.TextRight(right, y+10, pdf.Helvetica, 10, reference)
+	y += 16
+	if !run.IsLocked() {
+		document.Text(margin, y+10, pdf.HelveticaBold, 10, fmt.Sprintf("%s - not final until the payroll is locked", run.Status))
+		y += 16
+	}
+	y += 8
+	document.Line(margin, y, right, y, 0.5)
+	y += 10
+
+	details := [][2]string{
+		{"Employee", payslip.EmployeeName},
+		{"Employee ID", employeeReference(payslip)},
+		{"Email", payslip.EmployeeEmail.String},
+		{"Tax Number", payslip.EmployeeTaxNumber.String},
+	}
+	for _, detail := range details {
+		document.Text(margin, y+10, pdf.HelveticaBold, 10, detail[0])
+		document.Text(margin+100, y+10, pdf.Helvetica, 10, pdf.Truncate(pdf.Helvetica, 10, detail[1], right-margin-100))
+		y += 16
+	}
+	y += 14
+
+	section := func(title string) {
+		document.FillRect(margin, y, right-margin, rowHeight, 0.9)
+		document.Text(margin+5, y+12, pdf.HelveticaBold, 10, title)
+		document.TextRight(right-5, y+12, pdf.HelveticaBold, 10, "Amount")
+		y += rowHeight
+	}
+	row := func(font pdf.Font, label string, amount models.Money) {
+		document.Text(margin+5, y+12, font, 10, pdf.Truncate(font, 10, label, right-margin-120))
+		document.TextRight(right-5, y+12, font, 10, utilities.FormatMoney(amount))
+		y += rowHeight
+		document.Line(margin, y, right, y, 0.3)
+	}
+
+	section("Earnings")
+	row(pdf.Helvetica, "Base Salary", payslip.BaseSalary)
+	for _, item := range payslip.Allowances() {
+		label := item.Name
+		if item.IsTaxable {
+			label += " *"
+		}
+		row(pdf.Helvetica, label, item.Amount)
+	}
+	row(pdf.HelveticaBold, "Gross Pay", payslip.GrossPay)
+	y += 14
+
+	section("Deductions")
+	for _, item := range payslip.Deductions() {
+		row(pdf.Helvetica, item.Name, item.Amount)
+	}
+	row(pdf.HelveticaBold, "Total Deduction", payslip.TotalDeduction)
+	y += 14
+
+	document.FillRect(margin, y, right-margin, rowHeight+6, 0.8)
+	document.Text(margin+5, y+15, pdf.HelveticaBold, 12, "Net Pay")
+	document.TextRight(right-5, y+15, pdf.HelveticaBold, 12, utilities.FormatMoney(payslip.NetPay))
+	y += rowHeight + 20
+
+	document.Text(margin, y+10, pdf.Helvetica, 8, "* Taxable allowance")
+	document.Text(margin, size.Height-margin/2, pdf.Helvetica, 8, "Generated at "+time.Now().Format("2006-01-02 15:04"))
+	if run.LockedAt.Valid {
+		document.TextRight(right, size.Height-margin/2, pdf.Helvetica, 8, "Locked at "+run.LockedAt.Time.Format("2006-01-02 15:04"))
+	}
+
+	return pdfError(document.Close())
+}
+
+// employeeReference is the employee id at the time of the run, it's gone once the employee is purged
+func employeeReference(payslip *models.Payslip) string {
+	if !payslip.EmployeeId.Valid {
+		return "-"
+	}
+	return fmt.Sprintf("%d", payslip.EmployeeId.Int64)
+}
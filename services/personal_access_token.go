@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+)
+
+type PersonalAccessTokenService struct {
+	personalAccessTokenRepository *repositories.PersonalAccessTokenRepository
+}
+
+func NewPersonalAccessTokenService(personalAccessTokenRepository *repositories.PersonalAccessTokenRepository) *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{personalAccessTokenRepository: personalAccessTokenRepository}
+}
+
+func (service *PersonalAccessTokenService) GetByUserId(ctx context.Context, userId int) (*[]models.PersonalAccessToken, error) {
+	return service.personalAccessTokenRepository.GetByUserId(ctx, userId)
+}
+
+// Create issues a new token limited to the given permissions,
+// the plain token is returned only once and just its hash is stored
+func (service *PersonalAccessTokenService) Create(ctx context.Context, data *dto.CreatePersonalAccessTokenRequest, permissions []string) (string, *models.PersonalAccessToken, error) {
+	for _, scope := range data.Scopes {
+		if !slices.Contains(permissions, scope) {
+			return "", nil, &exceptions.ValidationError{
+				Message: "Scope is not allowed",
+				Errors: map[string]string{"scopes": "Scope " + scope + " is not granted to your role"},
+			}
+		}
+	}
+
+	var expiredAt sql.NullTime
+	if data.ExpiredAt != "" {
+		date, err := time.ParseInLocation("2006-01-02", data.ExpiredAt, time.Local)
+		if err != nil {
+			return "", nil, err
+		}
+		if !date.After(time.Now()) {
+			return "", nil, &exceptions.ValidationError{
+				Message: "Expiration date must be in the future",
+				Errors: map[string]string{"expired_at": "Expiration date must be in the future"},
+			}
+		}
+		expiredAt = sql.NullTime{Time: date, Valid: true}
+	}
+
+	random, err := utilities.RandomToken(20)
+	if err != nil {
+		return "", nil, err
+	}
+	plainToken := models.PersonalAccessTokenPrefix + random
+
+	token, err := service.personalAccessTokenRepository.Create(ctx, &models.PersonalAccessToken{
+		UserId: data.UserId,
+		Name: data.Name,
+		Token: utilities.HashToken(plainToken),
+		Scopes: data.Scopes,
+		ExpiredAt: expiredAt,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return plainToken, token, nil
+}
+
+func (service *PersonalAccessTokenService) Revoke(ctx context.Context, userId int, id int) error {
+	err := service.personalAccessTokenRepository.Delete(ctx, userId, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &exceptions.AppError{
+			Code: http.StatusNotFound,
+			Message: "Personal access token not found",
+			Err: err,
+		}
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"gitlab.com/tozd/go/errors"
+)
+
+type PositionService struct {
+	positionRepository *repositories.PositionRepository
+}
+
+func NewPositionService(positionRepository *repositories.PositionRepository) *PositionService {
+	return &PositionService{positionRepository: positionRepository}
+}
+
+func (service *PositionService) GetAll(ctx context.Context) (*[]models.Position, error) {
+	return service.positionRepository.GetAll(ctx)
+}
+
+func (service *PositionService) GetById(ctx context.Context, id int) (*models.Position, error) {
+	return service.positionRepository.GetById(ctx, id)
+}
+
+func (service *PositionService) Store(ctx context.Context, data *dto.CreatePositionRequest) (*models.Position, error) {
+	if err := service.ensureUniqueName(ctx, data.Name, 0); err != nil {
+		return nil, err
+	}
+	return service.positionRepository.Store(ctx, &models.Position{
+		Name: data.Name,
+		Description: sql.NullString{String: data.Description, Valid: data.Description != ""},
+	})
+}
+
+func (service *PositionService) Update(ctx context.Context, data *dto.UpdatePositionRequest) (*models.Position, error) {
+	if _, err := service.positionRepository.GetById(ctx, data.Id); err != nil {
+		return nil, err
+	}
+	if err := service.ensureUniqueName(ctx, data.Name, data.Id); err != nil {
+		return nil, err
+	}
+	return service.positionRepository.Update(ctx, &models.Position{
+		Id: data.Id,
+		Name: data.Name,
+		Description: sql.NullString{String: data.Description, Valid: data.Description != ""},
+	})
+}
+
+// Destroy deletes position that is not held by any employee (including the ones in trash)
+func (service *PositionService) Destroy(ctx context.Context, id int) (*models.Position, error) {
+	position, err := service.positionRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	total, err := service.positionRepository.CountEmployees(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if total > 0 {
+		return nil, &exceptions.AppError{
+			Code: http.StatusConflict,
+			Message: fmt.Sprintf("Position %s is held by %d employees, change their position first", position.Name, total),
+		}
+	}
+	if _, err := service.positionRepository.Destroy(ctx, id); err != nil {
+		return nil, err
+	}
+	return position, nil
+}
+
+func (service *PositionService) ensureUniqueName(ctx context.Context, name string, exceptId int) error {
+	existing, err := service.positionRepository.GetByName(ctx, name)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if existing != nil && existing.Id != exceptId {
+		return &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{"name": fmt.Sprintf("Position %s already exists", name)},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anggadarkprince/crud-employee-go/dto"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+)
+
+type RoleService struct {
+	roleRepository *repositories.RoleRepository
+	permissionRepository *repositories.PermissionRepository
+	db *sql.DB
+}
+
+func NewRoleService(
+	roleRepository *repositories.RoleRepository,
+	permissionRepository *repositories.PermissionRepository,
+	db *sql.DB,
+) *RoleService {
+	return &RoleService{
+		roleRepository: roleRepository,
+		permissionRepository: permissionRepository,
+		db: db,
+	}
+}
+
+func (service *RoleService) GetAll(ctx context.Context) (*[]models.Role, error) {
+	return service.roleRepository.GetAll(ctx)
+}
+
+func (service *RoleService) GetPermissions(ctx context.Context) (*[]models.Permission, error) {
+	return service.permissionRepository.GetAll(ctx)
+}
+
+func (service *RoleService) UpdatePermissions(ctx context.Context, data *dto.UpdateRolePermissionRequest) (*models.Role, error) {
+	role, err := service.roleRepository.GetById(ctx, data.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := service.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = service.roleRepository.WithTx(tx).SyncPermissions(ctx, role.Id, data.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	err = service.roleRepository.WithTx(tx).UpdateRequireTwoFactor(ctx, role.Id, data.RequireTwoFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
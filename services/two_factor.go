@@ -0,0 +1,250 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/configs"
+	"github.com/anggadarkprince/crud-employee-go/exceptions"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/encryption"
+	"github.com/anggadarkprince/crud-employee-go/pkg/totp"
+	"github.com/anggadarkprince/crud-employee-go/repositories"
+	"github.com/anggadarkprince/crud-employee-go/utilities"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const recoveryCodeCount = 8
+
+// TwoFactorSetup holds data to enroll the secret in authenticator app
+type TwoFactorSetup struct {
+	Secret string
+	// QrCode is PNG data URI of the provisioning URI
+	QrCode string
+}
+
+type TwoFactorService struct {
+	userRepository *repositories.UserRepository
+	roleRepository *repositories.RoleRepository
+	recoveryCodeRepository *repositories.RecoveryCodeRepository
+	db *sql.DB
+}
+
+func NewTwoFactorService(
+	userRepository *repositories.UserRepository,
+	roleRepository *repositories.RoleRepository,
+	recoveryCodeRepository *repositories.RecoveryCodeRepository,
+	db *sql.DB,
+) *TwoFactorService {
+	return &TwoFactorService{
+		userRepository: userRepository,
+		roleRepository: roleRepository,
+		recoveryCodeRepository: recoveryCodeRepository,
+		db: db,
+	}
+}
+
+// IsRequired tells whether role (user type) of the user enforces two factor authentication
+func (service *TwoFactorService) IsRequired(ctx context.Context, user *models.User) (bool, error) {
+	role, err := service.roleRepository.GetByName(ctx, user.UserType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return role.RequireTwoFactor, nil
+}
+
+func (service *TwoFactorService) CountRecoveryCodes(ctx context.Context, userId int) (int, error) {
+	return service.recoveryCodeRepository.CountUnused(ctx, userId)
+}
+
+// Enable generates a new secret that must be confirmed with a code before it is used on login
+func (service *TwoFactorService) Enable(ctx context.Context, user *models.User) error {
+	if user.HasTwoFactor() {
+		return &exceptions.ValidationError{Message: "Two-factor authentication is already enabled"}
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return err
+	}
+	encrypted, err := encryption.Encrypt(secret)
+	if err != nil {
+		return err
+	}
+	return service.userRepository.UpdateTwoFactorSecret(ctx, user.Id, sql.NullString{String: encrypted, Valid: true})
+}
+
+// Setup returns secret and QR code of the unconfirmed secret, nil when there is nothing to enroll
+func (service *TwoFactorService) Setup(user *models.User) (*TwoFactorSetup, error) {
+	if !user.TwoFactorSecret.Valid || user.HasTwoFactor() {
+		return nil, nil
+	}
+	secret, err := encryption.Decrypt(user.TwoFactorSecret.String)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := totp.URI(configs.Get().App.Name, user.Email, secret)
+	qr, err := qrcode.New(uri, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	var image bytes.Buffer
+	if err := png.Encode(&image, qr.Image(220)); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorSetup{
+		Secret: secret,
+		QrCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(image.Bytes()),
+	}, nil
+}
+
+// Confirm enables two factor authentication once the code matches, returns the plain recovery codes
+func (service *TwoFactorService) Confirm(ctx context.Context, user *models.User, code string) ([]string, error) {
+	if !user.TwoFactorSecret.Valid || user.HasTwoFactor() {
+		return nil, &exceptions.ValidationError{Message: "There is no two-factor setup to confirm"}
+	}
+	secret, err := encryption.Decrypt(user.TwoFactorSecret.String)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	step, ok := totp.Validate(secret, code, now)
+	if !ok {
+		return nil, &exceptions.ValidationError{
+			Message: "The code is invalid",
+			Errors: map[string]string{"code": "The code is invalid, check the time of your device"},
+		}
+	}
+
+	tx, err := service.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := service.userRepository.WithTx(tx).ConfirmTwoFactor(ctx, user.Id, now, step); err != nil {
+		return nil, err
+	}
+	codes, err := service.replaceRecoveryCodes(ctx, service.recoveryCodeRepository.WithTx(tx), user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, the password is required like Disable because
+// the new codes log in without the device
+func (service *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, user *models.User, password string) ([]string, error) {
+	if err := checkTwoFactorPassword(user, password); err != nil {
+		return nil, err
+	}
+	if !user.HasTwoFactor() {
+		return nil, &exceptions.ValidationError{Message: "Two-factor authentication is not enabled"}
+	}
+	return service.replaceRecoveryCodes(ctx, service.recoveryCodeRepository, user.Id)
+}
+
+// replaceRecoveryCodes generates codes formatted like "a1b2c-d3e4f", only their hashes are stored
+func (service *TwoFactorService) replaceRecoveryCodes(ctx context.Context, recoveryCodeRepository *repositories.RecoveryCodeRepository, userId int) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashedCodes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		random, err := utilities.RandomToken(5)
+		if err != nil {
+			return nil, err
+		}
+		code := random[:5] + "-" + random[5:]
+		codes = append(codes, code)
+		hashedCodes = append(hashedCodes, utilities.HashToken(code))
+	}
+	if err := recoveryCodeRepository.Replace(ctx, userId, hashedCodes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable removes the secret and recovery codes, the password is required to make sure it's really the user
+func (service *TwoFactorService) Disable(ctx context.Context, user *models.User, password string) error {
+	if err := checkTwoFactorPassword(user, password); err != nil {
+		return err
+	}
+	required, err := service.IsRequired(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required && user.HasTwoFactor() {
+		return &exceptions.ValidationError{Message: fmt.Sprintf("Two-factor authentication is required for %s users", user.UserType)}
+	}
+
+	tx, err := service.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := service.userRepository.WithTx(tx).UpdateTwoFactorSecret(ctx, user.Id, sql.NullString{}); err != nil {
+		return err
+	}
+	if err := service.recoveryCodeRepository.WithTx(tx).DeleteByUserId(ctx, user.Id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Verify checks the authenticator code or a recovery code on login, each code can only be used once
+func (service *TwoFactorService) Verify(ctx context.Context, user *models.User, code string, recoveryCode string) error {
+	if !user.HasTwoFactor() {
+		return exceptions.ErrInvalidTwoFactorCode
+	}
+
+	if recoveryCode != "" {
+		hashed := utilities.HashToken(strings.ToLower(strings.TrimSpace(recoveryCode)))
+		err := service.recoveryCodeRepository.Use(ctx, user.Id, hashed, time.Now())
+		if errors.Is(err, sql.ErrNoRows) {
+			return exceptions.ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+
+	secret, err := encryption.Decrypt(user.TwoFactorSecret.String)
+	if err != nil {
+		return err
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return exceptions.ErrInvalidTwoFactorCode
+	}
+	// Reject code replayed within its validity window
+	err = service.userRepository.UseTwoFactorStep(ctx, user.Id, step)
+	if errors.Is(err, sql.ErrNoRows) {
+		return exceptions.ErrInvalidTwoFactorCode
+	}
+	return err
+}
+
+// checkTwoFactorPassword makes sure it's really the user before two-factor settings are changed
+func checkTwoFactorPassword(user *models.User, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return &exceptions.ValidationError{
+			Message: "Password is wrong",
+			Errors: map[string]string{"two_factor_password": "Password is wrong"},
+		}
+	}
+	return nil
+}
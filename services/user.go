@@ -1,36 +1,110 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
-	"fmt"
-	"io"
-	"os"
-	"path/filepath"
+	"errors"
+	"image"
+	"log/slog"
 	"time"
 
 	"github.com/anggadarkprince/crud-employee-go/dto"
 	"github.com/anggadarkprince/crud-employee-go/exceptions"
 	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/imaging"
+	"github.com/anggadarkprince/crud-employee-go/pkg/storage"
+	"github.com/anggadarkprince/crud-employee-go/pkg/validation"
 	"github.com/anggadarkprince/crud-employee-go/repositories"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const avatarSize = 512
+
+// AvatarThumbnailSizes are square sizes (in pixels) of avatar thumbnails, templates pick one of them
+var AvatarThumbnailSizes = []int{64, 128, 256}
+
 type UserService struct {
 	userRepository *repositories.UserRepository
+	roleRepository *repositories.RoleRepository
+	personalAccessTokenRepository *repositories.PersonalAccessTokenRepository
+	auditLogRepository *repositories.AuditLogRepository
+	storage storage.Storage
 	db *sql.DB
 }
 
 func NewUserService(
 	userRepository *repositories.UserRepository,
+	roleRepository *repositories.RoleRepository,
+	personalAccessTokenRepository *repositories.PersonalAccessTokenRepository,
+	auditLogRepository *repositories.AuditLogRepository,
+	storage storage.Storage,
 	db *sql.DB,
 ) *UserService {
 	return &UserService{
 		userRepository: userRepository,
+		roleRepository: roleRepository,
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		auditLogRepository: auditLogRepository,
+		storage: storage,
 		db: db,
 	}
 }
 
+func (service *UserService) GetAll(ctx context.Context) (*[]models.User, error) {
+	return service.userRepository.GetAll(ctx)
+}
+
+func (service *UserService) GetById(ctx context.Context, id int) (*models.User, error) {
+	return service.userRepository.GetById(ctx, id)
+}
+
+// UpdateRole changes user type of the user, user type must be one of the registered roles
+func (service *UserService) UpdateRole(ctx context.Context, data *dto.UpdateUserRoleRequest) (*models.User, error) {
+	if _, err := service.roleRepository.GetByName(ctx, data.UserType); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &exceptions.ValidationError{
+				Message: "Role is not registered",
+				Errors: map[string]string{"user_type": "Role is not registered"},
+			}
+		}
+		return nil, err
+	}
+
+	tx, err := service.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	userRepository := service.userRepository.WithTx(tx)
+	before, err := userRepository.GetById(ctx, data.Id)
+	if err != nil {
+		return nil, err
+	}
+	user, err := userRepository.UpdateUserType(ctx, data.Id, data.UserType)
+	if err != nil {
+		return nil, err
+	}
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionUpdated,
+		models.AuditEntityUser,
+		user.Id,
+		userAuditValues(before),
+		userAuditValues(user),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 func (service *UserService) UpdateAccount(ctx context.Context, data *dto.UpdateAccountRequest) (*models.User, error) {
 	user, err := service.userRepository.GetById(ctx, data.Id)
 	if err != nil {
@@ -45,32 +119,20 @@ func (service *UserService) UpdateAccount(ctx context.Context, data *dto.UpdateA
 	}
 
 	data.Avatar = user.Avatar.String
+	committed := false
 	if data.AvatarFile != nil {
-        src, _ := data.AvatarFile.Open()
-        defer src.Close()
-
-		year := time.Now().Format("2006")
-		month := time.Now().Format("01")
-
-		uploadPath := fmt.Sprintf("avatars/%s/%s/%s", year, month, data.AvatarFile.Filename)
-		uploadDir := filepath.Join("uploads", "avatars", year, month)
-		err := os.MkdirAll(uploadDir, os.ModePerm)
-		if err != nil {
-			return nil, err
-		}
-
-		finalPath := filepath.Join(uploadDir, data.AvatarFile.Filename)
-
-        dst, _ := os.Create(finalPath)
-        defer dst.Close()
-
-        _, err = io.Copy(dst, src)
+		avatar, err := service.storeAvatar(ctx, data)
 		if err != nil {
 			return nil, err
 		}
-
-		data.Avatar = uploadPath
-    }
+		data.Avatar = avatar
+		// The new avatar is not referenced when the account fails to update
+		defer func() {
+			if !committed {
+				service.deleteAvatar(ctx, avatar)
+			}
+		}()
+	}
 
 	hashedPassword := user.Password
 	if data.Password != "" {
@@ -89,9 +151,164 @@ func (service *UserService) UpdateAccount(ctx context.Context, data *dto.UpdateA
 		Password: hashedPassword,
 		Avatar: sql.NullString{String: data.Avatar, Valid: data.Avatar != ""},
     }
-	user, err = service.userRepository.UpdateAccount(ctx, userModel)
+	tx, err := service.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	userRepository := service.userRepository.WithTx(tx)
+	before := user
+	user, err = userRepository.UpdateAccount(ctx, userModel)
+	if err != nil {
+		return nil, err
+	}
+
+	// Changing password ends every other session, the caller reissues the current one,
+	// access tokens are deleted too because they may have been created by whoever knew the old password
+	oldValues := userAuditValues(before)
+	newValues := userAuditValues(user)
+	if data.Password != "" {
+		if err := userRepository.RevokeSessions(ctx, user.Id, time.Now()); err != nil {
+			return nil, err
+		}
+		if err := service.personalAccessTokenRepository.WithTx(tx).DeleteByUserId(ctx, user.Id); err != nil {
+			return nil, err
+		}
+		// Only the fact that the password changed is recorded, never the hash
+		oldValues["password"] = "********"
+		newValues["password"] = "changed"
+	}
+
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionUpdated,
+		models.AuditEntityUser,
+		user.Id,
+		oldValues,
+		newValues,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	committed = true
+
+	// Replaced avatar is deleted only after the new one is saved
+	if before.Avatar.Valid && before.Avatar.String != user.Avatar.String {
+		service.deleteAvatar(ctx, before.Avatar.String)
+	}
+	return user, nil
+}
+
+// storeAvatar re-encodes the uploaded image as square avatar with thumbnails (see AvatarThumbnailSizes),
+// re-encoding drops EXIF/GPS metadata of the photo, the client filename is never used as path
+func (service *UserService) storeAvatar(ctx context.Context, data *dto.UpdateAccountRequest) (string, error) {
+	src, err := data.AvatarFile.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	img, format, content, err := imaging.Read(src, validation.MaxAvatarSize)
+	if err != nil {
+		return "", &exceptions.ValidationError{
+			Message: "Please check the data you provided.",
+			Errors: map[string]string{"avatar": err.Error()},
+		}
+	}
+	avatarImage := imaging.Orient(imaging.SquareThumbnail(img, avatarSize), imaging.Orientation(content))
+
+	avatar, err := storage.RandomName("avatars", "avatar" + imaging.Extension(format))
+	if err != nil {
+		return "", err
+	}
+	if err := service.putImage(ctx, avatar, avatarImage, format); err != nil {
+		return "", err
+	}
+	for _, size := range AvatarThumbnailSizes {
+		err := service.putImage(ctx, imaging.ThumbnailPath(avatar, size), imaging.SquareThumbnail(avatarImage, size), format)
+		if err != nil {
+			service.deleteAvatar(ctx, avatar)
+			return "", err
+		}
+	}
+	return avatar, nil
+}
+
+func (service *UserService) putImage(ctx context.Context, path string, img image.Image, format string) error {
+	var content bytes.Buffer
+	if err := imaging.Encode(&content, img, format); err != nil {
+		return err
+	}
+	return service.storage.Put(ctx, path, &content, "image/" + format)
+}
+
+// deleteAvatar removes the avatar and its thumbnails
+func (service *UserService) deleteAvatar(ctx context.Context, avatar string) {
+	deleteFile(ctx, service.storage, avatar)
+	for _, size := range AvatarThumbnailSizes {
+		deleteFile(ctx, service.storage, imaging.ThumbnailPath(avatar, size))
+	}
+}
+
+// deleteFile removes file that is no longer referenced, failure leaves an orphan file and is only logged
+func deleteFile(ctx context.Context, fileStorage storage.Storage, path string) {
+	if err := fileStorage.Delete(ctx, path); err != nil {
+		slog.Warn("Failed to delete file", slog.String("path", path), slog.Any("error", err))
+	}
+}
+
+// UpdateStatus activates or suspends the user, suspension revokes all sessions and personal access tokens
+func (service *UserService) UpdateStatus(ctx context.Context, data *dto.UpdateUserStatusRequest) (*models.User, error) {
+	if data.Id == data.ActorId {
+		return nil, &exceptions.ValidationError{
+			Message: "You cannot change status of your own account",
+		}
+	}
+
+	tx, err := service.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	userRepository := service.userRepository.WithTx(tx)
+	before, err := userRepository.GetById(ctx, data.Id)
 	if err != nil {
 		return nil, err
 	}
+	user, err := userRepository.UpdateStatus(ctx, data.Id, data.Status)
+	if err != nil {
+		return nil, err
+	}
+	err = recordAuditLog(
+		ctx,
+		service.auditLogRepository.WithTx(tx),
+		models.AuditActionUpdated,
+		models.AuditEntityUser,
+		user.Id,
+		userAuditValues(before),
+		userAuditValues(user),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if data.Status == "SUSPENDED" {
+		if err := userRepository.RevokeSessions(ctx, user.Id, time.Now()); err != nil {
+			return nil, err
+		}
+		if err := service.personalAccessTokenRepository.WithTx(tx).DeleteByUserId(ctx, user.Id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
\ No newline at end of file
@@ -2,7 +2,10 @@ package utilities
 
 import (
 	"database/sql"
+	"strings"
 	"time"
+
+	"github.com/anggadarkprince/crud-employee-go/models"
 )
 
 func StringToDate(value string) (sql.NullTime, error) {
@@ -20,3 +23,21 @@ func StringToDate(value string) (sql.NullTime, error) {
 	}
 	return formatterValue, nil
 }
+
+// FormatMoney formats amount with thousand separator and 2 decimals, e.g. 1500000 -> 1,500,000.00
+func FormatMoney(amount models.Money) string {
+	formatted := amount.String()
+	sign := ""
+	if strings.HasPrefix(formatted, "-") {
+		sign, formatted = "-", formatted[1:]
+	}
+	integer, decimal, _ := strings.Cut(formatted, ".")
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String() + "." + decimal
+}
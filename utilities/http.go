@@ -1,6 +1,9 @@
 package utilities
 
-import "net/http"
+import (
+	"net"
+	"net/http"
+)
 
 func Redirect(w http.ResponseWriter, path string) {
 	http.Redirect(w, &http.Request{}, path, http.StatusFound)
@@ -13,4 +16,13 @@ func QueryParam(r *http.Request, key string) string {
 func FormValue(r *http.Request, key string) string {
 	_ = r.ParseForm()
 	return r.FormValue(key)
-}
\ No newline at end of file
+}
+
+// ClientIP returns the remote address of the request without port
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
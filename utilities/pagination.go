@@ -0,0 +1,120 @@
+package utilities
+
+import (
+	"net/url"
+	"strconv"
+)
+
+type PageLink struct {
+	Page   int
+	Url    string
+	Active bool
+}
+
+// Pagination holds page metadata and builds links that keep the other query params
+type Pagination struct {
+	Total   int
+	Page    int
+	PerPage int
+	Pages   int
+	From    int
+	To      int
+	Sort    string
+	Order   string
+	path    string
+	query   url.Values
+}
+
+func NewPagination(total int, page int, perPage int, path string, query url.Values) *Pagination {
+	pages := (total + perPage - 1) / perPage
+	if pages < 1 {
+		pages = 1
+	}
+	from := 0
+	to := 0
+	if offset := (page - 1) * perPage; offset < total {
+		from = offset + 1
+		to = min(page*perPage, total)
+	}
+	return &Pagination{
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+		Pages:   pages,
+		From:    from,
+		To:      to,
+		Sort:    query.Get("sort"),
+		Order:   query.Get("order"),
+		path:    path,
+		query:   query,
+	}
+}
+
+// Url builds link of the current path with the given params replaced
+func (p *Pagination) Url(params ...string) string {
+	return p.UrlFor(p.path, params...)
+}
+
+// UrlFor builds link of another path that keeps the current query, e.g. export of the filtered list
+func (p *Pagination) UrlFor(path string, params ...string) string {
+	query := url.Values{}
+	for key, values := range p.query {
+		query[key] = append([]string{}, values...)
+	}
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i+1] == "" {
+			query.Del(params[i])
+		} else {
+			query.Set(params[i], params[i+1])
+		}
+	}
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+func (p *Pagination) PageUrl(page int) string {
+	return p.Url("page", strconv.Itoa(page))
+}
+
+// SortUrl toggles the order when sorting by the same column and resets to the first page
+func (p *Pagination) SortUrl(column string) string {
+	order := "asc"
+	if p.Sort == column && p.Order != "desc" {
+		order = "desc"
+	}
+	return p.Url("sort", column, "order", order, "page", "")
+}
+
+func (p *Pagination) HasPrev() bool {
+	return p.Page > 1
+}
+
+func (p *Pagination) HasNext() bool {
+	return p.Page < p.Pages
+}
+
+func (p *Pagination) PrevUrl() string {
+	return p.PageUrl(p.Page - 1)
+}
+
+func (p *Pagination) NextUrl() string {
+	return p.PageUrl(p.Page + 1)
+}
+
+// Links returns a window of page links around the current page
+func (p *Pagination) Links() []PageLink {
+	start := max(1, p.Page-2)
+	end := min(p.Pages, p.Page+2)
+
+	links := []PageLink{}
+	for page := start; page <= end; page++ {
+		links = append(links, PageLink{
+			Page:   page,
+			Url:    p.PageUrl(page),
+			Active: page == p.Page,
+		})
+	}
+	return links
+}
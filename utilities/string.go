@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -32,6 +33,17 @@ func Capitalize(s string) string {
     return strings.ToUpper(s[:1]) + s[1:]
 }
 
+// Replace invalid UTF-8 (e.g. from request headers) and cut to max bytes without splitting a character,
+// so the value fits a utf8mb4 column of that length
+func TruncateString(s string, maxBytes int) string {
+    s = strings.ToValidUTF8(s, "")
+    for len(s) > maxBytes {
+        _, size := utf8.DecodeLastRuneInString(s)
+        s = s[:len(s)-size]
+    }
+    return s
+}
+
 // Generate secure random string
 func RandomString(n int) string {
     b := make([]byte, n)
@@ -1,6 +1,7 @@
 package utilities
 
 import (
+	"bytes"
 	"database/sql"
 	"fmt"
 	"html"
@@ -12,15 +13,21 @@ import (
 	"slices"
 	"strings"
 	"text/template"
+	"time"
 
+	"github.com/anggadarkprince/crud-employee-go/configs"
 	"github.com/anggadarkprince/crud-employee-go/middlewares"
+	"github.com/anggadarkprince/crud-employee-go/models"
+	"github.com/anggadarkprince/crud-employee-go/pkg/imaging"
 	"github.com/anggadarkprince/crud-employee-go/pkg/session"
+	"github.com/anggadarkprince/crud-employee-go/pkg/storage"
 )
 
 var Template *template.Template
 
 var TemplateFuncs = template.FuncMap{
     "add": func(a, b int) int { return a + b },
+    "diff": func(a, b models.Money) models.Money { return a - b },
     "toUpper": strings.ToUpper,
     "hasPrefix": strings.HasPrefix,
     "contains": func(arr any, value string) bool {
@@ -28,6 +35,11 @@ var TemplateFuncs = template.FuncMap{
             return false
         }
         
+        // Single value of multi value input, e.g. old input with one checked checkbox
+        if str, ok := arr.(string); ok {
+            return str == value
+        }
+        
         // Handle []string
         if strArr, ok := arr.([]string); ok {
             return slices.Contains(strArr, value)
@@ -180,6 +192,30 @@ var TemplateFuncs = template.FuncMap{
     "emptySlice": func() []string {
         return []string{}
     },
+    "list": func(values ...any) []any {
+        return values
+    },
+    // escape must be used for untrusted input, templates are not auto escaped
+    "escape": func(value any) string {
+        return EscapeHTML(fmt.Sprint(value))
+    },
+    // Placeholders, replaced by request scoped funcs in Render
+    "can": func(permission string) bool {
+        return false
+    },
+    "csrfToken": func() string {
+        return ""
+    },
+    "csrfField": func() string {
+        return ""
+    },
+    "formatMoney": FormatMoney,
+    // fileUrl returns signed url of the stored file (e.g. avatar) that can be embedded in pages
+    "fileUrl": func(path string) string {
+        return storage.SignedUrl(path, time.Duration(configs.Get().Storage.SignedUrlTtl) * time.Second)
+    },
+    // thumbnail returns path of the image thumbnail size e.g. fileUrl (thumbnail .user.Avatar.String 128)
+    "thumbnail": imaging.ThumbnailPath,
     "formatDate": func(v any, layout, fallback string) string {
         if t, ok := v.(sql.NullTime); ok && t.Valid {
             return t.Time.Format(layout)
@@ -189,7 +225,7 @@ var TemplateFuncs = template.FuncMap{
 }
 
 func LoadTemplates() *template.Template {
-    root := template.New("").Option("missingkey=error").Funcs(TemplateFuncs)
+    root := template.New("").Option("missingkey=default").Funcs(TemplateFuncs)
 
     filepath.Walk("views", func(path string, info os.FileInfo, err error) error {
         if err != nil || info.IsDir() {
@@ -221,6 +257,11 @@ func InitTemplates() {
 }
 
 func Render(w http.ResponseWriter, r *http.Request, name string, data map[string]any) error {
+    return RenderStatus(w, r, http.StatusOK, name, data)
+}
+
+// RenderStatus renders the template with a non 200 status code (e.g. error pages)
+func RenderStatus(w http.ResponseWriter, r *http.Request, status int, name string, data map[string]any) error {
     funcs := template.FuncMap{
         "query": func(key string) string {
             return r.URL.Query().Get(key)
@@ -231,6 +272,15 @@ func Render(w http.ResponseWriter, r *http.Request, name string, data map[string
 		"currentPath": func() string {
 			return r.URL.Path
 		},
+        "can": func(permission string) bool {
+            return middlewares.Can(r, permission)
+        },
+        "csrfToken": func() string {
+            return middlewares.GetCSRFToken(r)
+        },
+        "csrfField": func() string {
+            return fmt.Sprintf(`<input type="hidden" name="%s" value="%s">`, middlewares.CSRFFieldName, middlewares.GetCSRFToken(r))
+        },
     }
 
     // Clone template so funcs are local to this request
@@ -288,9 +338,22 @@ func Render(w http.ResponseWriter, r *http.Request, name string, data map[string
     }
     maps.Copy(payload, data)
 
+    if status != http.StatusOK {
+        w.WriteHeader(status)
+    }
+
 	return tmpl.ExecuteTemplate(w, name, payload)
 }
 
+// RenderMail renders mail body from views/mails, request scoped funcs are not available
+func RenderMail(name string, data map[string]any) (string, error) {
+    var body bytes.Buffer
+    if err := Template.ExecuteTemplate(&body, name, data); err != nil {
+        return "", err
+    }
+    return body.String(), nil
+}
+
 func EscapeHTML(s string) string {
     return html.EscapeString(s)
 }